@@ -8,20 +8,29 @@ import (
 	"os"
 	"os/signal"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/spf13/pflag"
 	"golang.org/x/term"
 
 	"sonos-playlist/internal/ai"
 	"sonos-playlist/internal/config"
+	"sonos-playlist/internal/daemon"
+	"sonos-playlist/internal/native/appconfig"
 	nativecli "sonos-playlist/internal/native/cli"
+	nativesonos "sonos-playlist/internal/native/sonos"
 	"sonos-playlist/internal/output"
 	"sonos-playlist/internal/playlist"
+	"sonos-playlist/internal/resolve"
 	"sonos-playlist/internal/setup"
 	"sonos-playlist/internal/sonos"
+	"sonos-playlist/internal/storage"
+	"sonos-playlist/internal/taste"
 )
 
 const (
@@ -37,9 +46,21 @@ type cliOptions struct {
 	ProviderSpecified bool
 	Room              string
 	Count             int
+	ExcludeRecent     int
 	SonosAPI          string
+	Backend           config.Backend
+	BackendSpecified  bool
+	Services          []string
 	DryRun            bool
+	SeedNow           bool
+	Append            bool
+	PlayNext          bool
+	ExplainMatch      bool
+	MatchAllow        []string
+	MatchExclude      []string
+	MatchAdjust       []string
 	Monitor           bool
+	Refine            bool
 	ListRooms         bool
 	Setup             bool
 	Prompt            string
@@ -93,6 +114,9 @@ func main() {
 
 func run(ctx context.Context) error {
 	cfg := config.Load()
+	storage.ConfigureSearchCache(cfg.SearchCacheTTL, cfg.SearchCacheMaxEntries)
+	defer func() { _ = storage.FlushSearchCache() }()
+	storage.ConfigureBlocklist(cfg.BlocklistTTL)
 	opts, err := parseArgs(cfg)
 	if err != nil {
 		return err
@@ -113,6 +137,7 @@ func run(ctx context.Context) error {
 		Verbose: opts.Verbose,
 		NoColor: opts.NoColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb",
 	})
+	configureMaxVolume(out)
 
 	if opts.Setup {
 		if err := setup.Run(out); err != nil {
@@ -125,8 +150,15 @@ func run(ctx context.Context) error {
 	}
 
 	client := sonos.NewClient(opts.SonosAPI)
+	connected := false
+	apiReachable := func() bool {
+		if !connected {
+			connected = client.CheckConnection(ctx)
+		}
+		return connected
+	}
 	ensureConnected := func() error {
-		if !client.CheckConnection(ctx) {
+		if !apiReachable() {
 			setup.PrintInstructions(out)
 			return fmt.Errorf("sonos api not reachable")
 		}
@@ -155,6 +187,12 @@ func run(ctx context.Context) error {
 	if prompt == "" && !opts.NoInput && !term.IsTerminal(int(os.Stdin.Fd())) {
 		prompt = readPromptFromStdin()
 	}
+	if prompt == "" && opts.Refine {
+		return usageError{msg: "Usage: sonos refine \"<instruction>\""}
+	}
+	if prompt == "" && opts.SeedNow {
+		prompt = defaultSeedPrompt
+	}
 	if prompt == "" {
 		return usageError{msg: strings.Join([]string{
 			"Missing prompt or control command.",
@@ -166,11 +204,25 @@ func run(ctx context.Context) error {
 		}, "\n")}
 	}
 
+	// Without an explicit choice, playlists go straight to the speakers when
+	// node-sonos-http-api isn't running.
+	if !opts.BackendSpecified && opts.Backend == config.BackendHTTP && (!opts.DryRun || opts.SeedNow) && !apiReachable() {
+		out.Info(out.Gray("Sonos HTTP API not reachable at " + opts.SonosAPI + "; using the native backend"))
+		opts.Backend = config.BackendNative
+	}
+	native := opts.Backend == config.BackendNative
 	room := opts.Room
 	if room == "" {
-		if opts.DryRun {
+		switch {
+		case opts.DryRun && !opts.SeedNow:
 			room = "Living Room"
-		} else {
+		case native:
+			defaultRoom, err := nativeDefaultRoom(ctx)
+			if err != nil {
+				return err
+			}
+			room = defaultRoom
+		default:
 			if err := ensureConnected(); err != nil {
 				return err
 			}
@@ -183,7 +235,7 @@ func run(ctx context.Context) error {
 	}
 
 	promptLower := strings.ToLower(strings.TrimSpace(prompt))
-	if v, ok := parseVolumeCommand(promptLower); ok {
+	if v, ok := parseVolumeCommand(promptLower); ok && !opts.Refine {
 		if err := ensureConnected(); err != nil {
 			return err
 		}
@@ -198,7 +250,7 @@ func run(ctx context.Context) error {
 		return nil
 	}
 
-	if handler, ok := controlCommands[promptLower]; ok {
+	if handler, ok := controlCommands[promptLower]; ok && !opts.Refine {
 		if err := ensureConnected(); err != nil {
 			return err
 		}
@@ -217,61 +269,149 @@ func run(ctx context.Context) error {
 		return nil
 	}
 
-	keys := ai.APIKeys{
-		Anthropic: cfg.AnthropicAPIKey,
-		OpenAI:    cfg.OpenAIAPIKey,
-		Google:    cfg.GoogleAPIKey,
-		XAI:       cfg.XAIAPIKey,
-	}
+	keys := cfg.APIKeys
 	if opts.ProviderSpecified {
-		switch opts.Provider {
-		case "claude":
-			keys = ai.APIKeys{Anthropic: cfg.AnthropicAPIKey}
-		case "openai":
-			keys = ai.APIKeys{OpenAI: cfg.OpenAIAPIKey}
-		case "gemini":
-			keys = ai.APIKeys{Google: cfg.GoogleAPIKey}
-		case "grok", "xai":
-			keys = ai.APIKeys{XAI: cfg.XAIAPIKey}
-		}
+		keys = keys.Only(opts.Provider)
 	}
 
-	providers := selectedProviders(keys)
+	providers := ai.ConfiguredDisplayNames(keys)
 	if len(providers) == 0 {
 		if opts.ProviderSpecified {
 			out.Error("No API key configured for provider: " + opts.Provider)
 		} else {
 			out.Error("No API keys configured.")
 		}
-		out.Error("Set at least one of: ANTHROPIC_API_KEY, OPENAI_API_KEY, GOOGLE_API_KEY, XAI_API_KEY")
+		out.Error("Set at least one of: " + strings.Join(ai.ProviderEnvKeys(), ", "))
 		return fmt.Errorf("missing api keys")
 	}
 
 	out.Info(out.Gray("Using providers: " + strings.Join(providers, ", ")))
-	if !opts.DryRun {
+	matches, matchRules, err := configureMatching(cfg, opts, prompt, out)
+	if err != nil {
+		return err
+	}
+	needSpeaker := !opts.DryRun || opts.SeedNow
+	if needSpeaker && !native {
 		if err := ensureConnected(); err != nil {
 			return err
 		}
+	}
+
+	// The native backend requires a direct speaker client for everything;
+	// with the HTTP backend it is optional, used when reachable to read the
+	// current track, edit the queue, and receive playback events.
+	var speaker *nativesonos.Client
+	if (native && needSpeaker) || opts.SeedNow || opts.Refine || (!opts.DryRun && (opts.PlayNext || opts.Monitor)) {
+		c, err := nativesonos.CoordinatorClientForName(ctx, room, 5*time.Second)
+		switch {
+		case err == nil:
+			speaker = c
+		case native && needSpeaker:
+			return fmt.Errorf("could not reach %s: %w", room, err)
+		default:
+			out.Debug("Could not reach " + room + " directly: " + err.Error())
+		}
+	}
+	if needSpeaker {
 		out.Info(out.Gray("Using speaker: " + room))
 	}
+	var backend playlist.Backend = playlist.HTTPBackend{Client: client, Room: room, Speaker: speaker}
+	if native && speaker != nil {
+		backend = playlist.NewNativeBackend(speaker, playlist.NativeResolver(ctx, speaker, opts.Services, out))
+	} else if !native && !sameServices(opts.Services, resolve.DefaultServices) {
+		out.Warn("--services needs the native backend; matching songs on Apple Music")
+	}
+
+	var seed *ai.Song
+	if opts.SeedNow {
+		song, err := nowPlayingSeed(ctx, speaker, client, room)
+		if err != nil {
+			return err
+		}
+		seed = &song
+	}
+
+	mode := playlist.QueueReplace
+	switch {
+	case opts.PlayNext:
+		mode = playlist.QueuePlayNext
+	case opts.Append:
+		mode = playlist.QueueAppend
+	}
+	var queue playlist.QueueEditor
+	if speaker != nil {
+		queue = speaker
+	}
+
+	templates := ai.DefaultPromptTemplates()
+	if cfg.PromptDir != "" {
+		if templates, err = ai.LoadPromptTemplates(cfg.PromptDir); err != nil {
+			return err
+		}
+	}
+	profile, err := loadTasteProfile()
+	if err != nil {
+		return err
+	}
+
+	if opts.Refine {
+		refine := playlist.RefineOptions{
+			Keys:        keys,
+			Models:      ai.Models{},
+			Weights:     cfg.ProviderWeights,
+			Templates:   templates,
+			Taste:       profile,
+			Room:        room,
+			Instruction: prompt,
+			Count:       opts.Count,
+			DryRun:      opts.DryRun,
+			Backend:     backend,
+			Queue:       queue,
+			Output:      out,
+		}
+		return runRefine(ctx, opts, out, refine)
+	}
+
+	// With --monitor, a running daemon takes over monitoring, so this run
+	// exits as soon as the songs are queued.
+	var handoff func(context.Context, []playlist.WatchedSong) error
+	if opts.Monitor && !opts.DryRun {
+		dc := daemon.NewClient(daemon.DefaultPaths())
+		if _, err := dc.Status(ctx); err == nil {
+			handoff = func(ctx context.Context, songs []playlist.WatchedSong) error {
+				return dc.Watch(ctx, daemon.WatchRequest{
+					Room: room, Backend: opts.Backend, SonosAPI: opts.SonosAPI, Services: opts.Services,
+					MatchRules: &matchRules, Songs: songs,
+				})
+			}
+		}
+	}
 
 	result, err := playlist.GenerateAndPlay(ctx, playlist.GeneratorOptions{
-		Keys:             keys,
-		Models:           ai.Models{},
-		Prompt:           prompt,
-		Room:             room,
-		DryRun:           opts.DryRun,
-		Client:           client,
-		Monitor:          opts.Monitor,
-		CountPerProvider: opts.Count,
-		Output:           out,
+		Keys:              keys,
+		Models:            ai.Models{},
+		Weights:           cfg.ProviderWeights,
+		Templates:         templates,
+		Taste:             profile,
+		Prompt:            prompt,
+		Room:              room,
+		DryRun:            opts.DryRun,
+		Backend:           backend,
+		Monitor:           opts.Monitor,
+		Handoff:           handoff,
+		CountPerProvider:  opts.Count,
+		ExcludeRecentDays: opts.ExcludeRecent,
+		Seed:              seed,
+		Mode:              mode,
+		Queue:             queue,
+		Output:            out,
 	})
 	if err != nil {
 		return err
 	}
 
 	if opts.JSON {
-		return out.EmitJSON(map[string]any{
+		payload := map[string]any{
 			"providers":        providers,
 			"countPerProvider": opts.Count,
 			"room":             result.Room,
@@ -280,18 +420,213 @@ func run(ctx context.Context) error {
 			"failedSongs":      result.FailedSongs,
 			"playbackStarted":  result.PlaybackStarted,
 			"monitored":        result.Monitored,
-		})
+			"handedOff":        result.HandedOff,
+			"mode":             result.Mode,
+			"providerResults":  result.Providers,
+		}
+		if result.DryRun {
+			payload["songs"] = result.Songs
+		}
+		if result.Seed != nil {
+			payload["seed"] = result.Seed
+		}
+		if opts.ExplainMatch {
+			payload["matches"] = matches.all()
+		}
+		return out.EmitJSON(payload)
 	}
 	return nil
 }
 
+// configureMatching sets the search match rules: the rules file, then flag
+// overrides, relaxed for versions the prompt asks for. It returns the
+// effective rules, to hand to the daemon with the queued songs. With
+// --explain-match the returned log collects a report per searched song.
+func configureMatching(cfg config.Config, opts cliOptions, prompt string, out *output.Output) (*matchLog, sonos.MatchRules, error) {
+	rules, err := sonos.LoadMatchRules(cfg.MatchRulesFile)
+	if err != nil {
+		return nil, sonos.MatchRules{}, err
+	}
+	overrides := sonos.MatchRules{Adjust: map[string]int{}, Allow: opts.MatchAllow, Exclude: opts.MatchExclude}
+	for _, a := range opts.MatchAdjust {
+		kw, n, err := sonos.ParseMatchAdjust(a)
+		if err != nil {
+			return nil, sonos.MatchRules{}, usageError{msg: err.Error()}
+		}
+		overrides.Adjust[kw] = n
+	}
+	rules = rules.Merge(overrides).ForPrompt(prompt)
+	sonos.SetMatchRules(rules)
+
+	log := &matchLog{out: out, quiet: opts.JSON}
+	if opts.ExplainMatch {
+		sonos.SetMatchExplainer(log.record)
+	}
+	return log, rules, nil
+}
+
+// matchLog prints match reports as songs are searched and keeps them for
+// JSON output. Searches may run concurrently.
+type matchLog struct {
+	mu      sync.Mutex
+	out     *output.Output
+	quiet   bool
+	reports []sonos.MatchReport
+}
+
+func (l *matchLog) record(r sonos.MatchReport) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reports = append(l.reports, r)
+	if l.quiet {
+		return
+	}
+	l.out.Print(l.out.Bold(fmt.Sprintf("Match: %s - %s", r.Song.Artist, r.Song.Title)))
+	if len(r.Candidates) == 0 {
+		l.out.Print(l.out.Gray("  (no results)"))
+	}
+	candidates := append([]sonos.CandidateReport(nil), r.Candidates...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Chosen != b.Chosen {
+			return a.Chosen
+		}
+		if (a.Rejected == "") != (b.Rejected == "") {
+			return a.Rejected == ""
+		}
+		return a.Score > b.Score
+	})
+	for _, c := range candidates {
+		track := fmt.Sprintf("%s - %s (%s) [%d]", c.Artist, c.Title, c.Album, c.TrackID)
+		switch {
+		case c.Rejected != "":
+			l.out.Print(l.out.Gray(fmt.Sprintf("  x %s: %s", track, c.Rejected)))
+		case c.Chosen:
+			l.out.Print(l.out.Green(fmt.Sprintf("  * %4d %s: %s", c.Score, track, strings.Join(c.Reasons, ", "))))
+		default:
+			l.out.Print(fmt.Sprintf("    %4d %s: %s", c.Score, track, strings.Join(c.Reasons, ", ")))
+		}
+	}
+}
+
+func (l *matchLog) all() []sonos.MatchReport {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]sonos.MatchReport{}, l.reports...)
+}
+
+// defaultSeedPrompt is used when --seed-now is given without a prompt.
+const defaultSeedPrompt = "more songs in the style of what is playing now"
+
+// nowPlayingSeed reads the current track, asking the speaker directly first
+// and falling back to the HTTP API's room state.
+func nowPlayingSeed(ctx context.Context, speaker *nativesonos.Client, client *sonos.Client, room string) (ai.Song, error) {
+	if speaker != nil {
+		if pos, err := speaker.GetPositionInfo(ctx); err == nil {
+			if item, ok := nativesonos.ParseNowPlaying(pos.TrackMeta); ok && item.Title != "" && item.Artist != "" {
+				return ai.Song{Artist: item.Artist, Title: item.Title, Album: item.Album}, nil
+			}
+		}
+	}
+	song, ok, err := sonos.NowPlaying(ctx, client, room)
+	if err != nil {
+		return ai.Song{}, err
+	}
+	if !ok {
+		return ai.Song{}, fmt.Errorf("nothing is playing on %s to seed from", room)
+	}
+	return song, nil
+}
+
+func runRefine(ctx context.Context, opts cliOptions, out *output.Output, refine playlist.RefineOptions) error {
+	if refine.Queue == nil {
+		out.Warn("Could not reach " + refine.Room + " directly; refining the last generated playlist")
+	}
+	result, err := playlist.Refine(ctx, refine)
+	if err != nil {
+		return err
+	}
+	if opts.JSON {
+		payload := map[string]any{
+			"action":          "refine",
+			"room":            result.Room,
+			"instruction":     result.Instruction,
+			"dryRun":          result.DryRun,
+			"kept":            result.Kept,
+			"removed":         result.Removed,
+			"added":           result.Added,
+			"failed":          result.Failed,
+			"providerResults": result.Providers,
+		}
+		if result.DryRun {
+			payload["songs"] = result.Songs
+		}
+		return out.EmitJSON(payload)
+	}
+	return nil
+}
+
+func sameServices(a, b []string) bool {
+	return strings.Join(a, ",") == strings.Join(b, ",")
+}
+
+// configureMaxVolume applies the per-room volume caps from the native config
+// (`sonos config set maxVolume.<room> N`) to the legacy volume commands. An
+// unreadable config only costs the caps, so it is reported and skipped.
+func configureMaxVolume(out *output.Output) {
+	store, err := appconfig.NewDefaultStore()
+	if err != nil {
+		// No user config directory means no caps can have been configured.
+		return
+	}
+	appCfg, err := store.Load()
+	if err != nil {
+		out.Warn(fmt.Sprintf("Ignoring volume limits: load config: %v", err))
+		return
+	}
+	sonos.ConfigureMaxVolume(appCfg.MaxVolume.For)
+}
+
+// nativeDefaultRoom picks the coordinator of the first group found on the
+// network, mirroring what the HTTP API reports first.
+func nativeDefaultRoom(ctx context.Context) (string, error) {
+	devs, err := nativesonos.Discover(ctx, nativesonos.DiscoverOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		return "", err
+	}
+	if len(devs) == 0 {
+		return "", fmt.Errorf("no sonos speakers found")
+	}
+	top, err := nativesonos.NewClient(devs[0].IP, 5*time.Second).GetTopology(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, g := range top.Groups {
+		if g.Coordinator.Name != "" {
+			return g.Coordinator.Name, nil
+		}
+	}
+	return "", fmt.Errorf("no sonos speakers found")
+}
+
+func loadTasteProfile() (taste.Profile, error) {
+	store, err := taste.NewDefaultStore()
+	if err != nil {
+		return taste.Profile{}, nil
+	}
+	return store.Load()
+}
+
 func parseArgs(cfg config.Config) (cliOptions, error) {
 	opts := cliOptions{
-		Provider: string(cfg.DefaultProvider),
-		Room:     cfg.DefaultRoom,
-		Count:    cfg.DefaultCount,
-		SonosAPI: cfg.SonosAPIURL,
+		Provider:      string(cfg.DefaultProvider),
+		Room:          cfg.DefaultRoom,
+		Count:         cfg.DefaultCount,
+		ExcludeRecent: cfg.ExcludeRecentDays,
+		SonosAPI:      cfg.SonosAPIURL,
 	}
+	backend := string(cfg.Backend)
+	services := strings.Join(cfg.Services, ",")
 
 	fs := pflag.NewFlagSet("sonos", pflag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
@@ -299,11 +634,21 @@ func parseArgs(cfg config.Config) (cliOptions, error) {
 
 	fs.BoolVarP(&opts.Help, "help", "h", false, "display help")
 	fs.BoolVar(&opts.Version, "version", false, "output the version number")
-	fs.StringVarP(&opts.Provider, "provider", "p", opts.Provider, "AI provider: claude, openai, gemini, grok (or xai)")
+	fs.StringVarP(&opts.Provider, "provider", "p", opts.Provider, "AI provider: "+strings.Join(ai.ProviderNames(), ", "))
 	fs.StringVarP(&opts.Room, "room", "r", opts.Room, "Sonos speaker name")
 	fs.IntVarP(&opts.Count, "count", "c", opts.Count, "Number of songs generated per provider (1-50)")
+	fs.IntVar(&opts.ExcludeRecent, "exclude-recent", opts.ExcludeRecent, "Skip songs queued in the last N days (0 = off)")
 	fs.StringVarP(&opts.SonosAPI, "sonos-api", "s", opts.SonosAPI, "Sonos HTTP API URL")
+	fs.StringVar(&backend, "backend", backend, "Speaker backend for AI playlists: http or native")
+	fs.StringVar(&services, "services", services, "Music services to match songs on, in preferred order (native backend)")
 	fs.BoolVarP(&opts.DryRun, "dry-run", "d", false, "Preview playlist without playing")
+	fs.BoolVar(&opts.SeedNow, "seed-now", false, "Build on the current track and add songs after it without stopping playback")
+	fs.BoolVar(&opts.Append, "append", false, "Add songs after the existing queue without stopping playback")
+	fs.BoolVar(&opts.PlayNext, "play-next", false, "Insert songs right after the current track without stopping playback")
+	fs.BoolVar(&opts.ExplainMatch, "explain-match", false, "Show why each search result was chosen or rejected")
+	fs.StringSliceVar(&opts.MatchAllow, "match-allow", nil, "Keep versions with these keywords (e.g. instrumental,workout)")
+	fs.StringSliceVar(&opts.MatchExclude, "match-exclude", nil, "Reject versions with these keywords")
+	fs.StringArrayVar(&opts.MatchAdjust, "match-adjust", nil, "Score adjustment for a title keyword, e.g. remix=0 (repeatable)")
 	fs.BoolVar(&opts.JSON, "json", false, "Output machine-readable JSON for supported commands")
 	fs.BoolVar(&opts.Plain, "plain", false, "Disable decorative formatting")
 	fs.BoolVarP(&opts.Quiet, "quiet", "q", false, "Suppress non-essential output")
@@ -320,18 +665,39 @@ func parseArgs(cfg config.Config) (cliOptions, error) {
 	if fs.Lookup("provider") != nil {
 		opts.ProviderSpecified = fs.Lookup("provider").Changed
 	}
+	opts.BackendSpecified = cfg.BackendSet || fs.Changed("backend")
 
-	switch strings.ToLower(opts.Provider) {
-	case "claude", "openai", "gemini", "grok", "xai":
-		opts.Provider = strings.ToLower(opts.Provider)
-	default:
-		return cliOptions{}, usageError{msg: "provider must be one of: claude, openai, gemini, grok, xai"}
+	provider, ok := ai.Lookup(opts.Provider)
+	if !ok {
+		return cliOptions{}, usageError{msg: "provider must be one of: " + strings.Join(ai.ProviderNames(), ", ")}
 	}
+	opts.Provider = provider.Info().Name
 	if opts.Count < 1 || opts.Count > 50 {
 		return cliOptions{}, usageError{msg: "count must be between 1 and 50"}
 	}
+	b, ok := config.ParseBackend(backend)
+	if !ok {
+		return cliOptions{}, usageError{msg: "backend must be one of: http, native"}
+	}
+	opts.Backend = b
+	opts.Services = resolve.ParseServices(services)
+	if len(opts.Services) == 0 {
+		return cliOptions{}, usageError{msg: "services must name at least one music service"}
+	}
+	if opts.Append && opts.PlayNext {
+		return cliOptions{}, usageError{msg: "--append and --play-next cannot be used together"}
+	}
+	if opts.ExcludeRecent < 0 {
+		return cliOptions{}, usageError{msg: "exclude-recent must be 0 or more days"}
+	}
 
 	args := fs.Args()
+	// Only a separate `refine` argument selects refine; a prompt that merely
+	// starts with the word is still a playlist prompt.
+	if len(args) > 0 && strings.EqualFold(args[0], "refine") {
+		opts.Refine = true
+		args = args[1:]
+	}
 	opts.Prompt = strings.TrimSpace(strings.Join(args, " "))
 	return opts, nil
 }
@@ -343,27 +709,39 @@ func printUsage(cfg config.Config) {
 	fmt.Fprintln(os.Stdout, "")
 	fmt.Fprintln(os.Stdout, "Arguments:")
 	fmt.Fprintln(os.Stdout, "  prompt                     Natural language playlist description")
+	fmt.Fprintln(os.Stdout, "  refine <instruction>       Revise the upcoming songs in place, e.g. sonos refine \"less vocals\"")
 	fmt.Fprintln(os.Stdout, "")
 	fmt.Fprintln(os.Stdout, "Options:")
 	fmt.Fprintln(os.Stdout, "  -h, --help                 display help")
 	fmt.Fprintln(os.Stdout, "      --version              output the version number")
-	fmt.Fprintf(os.Stdout, "  -p, --provider <provider>  AI provider: claude, openai, gemini, grok, xai (default: %q)\n", cfg.DefaultProvider)
+	fmt.Fprintf(os.Stdout, "  -p, --provider <provider>  AI provider: %s (default: %q)\n", strings.Join(ai.ProviderNames(), ", "), cfg.DefaultProvider)
 	fmt.Fprintln(os.Stdout, "  -r, --room <room>          Sonos speaker name")
 	fmt.Fprintf(os.Stdout, "  -c, --count <number>       Number of songs generated per provider (1-50) (default: %d)\n", cfg.DefaultCount)
+	fmt.Fprintf(os.Stdout, "      --exclude-recent <n>   Skip songs queued in the last N days (0 = off) (default: %d)\n", cfg.ExcludeRecentDays)
 	fmt.Fprintf(os.Stdout, "  -s, --sonos-api <url>      Sonos HTTP API URL (default: %q)\n", cfg.SonosAPIURL)
+	fmt.Fprintf(os.Stdout, "      --backend <backend>    Speaker backend for AI playlists: http or native (default: %q, native when the HTTP API is unreachable)\n", cfg.Backend)
+	fmt.Fprintf(os.Stdout, "      --services <list>      Music services to match songs on, in preferred order (native backend) (default: %q)\n", strings.Join(cfg.Services, ","))
 	fmt.Fprintln(os.Stdout, "  -d, --dry-run              Preview playlist without playing")
+	fmt.Fprintln(os.Stdout, "      --seed-now             Build on the current track and add songs after it without stopping playback")
+	fmt.Fprintln(os.Stdout, "      --append               Add songs after the existing queue without stopping playback")
+	fmt.Fprintln(os.Stdout, "      --play-next            Insert songs right after the current track without stopping playback")
+	fmt.Fprintln(os.Stdout, "      --explain-match        Show why each search result was chosen or rejected")
+	fmt.Fprintln(os.Stdout, "      --match-allow <list>   Keep versions with these keywords (e.g. instrumental,workout)")
+	fmt.Fprintln(os.Stdout, "      --match-exclude <list> Reject versions with these keywords")
+	fmt.Fprintln(os.Stdout, "      --match-adjust <k=n>   Score adjustment for a title keyword, e.g. remix=0 (repeatable)")
 	fmt.Fprintln(os.Stdout, "      --json                 Output machine-readable JSON for supported commands")
 	fmt.Fprintln(os.Stdout, "      --plain                Disable decorative formatting")
 	fmt.Fprintln(os.Stdout, "  -q, --quiet                Suppress non-essential output")
 	fmt.Fprintln(os.Stdout, "  -v, --verbose              Enable verbose diagnostics")
 	fmt.Fprintln(os.Stdout, "      --no-color             Disable colored output")
 	fmt.Fprintln(os.Stdout, "      --no-input             Disable stdin reads/prompts")
-	fmt.Fprintln(os.Stdout, "      --monitor              Keep running after queueing and auto-skip unavailable tracks (opt-in)")
+	fmt.Fprintln(os.Stdout, "      --monitor              Keep running after queueing and auto-skip unavailable tracks (opt-in;")
+	fmt.Fprintln(os.Stdout, "                             handed to `sonos daemon` instead when it is running)")
 	fmt.Fprintln(os.Stdout, "  -l, --list-rooms           Show available Sonos speakers")
 	fmt.Fprintln(os.Stdout, "      --setup                Install and start node-sonos-http-api")
 	fmt.Fprintln(os.Stdout, "")
 	fmt.Fprintln(os.Stdout, "Native Sonos Commands:")
-	fmt.Fprintln(os.Stdout, "  discover, status (now), queue, favorites, group, config, volume, mute, watch, scene, play, pause, stop, next, prev")
+	fmt.Fprintln(os.Stdout, "  discover, status (now), queue, favorites, group, config, volume, mute, eq, mode, sleep, alarm, watch, scene, taste, history, cache, blocklist, daemon, play, pause, stop, next, prev")
 	fmt.Fprintln(os.Stdout, "  Run `sonos <command> --help` for command-specific usage.")
 	fmt.Fprintln(os.Stdout, "  Run `sonos help` for the native command tree.")
 }
@@ -523,20 +901,3 @@ func renderControlText(result controlResult) string {
 		return "Done"
 	}
 }
-
-func selectedProviders(keys ai.APIKeys) []string {
-	providers := []string{}
-	if keys.Anthropic != "" {
-		providers = append(providers, "Claude")
-	}
-	if keys.OpenAI != "" {
-		providers = append(providers, "OpenAI")
-	}
-	if keys.Google != "" {
-		providers = append(providers, "Gemini")
-	}
-	if keys.XAI != "" {
-		providers = append(providers, "Grok")
-	}
-	return providers
-}
@@ -1,60 +1,119 @@
 package ai
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
-	"net/http"
 )
 
-func GeneratePlaylistClaude(ctx context.Context, apiKey, model, prompt string, count int) ([]Song, error) {
-	systemPrompt := `You are a music expert. Generate playlists based on user requests.
-Return ONLY a JSON array of songs, no other text. Each song should have "title" and "artist" fields.
-Example: [{"title": "Blue in Green", "artist": "Miles Davis"}, {"title": "Take Five", "artist": "Dave Brubeck"}]`
+const DefaultClaudeModel = "claude-sonnet-4-6"
 
-	userPrompt := fmt.Sprintf("Generate a playlist of exactly %d songs for: \"%s\"\nReturn only the JSON array, no explanation.", count, prompt)
+// claudePlaylistTool is the forced tool call Claude uses to return structured
+// playlist output.
+const claudePlaylistTool = "submit_playlist"
 
+type claudeProvider struct{}
+
+func (claudeProvider) Info() ProviderInfo {
+	return ProviderInfo{
+		Name:         "claude",
+		DisplayName:  "Claude",
+		EnvKeys:      []string{"ANTHROPIC_API_KEY"},
+		DefaultModel: DefaultClaudeModel,
+	}
+}
+
+func (claudeProvider) request(apiKey, model string, req Request, stream bool) apiRequest {
 	payload := map[string]any{
 		"model":      model,
-		"max_tokens": 2048,
-		"system":     systemPrompt,
+		"max_tokens": outputTokenLimit(req.Count),
+		"system":     req.System,
 		"messages": []map[string]any{
-			{"role": "user", "content": userPrompt},
+			{"role": "user", "content": req.User},
 		},
+		"tools": []map[string]any{{
+			"name":         claudePlaylistTool,
+			"description":  "Submit the generated playlist.",
+			"input_schema": playlistSchema(false),
+		}},
+		"tool_choice": map[string]any{"type": "tool", "name": claudePlaylistTool},
 	}
-	buf, _ := json.Marshal(payload)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.anthropic.com/v1/messages", bytes.NewReader(buf))
-	if err != nil {
-		return nil, err
+	if stream {
+		payload["stream"] = true
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", apiKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
+	return apiRequest{
+		Provider: "claude",
+		Label:    "claude",
+		URL:      "https://api.anthropic.com/v1/messages",
+		Header: map[string]string{
+			"x-api-key":         apiKey,
+			"anthropic-version": "2023-06-01",
+		},
+		Payload: payload,
+	}
+}
 
-	resp, err := apiHTTPClient.Do(req)
+func (p claudeProvider) Generate(ctx context.Context, apiKey, model string, req Request) ([]Song, error) {
+	body, err := p.request(apiKey, model, req, false).do(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	body, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("claude api error: %d - %s", resp.StatusCode, string(body))
-	}
 
 	var data struct {
 		Content []struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
+			Type  string          `json:"type"`
+			Text  string          `json:"text"`
+			Name  string          `json:"name"`
+			Input json.RawMessage `json:"input"`
 		} `json:"content"`
 	}
 	if err := json.Unmarshal(body, &data); err != nil {
 		return nil, err
 	}
 	text := ""
-	if len(data.Content) > 0 {
-		text = data.Content[0].Text
+	for _, block := range data.Content {
+		if block.Type == "tool_use" && block.Name == claudePlaylistTool && len(block.Input) > 0 {
+			return parsePlaylistResponse(string(block.Input))
+		}
+		if text == "" && block.Type == "text" {
+			text = block.Text
+		}
 	}
 	return parsePlaylistResponse(text)
 }
+
+func (p claudeProvider) GenerateStream(ctx context.Context, apiKey, model string, req Request, emit func(Song)) ([]Song, error) {
+	parser := newSongStreamParser(emit)
+	err := p.request(apiKey, model, req, true).stream(ctx, func(r io.Reader) error {
+		return readSSE(r, func(data []byte) error {
+			var ev struct {
+				Type  string `json:"type"`
+				Delta struct {
+					Type        string `json:"type"`
+					Text        string `json:"text"`
+					PartialJSON string `json:"partial_json"`
+				} `json:"delta"`
+				Error struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(data, &ev); err != nil {
+				return nil
+			}
+			switch ev.Type {
+			case "content_block_delta":
+				switch ev.Delta.Type {
+				case "input_json_delta":
+					parser.Write(ev.Delta.PartialJSON)
+				case "text_delta":
+					parser.Write(ev.Delta.Text)
+				}
+			case "error":
+				return errors.New("claude stream error: " + ev.Error.Message)
+			}
+			return nil
+		})
+	})
+	return finishStream(parser, err)
+}
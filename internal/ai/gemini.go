@@ -1,62 +1,99 @@
 package ai
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"io"
-	"net/http"
 	"net/url"
+	"strings"
 )
 
-func GeneratePlaylistGemini(ctx context.Context, apiKey, model, prompt string, count int) ([]Song, error) {
-	fullPrompt := fmt.Sprintf(`You are a music expert. Generate a playlist of exactly %d songs for: "%s"
+const DefaultGeminiModel = "gemini-3-flash-preview"
 
-Return ONLY a JSON array of songs, no other text. Each song should have "title" and "artist" fields.
-Example: [{"title": "Blue in Green", "artist": "Miles Davis"}, {"title": "Take Five", "artist": "Dave Brubeck"}]
+type geminiProvider struct{}
 
-Return only the JSON array, no explanation or markdown formatting.`, count, prompt)
+func (geminiProvider) Info() ProviderInfo {
+	return ProviderInfo{
+		Name:         "gemini",
+		DisplayName:  "Gemini",
+		EnvKeys:      []string{"GOOGLE_API_KEY"},
+		DefaultModel: DefaultGeminiModel,
+	}
+}
 
+func (geminiProvider) request(apiKey, model string, req Request, stream bool) apiRequest {
 	payload := map[string]any{
+		"systemInstruction": map[string]any{
+			"parts": []map[string]string{{"text": req.System}},
+		},
 		"contents": []map[string]any{
-			{"parts": []map[string]string{{"text": fullPrompt}}},
+			{"role": "user", "parts": []map[string]string{{"text": req.User}}},
+		},
+		"generationConfig": map[string]any{
+			"maxOutputTokens":    outputTokenLimit(req.Count),
+			"responseMimeType":   "application/json",
+			"responseJsonSchema": playlistSchema(false),
 		},
-		"generationConfig": map[string]int{"maxOutputTokens": 2048},
 	}
-	buf, _ := json.Marshal(payload)
-	u := "https://generativelanguage.googleapis.com/v1beta/models/" + url.PathEscape(model) + ":generateContent?key=" + url.QueryEscape(apiKey)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(buf))
-	if err != nil {
-		return nil, err
+	// The key travels in a header so transport errors (which include the URL)
+	// never echo it back to the terminal.
+	u := "https://generativelanguage.googleapis.com/v1beta/models/" + url.PathEscape(model) + ":generateContent"
+	if stream {
+		u = "https://generativelanguage.googleapis.com/v1beta/models/" + url.PathEscape(model) + ":streamGenerateContent?alt=sse"
+	}
+	return apiRequest{
+		Provider: "gemini",
+		Label:    "gemini",
+		URL:      u,
+		Header:   map[string]string{"x-goog-api-key": apiKey},
+		Payload:  payload,
 	}
-	req.Header.Set("Content-Type", "application/json")
+}
 
-	resp, err := apiHTTPClient.Do(req)
-	if err != nil {
-		return nil, err
+type geminiResponse struct {
+	Candidates []struct {
+		Content struct {
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"content"`
+	} `json:"candidates"`
+}
+
+func (r geminiResponse) text() string {
+	if len(r.Candidates) == 0 {
+		return ""
 	}
-	defer resp.Body.Close()
-	body, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("gemini api error: %d - %s", resp.StatusCode, string(body))
+	var b strings.Builder
+	for _, part := range r.Candidates[0].Content.Parts {
+		b.WriteString(part.Text)
 	}
+	return b.String()
+}
 
-	var data struct {
-		Candidates []struct {
-			Content struct {
-				Parts []struct {
-					Text string `json:"text"`
-				} `json:"parts"`
-			} `json:"content"`
-		} `json:"candidates"`
+func (p geminiProvider) Generate(ctx context.Context, apiKey, model string, req Request) ([]Song, error) {
+	body, err := p.request(apiKey, model, req, false).do(ctx)
+	if err != nil {
+		return nil, err
 	}
+	var data geminiResponse
 	if err := json.Unmarshal(body, &data); err != nil {
 		return nil, err
 	}
-	text := ""
-	if len(data.Candidates) > 0 && len(data.Candidates[0].Content.Parts) > 0 {
-		text = data.Candidates[0].Content.Parts[0].Text
-	}
-	return parsePlaylistResponse(text)
+	return parsePlaylistResponse(data.text())
+}
+
+func (p geminiProvider) GenerateStream(ctx context.Context, apiKey, model string, req Request, emit func(Song)) ([]Song, error) {
+	parser := newSongStreamParser(emit)
+	err := p.request(apiKey, model, req, true).stream(ctx, func(r io.Reader) error {
+		return readSSE(r, func(data []byte) error {
+			var chunk geminiResponse
+			if err := json.Unmarshal(data, &chunk); err != nil {
+				return nil
+			}
+			parser.Write(chunk.text())
+			return nil
+		})
+	})
+	return finishStream(parser, err)
 }
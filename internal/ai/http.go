@@ -1,8 +1,279 @@
 package ai
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 var apiHTTPClient = &http.Client{Timeout: 25 * time.Second}
+
+// streamHTTPClient serves streaming requests. It has no overall Timeout, which
+// would also cover reading the body and cut long generations off mid-stream;
+// instead the transport bounds the wait for response headers and stream bounds
+// the gap between reads with streamIdleTimeout.
+var streamHTTPClient = &http.Client{Transport: newStreamTransport(25 * time.Second)}
+
+// streamIdleTimeout aborts a stream that stops sending data.
+var streamIdleTimeout = 30 * time.Second
+
+func newStreamTransport(headerTimeout time.Duration) http.RoundTripper {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.ResponseHeaderTimeout = headerTimeout
+	return t
+}
+
+// retryPolicy bounds how provider requests are retried.
+type retryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// MaxRetryAfter caps how long a server-provided Retry-After is honored;
+	// longer waits fail immediately instead of stalling the CLI.
+	MaxRetryAfter time.Duration
+}
+
+var defaultRetryPolicy = retryPolicy{
+	MaxAttempts:   4,
+	BaseDelay:     500 * time.Millisecond,
+	MaxDelay:      8 * time.Second,
+	MaxRetryAfter: 30 * time.Second,
+}
+
+// defaultMaxConcurrent applies to providers that don't set
+// ProviderInfo.MaxConcurrent.
+const defaultMaxConcurrent = 2
+
+// apiRequest is a JSON POST to a provider endpoint.
+type apiRequest struct {
+	// Provider is the registry name; it selects the concurrency limit.
+	Provider string
+	// Label prefixes error messages (e.g. "claude api error: 429 - ...").
+	Label   string
+	URL     string
+	Header  map[string]string
+	Payload any
+}
+
+// APIError is returned when a provider answers with a non-2xx status.
+type APIError struct {
+	Label      string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s api error: %d - %s", e.Label, e.StatusCode, e.Body)
+}
+
+// do sends the request and returns the response body. See run for the retry
+// behavior.
+func (r apiRequest) do(ctx context.Context) ([]byte, error) {
+	var body []byte
+	err := r.run(ctx, apiHTTPClient, func(resp *http.Response) error {
+		b, err := io.ReadAll(resp.Body)
+		body = b
+		return err
+	})
+	return body, err
+}
+
+// stream sends the request and hands the response body to handle as it
+// arrives. Retries only happen before handle is called, so partial output is
+// never replayed. There is no limit on the total duration; the stream fails
+// when ctx is done or no data arrives for streamIdleTimeout.
+func (r apiRequest) stream(ctx context.Context, handle func(io.Reader) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	return r.run(ctx, streamHTTPClient, func(resp *http.Response) error {
+		body := &idleReader{r: resp.Body, timeout: streamIdleTimeout}
+		body.timer = time.AfterFunc(body.timeout, func() {
+			body.idle.Store(true)
+			cancel()
+		})
+		defer body.timer.Stop()
+		err := handle(body)
+		if body.idle.Load() {
+			return fmt.Errorf("%s stream: no data for %s", r.Label, body.timeout)
+		}
+		return err
+	})
+}
+
+// idleReader restarts timer on every read that returns data.
+type idleReader struct {
+	r       io.Reader
+	timeout time.Duration
+	timer   *time.Timer
+	idle    atomic.Bool
+}
+
+func (b *idleReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if n > 0 {
+		b.timer.Reset(b.timeout)
+	}
+	return n, err
+}
+
+// run sends the request, retrying transient failures (network errors, 408,
+// 429, 5xx) with bounded exponential backoff. Retry-After is honored, and no
+// retry is attempted when the wait would outlive ctx's deadline. handle is
+// called once, with the first 2xx response.
+func (r apiRequest) run(ctx context.Context, client *http.Client, handle func(*http.Response) error) error {
+	buf, err := json.Marshal(r.Payload)
+	if err != nil {
+		return err
+	}
+
+	release, err := acquireProviderSlot(ctx, r.Provider)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	policy := defaultRetryPolicy
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		resp, retryAfter, err := r.open(ctx, client, buf)
+		if err == nil {
+			defer resp.Body.Close()
+			return handle(resp)
+		}
+		lastErr = err
+		if attempt >= policy.MaxAttempts || !isRetryable(ctx, err) {
+			return lastErr
+		}
+
+		delay := policy.backoff(attempt)
+		if retryAfter > 0 {
+			if retryAfter > policy.MaxRetryAfter {
+				return lastErr
+			}
+			delay = retryAfter
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return lastErr
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return lastErr
+		case <-timer.C:
+		}
+	}
+}
+
+// open posts buf and returns the response when the status is 2xx. Other
+// statuses are drained into an *APIError along with any Retry-After hint.
+func (r apiRequest) open(ctx context.Context, client *http.Client, buf []byte) (*http.Response, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(buf))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range r.Header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return nil, retryAfter, &APIError{Label: r.Label, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return resp, 0, nil
+}
+
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusRequestTimeout, http.StatusTooManyRequests:
+			return true
+		case http.StatusNotImplemented, http.StatusHTTPVersionNotSupported:
+			return false
+		default:
+			return apiErr.StatusCode >= 500
+		}
+	}
+	// Transport-level failures (connection reset, client timeout) are transient.
+	return true
+}
+
+// backoff returns the delay before retry number attempt (1-based), with up to
+// 20% jitter so concurrent callers don't retry in lockstep.
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	jitter := time.Duration(rand.Int63n(int64(delay)/5 + 1))
+	return delay - jitter
+}
+
+// parseRetryAfter accepts both delta-seconds and HTTP-date forms.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+var (
+	providerSlotsMu sync.Mutex
+	providerSlots   = map[string]chan struct{}{}
+)
+
+// acquireProviderSlot blocks until the provider is below its concurrency limit
+// or ctx is done.
+func acquireProviderSlot(ctx context.Context, provider string) (func(), error) {
+	providerSlotsMu.Lock()
+	slots, ok := providerSlots[provider]
+	if !ok {
+		limit := defaultMaxConcurrent
+		if p, found := Lookup(provider); found && p.Info().MaxConcurrent > 0 {
+			limit = p.Info().MaxConcurrent
+		}
+		slots = make(chan struct{}, limit)
+		providerSlots[provider] = slots
+	}
+	providerSlotsMu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
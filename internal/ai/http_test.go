@@ -0,0 +1,166 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAPIRequestRetriesRateLimit(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	body, err := apiRequest{Provider: "retry-test", Label: "test", URL: srv.URL, Payload: map[string]any{}}.do(context.Background())
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	if string(body) != `{"ok":true}` || calls.Load() != 2 {
+		t.Fatalf("body=%q calls=%d", body, calls.Load())
+	}
+}
+
+func TestAPIRequestDoesNotRetryClientErrors(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte("bad key"))
+	}))
+	defer srv.Close()
+
+	_, err := apiRequest{Provider: "retry-test-401", Label: "test", URL: srv.URL, Payload: map[string]any{}}.do(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 APIError, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("calls: %d", calls.Load())
+	}
+}
+
+func TestAPIRequestStopsBeforeDeadline(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "5")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err := apiRequest{Provider: "retry-test-deadline", Label: "test", URL: srv.URL, Payload: map[string]any{}}.do(ctx)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("waited past the point of no return: %s", time.Since(start))
+	}
+}
+
+func TestAPIRequestStreamOutlivesRequestTimeout(t *testing.T) {
+	prevClient, prevIdle := apiHTTPClient, streamIdleTimeout
+	apiHTTPClient = &http.Client{Timeout: 100 * time.Millisecond}
+	streamIdleTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { apiHTTPClient, streamIdleTimeout = prevClient, prevIdle })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 8; i++ {
+			_, _ = w.Write([]byte("x"))
+			w.(http.Flusher).Flush()
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+
+	var got []byte
+	err := apiRequest{Provider: "stream-test-slow", Label: "test", URL: srv.URL, Payload: map[string]any{}}.stream(context.Background(), func(r io.Reader) error {
+		b, err := io.ReadAll(r)
+		got = b
+		return err
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if string(got) != "xxxxxxxx" {
+		t.Fatalf("body: %q", got)
+	}
+}
+
+func TestAPIRequestStreamFailsWhenIdle(t *testing.T) {
+	prevIdle := streamIdleTimeout
+	streamIdleTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { streamIdleTimeout = prevIdle })
+
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("x"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	err := apiRequest{Provider: "stream-test-idle", Label: "test", URL: srv.URL, Payload: map[string]any{}}.stream(context.Background(), func(r io.Reader) error {
+		_, err := io.ReadAll(r)
+		return err
+	})
+	if err == nil || !strings.Contains(err.Error(), "no data") {
+		t.Fatalf("expected idle error, got %v", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", 0},
+		{"7", 7 * time.Second},
+		{"-1", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{"garbage", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.in, now); got != tt.want {
+			t.Fatalf("parseRetryAfter(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyBackoffIsBounded(t *testing.T) {
+	t.Parallel()
+
+	p := retryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt := 1; attempt <= 10; attempt++ {
+		d := p.backoff(attempt)
+		if d <= 0 || d > time.Second {
+			t.Fatalf("attempt %d: delay %s out of bounds", attempt, d)
+		}
+	}
+}
@@ -3,130 +3,205 @@ package ai
 import (
 	"context"
 	"fmt"
-	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
-func songKey(song Song) string {
-	return strings.ToLower(song.Artist) + ":::" + strings.ToLower(song.Title)
+// ProviderStatus is the outcome of a single provider call.
+type ProviderStatus string
+
+const (
+	ProviderOK    ProviderStatus = "ok"
+	ProviderEmpty ProviderStatus = "empty"
+	ProviderError ProviderStatus = "error"
+)
+
+// ProviderReport records how one provider contributed to a generation.
+type ProviderReport struct {
+	Provider string         `json:"provider"`
+	Model    string         `json:"model"`
+	Status   ProviderStatus `json:"status"`
+	Error    string         `json:"error,omitempty"`
+	Latency  time.Duration  `json:"-"`
+	// LatencyMS mirrors Latency for JSON consumers.
+	LatencyMS int64 `json:"latencyMs"`
+	// Returned is the number of songs the provider answered with.
+	Returned int `json:"returned"`
+	// Kept is the number of distinct songs the provider contributed to the
+	// merged playlist.
+	Kept int `json:"kept"`
+	// Exclusive counts kept songs no other provider suggested.
+	Exclusive int `json:"exclusive"`
 }
 
-func GeneratePlaylistAllProviders(ctx context.Context, keys APIKeys, models Models, prompt string, countPerProvider int) ([]RankedSong, error) {
-	models = models.WithDefaults()
-	type providerCall struct {
-		name  string
-		key   string
-		model string
-		fn    func(context.Context, string, string, string, int) ([]Song, error)
-	}
-	calls := []providerCall{}
-	if keys.Anthropic != "" {
-		calls = append(calls, providerCall{name: "claude", key: keys.Anthropic, model: models.Claude, fn: GeneratePlaylistClaude})
-	}
-	if keys.OpenAI != "" {
-		calls = append(calls, providerCall{name: "openai", key: keys.OpenAI, model: models.OpenAI, fn: GeneratePlaylistOpenAI})
-	}
-	if keys.Google != "" {
-		calls = append(calls, providerCall{name: "gemini", key: keys.Google, model: models.Gemini, fn: GeneratePlaylistGemini})
+// PlaylistResult is the merged output of GeneratePlaylistAllProviders.
+type PlaylistResult struct {
+	Songs     []RankedSong     `json:"songs"`
+	Providers []ProviderReport `json:"providers"`
+}
+
+// Failed returns the reports of providers that returned an error.
+func (r PlaylistResult) Failed() []ProviderReport {
+	out := []ProviderReport{}
+	for _, p := range r.Providers {
+		if p.Status == ProviderError {
+			out = append(out, p)
+		}
 	}
-	if keys.XAI != "" {
-		calls = append(calls, providerCall{name: "grok", key: keys.XAI, model: models.Grok, fn: GeneratePlaylistGrok})
+	return out
+}
+
+func GeneratePlaylistAllProviders(ctx context.Context, keys APIKeys, models Models, weights Weights, req Request) (PlaylistResult, error) {
+	return StreamPlaylistAllProviders(ctx, keys, models, weights, req, nil)
+}
+
+// StreamPlaylistAllProviders queries every configured provider concurrently,
+// like GeneratePlaylistAllProviders, and additionally calls onSong as soon as
+// any provider produces a song not seen before. Providers that implement
+// StreamingProvider are streamed; the rest report their songs when they
+// finish. onSong is called from a single goroutine at a time and may be nil.
+// The returned result is ranked by NewRanker(weights), as in the
+// non-streaming call.
+func StreamPlaylistAllProviders(ctx context.Context, keys APIKeys, models Models, weights Weights, req Request, onSong func(Song)) (PlaylistResult, error) {
+	models = models.WithDefaults()
+	providers := keys.Configured()
+	if len(providers) == 0 {
+		return PlaylistResult{}, fmt.Errorf("no api keys configured")
 	}
-	if len(calls) == 0 {
-		return nil, fmt.Errorf("no api keys configured")
+	allowed := req.filter()
+
+	var emitMu sync.Mutex
+	emitted := map[string]struct{}{}
+	emit := func(song Song) {
+		if onSong == nil || !allowed(song) {
+			return
+		}
+		key := SongKey(song)
+		emitMu.Lock()
+		defer emitMu.Unlock()
+		if _, ok := emitted[key]; ok {
+			return
+		}
+		emitted[key] = struct{}{}
+		onSong(song)
 	}
 
-	all := make([]Song, 0, len(calls)*countPerProvider)
-	var mu sync.Mutex
+	reports := make([]ProviderReport, len(providers))
+	results := make([][]Song, len(providers))
 	var wg sync.WaitGroup
 
-	for _, c := range calls {
-		call := c
+	for i, p := range providers {
+		i, provider := i, p
+		name := provider.Info().Name
+		reports[i] = ProviderReport{Provider: name, Model: models[name]}
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			songs, err := call.fn(ctx, call.key, call.model, prompt, countPerProvider)
-			if err != nil {
-				return
+			start := time.Now()
+			var songs []Song
+			var err error
+			if sp, ok := provider.(StreamingProvider); ok && onSong != nil {
+				songs, err = sp.GenerateStream(ctx, keys[name], models[name], req, emit)
+			} else {
+				songs, err = provider.Generate(ctx, keys[name], models[name], req)
+			}
+			// The final parse can recover songs the incremental parser missed.
+			for _, song := range songs {
+				emit(song)
 			}
-			mu.Lock()
-			all = append(all, songs...)
-			mu.Unlock()
+			report := &reports[i]
+			report.Latency = time.Since(start)
+			report.LatencyMS = report.Latency.Milliseconds()
+			switch {
+			case err != nil:
+				report.Status = ProviderError
+				report.Error = err.Error()
+			case len(songs) == 0:
+				report.Status = ProviderEmpty
+			default:
+				report.Status = ProviderOK
+			}
+			report.Returned = len(songs)
+			results[i] = filterSongs(songs, allowed)
 		}()
 	}
 	wg.Wait()
 
-	counts := map[string]RankedSong{}
-	for _, song := range all {
-		key := songKey(song)
-		existing, ok := counts[key]
-		if ok {
-			existing.Votes++
-			counts[key] = existing
-		} else {
-			counts[key] = RankedSong{Song: song, Votes: 1}
-		}
+	ballots := make([]Ballot, len(providers))
+	for i, songs := range results {
+		ballots[i] = Ballot{Provider: reports[i].Provider, Songs: songs}
 	}
+	ranked := NewRanker(weights).Rank(ballots)
 
-	ranked := make([]RankedSong, 0, len(counts))
-	for _, s := range counts {
-		ranked = append(ranked, s)
+	index := make(map[string]int, len(reports))
+	for i, r := range reports {
+		index[r.Provider] = i
 	}
-	sort.Slice(ranked, func(i, j int) bool {
-		return ranked[i].Votes > ranked[j].Votes
-	})
-	return ranked, nil
-}
-
-func GenerateMoreSongs(ctx context.Context, keys APIKeys, models Models, prompt string, existingSongs []Song, count int) ([]Song, error) {
-	models = models.WithDefaults()
-	existing := map[string]struct{}{}
-	for _, s := range existingSongs {
-		existing[songKey(s)] = struct{}{}
+	for _, song := range ranked {
+		for _, name := range song.Providers {
+			reports[index[name]].Kept++
+			if len(song.Providers) == 1 {
+				reports[index[name]].Exclusive++
+			}
+		}
 	}
 
-	exclude := make([]string, 0, min(10, len(existingSongs)))
-	for i := 0; i < len(existingSongs) && i < 10; i++ {
-		exclude = append(exclude, existingSongs[i].Artist+" - "+existingSongs[i].Title)
-	}
-	newPrompt := prompt + ". Exclude these songs: " + strings.Join(exclude, ", ") + ". Give me different songs."
-
-	providers := []struct {
-		key   string
-		model string
-		fn    func(context.Context, string, string, string, int) ([]Song, error)
-	}{
-		{key: keys.Anthropic, model: models.Claude, fn: GeneratePlaylistClaude},
-		{key: keys.OpenAI, model: models.OpenAI, fn: GeneratePlaylistOpenAI},
-		{key: keys.Google, model: models.Gemini, fn: GeneratePlaylistGemini},
-		{key: keys.XAI, model: models.Grok, fn: GeneratePlaylistGrok},
+	result := PlaylistResult{Songs: ranked, Providers: reports}
+	if failed := result.Failed(); len(failed) == len(reports) {
+		msgs := make([]string, 0, len(failed))
+		for _, f := range failed {
+			msgs = append(msgs, f.Provider+": "+f.Error)
+		}
+		return result, fmt.Errorf("all providers failed: %s", strings.Join(msgs, "; "))
 	}
+	return result, nil
+}
 
-	for _, p := range providers {
-		if p.key == "" {
-			continue
-		}
-		songs, err := p.fn(ctx, p.key, p.model, newPrompt, count)
+// GenerateMoreSongs asks each configured provider in turn for more songs and
+// returns the first answer with anything not excluded by req.
+func GenerateMoreSongs(ctx context.Context, keys APIKeys, models Models, req Request) ([]Song, error) {
+	models = models.WithDefaults()
+	allowed := req.filter()
+	for _, p := range keys.Configured() {
+		name := p.Info().Name
+		songs, err := p.Generate(ctx, keys[name], models[name], req)
 		if err != nil {
 			continue
 		}
-		out := make([]Song, 0, len(songs))
-		for _, s := range songs {
-			if _, ok := existing[songKey(s)]; !ok {
-				out = append(out, s)
-			}
-		}
-		if len(out) > 0 {
+		if out := filterSongs(songs, allowed); len(out) > 0 {
 			return out, nil
 		}
 	}
 	return []Song{}, nil
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// filter returns a predicate that rejects songs by excluded artists and
+// excluded songs.
+func (r Request) filter() func(Song) bool {
+	artists := make(map[string]struct{}, len(r.ExcludeArtists))
+	for _, a := range r.ExcludeArtists {
+		artists[NormalizeArtist(a)] = struct{}{}
+	}
+	songs := make(map[string]struct{}, len(r.ExcludeSongs))
+	for _, s := range r.ExcludeSongs {
+		songs[SongKey(s)] = struct{}{}
+	}
+	return func(song Song) bool {
+		if _, ok := artists[NormalizeArtist(song.Artist)]; ok {
+			return false
+		}
+		_, ok := songs[SongKey(song)]
+		return !ok
+	}
+}
+
+func filterSongs(songs []Song, keep func(Song) bool) []Song {
+	out := make([]Song, 0, len(songs))
+	for _, s := range songs {
+		if keep(s) {
+			out = append(out, s)
+		}
 	}
-	return b
+	return out
 }
@@ -0,0 +1,123 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGeneratePlaylistAllProvidersReportsPerProvider(t *testing.T) {
+	t.Parallel()
+
+	registerForTest(t, stubProvider{
+		info: ProviderInfo{Name: "report-a", DefaultModel: "a-1"},
+		songs: []Song{
+			{Title: "Shared", Artist: "Band"},
+			{Title: "Only A", Artist: "Band"},
+			{Title: "shared", Artist: "band"},
+		},
+	})
+	registerForTest(t, stubProvider{
+		info:  ProviderInfo{Name: "report-b", DefaultModel: "b-1"},
+		songs: []Song{{Title: "Shared", Artist: "Band", Album: "Debut"}},
+	})
+	registerForTest(t, stubProvider{
+		info: ProviderInfo{Name: "report-c", DefaultModel: "c-1"},
+		err:  errors.New("429 too many requests"),
+	})
+
+	keys := APIKeys{"report-a": "k", "report-b": "k", "report-c": "k"}
+	res, err := GeneratePlaylistAllProviders(context.Background(), keys, Models{}, nil, Request{Count: 3})
+	if err != nil {
+		t.Fatalf("GeneratePlaylistAllProviders: %v", err)
+	}
+	if len(res.Songs) != 2 {
+		t.Fatalf("songs: %+v", res.Songs)
+	}
+	if res.Songs[0].Title != "Shared" || res.Songs[0].Votes != 2 || res.Songs[0].Album != "Debut" {
+		t.Fatalf("top song: %+v", res.Songs[0])
+	}
+
+	byName := map[string]ProviderReport{}
+	for _, r := range res.Providers {
+		byName[r.Provider] = r
+	}
+	if r := byName["report-a"]; r.Status != ProviderOK || r.Returned != 3 || r.Kept != 2 || r.Exclusive != 1 || r.Model != "a-1" {
+		t.Fatalf("report-a: %+v", r)
+	}
+	if r := byName["report-b"]; r.Kept != 1 || r.Exclusive != 0 {
+		t.Fatalf("report-b: %+v", r)
+	}
+	if r := byName["report-c"]; r.Status != ProviderError || r.Error == "" {
+		t.Fatalf("report-c: %+v", r)
+	}
+	if len(res.Failed()) != 1 {
+		t.Fatalf("failed: %+v", res.Failed())
+	}
+}
+
+func TestGeneratePlaylistAllProvidersErrorsWhenAllFail(t *testing.T) {
+	t.Parallel()
+
+	registerForTest(t, stubProvider{info: ProviderInfo{Name: "fail-only"}, err: errors.New("bad key")})
+	res, err := GeneratePlaylistAllProviders(context.Background(), APIKeys{"fail-only": "k"}, Models{}, nil, Request{Count: 3})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if len(res.Providers) != 1 || res.Providers[0].Status != ProviderError {
+		t.Fatalf("reports: %+v", res.Providers)
+	}
+}
+
+func TestGeneratePlaylistAllProvidersDropsExcludedArtists(t *testing.T) {
+	t.Parallel()
+
+	registerForTest(t, stubProvider{
+		info: ProviderInfo{Name: "exclude-a"},
+		songs: []Song{
+			{Title: "Photograph", Artist: "Nickelback"},
+			{Title: "Rockstar", Artist: "Nickelback feat. Billy Gibbons"},
+			{Title: "Kiss", Artist: "Prince"},
+		},
+	})
+	req := Request{Count: 3, ExcludeArtists: []string{"nickelback"}}
+	res, err := GeneratePlaylistAllProviders(context.Background(), APIKeys{"exclude-a": "k"}, Models{}, nil, req)
+	if err != nil {
+		t.Fatalf("GeneratePlaylistAllProviders: %v", err)
+	}
+	if len(res.Songs) != 1 || res.Songs[0].Artist != "Prince" {
+		t.Fatalf("songs: %+v", res.Songs)
+	}
+	if res.Providers[0].Returned != 3 || res.Providers[0].Kept != 1 {
+		t.Fatalf("report: %+v", res.Providers[0])
+	}
+}
+
+func TestGenerateMoreSongsFiltersFullExclusionSet(t *testing.T) {
+	t.Parallel()
+
+	registerForTest(t, stubProvider{
+		info: ProviderInfo{Name: "more-a"},
+		songs: []Song{
+			{Title: "Let It Be (Remastered 2009)", Artist: "The Beatles"},
+			{Title: "Something", Artist: "Beatles"},
+		},
+	})
+	exclude := make([]Song, 0, 150)
+	for i := 0; i < 149; i++ {
+		exclude = append(exclude, Song{Title: "Filler", Artist: string(rune('A' + i%26))})
+	}
+	exclude = append(exclude, Song{Title: "Let It Be", Artist: "Beatles"})
+
+	req, err := PromptBuilder{}.Build("sixties", 2, exclude)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	songs, err := GenerateMoreSongs(context.Background(), APIKeys{"more-a": "k"}, Models{}, req)
+	if err != nil {
+		t.Fatalf("GenerateMoreSongs: %v", err)
+	}
+	if len(songs) != 1 || songs[0].Title != "Something" {
+		t.Fatalf("songs: %+v", songs)
+	}
+}
@@ -0,0 +1,37 @@
+package ai
+
+import "strings"
+
+const (
+	DefaultLocalBaseURL = "http://localhost:11434/v1"
+	DefaultLocalModel   = "llama3.1"
+)
+
+// NewLocalProvider returns a provider for a self-hosted, OpenAI-compatible
+// chat-completions server (Ollama, llama.cpp server, vLLM, ...). No API key is
+// required once a base URL or a model is configured; a model alone uses
+// DefaultLocalBaseURL. LOCAL_LLM_API_KEY is sent as a bearer token when set.
+func NewLocalProvider(baseURL, model string) Provider {
+	enabled := strings.TrimSpace(baseURL) != "" || strings.TrimSpace(model) != ""
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = DefaultLocalBaseURL
+	}
+	if strings.TrimSpace(model) == "" {
+		model = DefaultLocalModel
+	}
+	return chatCompletionsProvider{
+		info: ProviderInfo{
+			Name:         "local",
+			DisplayName:  "Local",
+			Aliases:      []string{"ollama"},
+			EnvKeys:      []string{"LOCAL_LLM_API_KEY"},
+			DefaultModel: model,
+			KeyOptional:  enabled,
+			SetupHint:    "LOCAL_LLM_BASE_URL",
+			// A single self-hosted model usually serves one request at a time.
+			MaxConcurrent: 1,
+		},
+		endpoint: strings.TrimRight(strings.TrimSpace(baseURL), "/") + "/chat/completions",
+		errLabel: "local model",
+	}
+}
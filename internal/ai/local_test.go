@@ -0,0 +1,65 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLocalProviderSpeaksChatCompletions(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("unexpected Authorization header: %q", got)
+		}
+		var body struct {
+			Model string `json:"model"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Model != "qwen2.5" {
+			t.Errorf("model: %q", body.Model)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"[{\"title\":\"Take Five\",\"artist\":\"Dave Brubeck\"}]"}}]}`))
+	}))
+	defer srv.Close()
+
+	p := NewLocalProvider(srv.URL+"/v1/", "qwen2.5")
+	info := p.Info()
+	if info.Name != "local" || !info.KeyOptional {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+	songs, err := p.Generate(context.Background(), "", info.DefaultModel, Request{User: "cool jazz", Count: 1})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if len(songs) != 1 || songs[0].Title != "Take Five" {
+		t.Fatalf("unexpected songs: %+v", songs)
+	}
+}
+
+func TestLocalProviderDisabledWithoutBaseURL(t *testing.T) {
+	t.Parallel()
+
+	if NewLocalProvider("", "").Info().KeyOptional {
+		t.Fatalf("expected local provider to require configuration")
+	}
+}
+
+func TestLocalProviderEnabledByModelAlone(t *testing.T) {
+	t.Parallel()
+
+	p := NewLocalProvider("", "qwen2.5")
+	info := p.Info()
+	if !info.KeyOptional || info.DefaultModel != "qwen2.5" {
+		t.Fatalf("expected a model alone to enable the local provider: %+v", info)
+	}
+	if got := p.(chatCompletionsProvider).endpoint; got != DefaultLocalBaseURL+"/chat/completions" {
+		t.Fatalf("endpoint: %s", got)
+	}
+}
@@ -1,46 +1,72 @@
 package ai
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"io"
-	"net/http"
 )
 
-func GeneratePlaylistOpenAI(ctx context.Context, apiKey, model, prompt string, count int) ([]Song, error) {
-	systemPrompt := `You are a music expert. Generate playlists based on user requests.
-Return ONLY a JSON array of songs, no other text. Each song should have "title" and "artist" fields.
-Example: [{"title": "Blue in Green", "artist": "Miles Davis"}, {"title": "Take Five", "artist": "Dave Brubeck"}]`
+const DefaultOpenAIModel = "gpt-5.2"
 
-	userPrompt := fmt.Sprintf("Generate a playlist of exactly %d songs for: \"%s\"\nReturn only the JSON array, no explanation.", count, prompt)
+var openAIProvider = chatCompletionsProvider{
+	info: ProviderInfo{
+		Name:         "openai",
+		DisplayName:  "OpenAI",
+		EnvKeys:      []string{"OPENAI_API_KEY"},
+		DefaultModel: DefaultOpenAIModel,
+	},
+	endpoint: "https://api.openai.com/v1/chat/completions",
+	errLabel: "openai",
+}
+
+// chatCompletionsProvider talks to any endpoint that speaks the OpenAI
+// chat-completions wire format.
+type chatCompletionsProvider struct {
+	info     ProviderInfo
+	endpoint string
+	errLabel string
+}
+
+func (p chatCompletionsProvider) Info() ProviderInfo { return p.info }
 
+func (p chatCompletionsProvider) request(apiKey, model string, req Request, stream bool) apiRequest {
 	payload := map[string]any{
 		"model": model,
 		"messages": []map[string]string{
-			{"role": "system", "content": systemPrompt},
-			{"role": "user", "content": userPrompt},
+			{"role": "system", "content": req.System},
+			{"role": "user", "content": req.User},
+		},
+		"max_tokens": outputTokenLimit(req.Count),
+		"response_format": map[string]any{
+			"type": "json_schema",
+			"json_schema": map[string]any{
+				"name":   "playlist",
+				"strict": true,
+				"schema": playlistSchema(true),
+			},
 		},
-		"max_tokens": 2048,
 	}
-	buf, _ := json.Marshal(payload)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.openai.com/v1/chat/completions", bytes.NewReader(buf))
-	if err != nil {
-		return nil, err
+	if stream {
+		payload["stream"] = true
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+	header := map[string]string{}
+	if apiKey != "" {
+		header["Authorization"] = "Bearer " + apiKey
+	}
+	return apiRequest{
+		Provider: p.info.Name,
+		Label:    p.errLabel,
+		URL:      p.endpoint,
+		Header:   header,
+		Payload:  payload,
+	}
+}
 
-	resp, err := apiHTTPClient.Do(req)
+func (p chatCompletionsProvider) Generate(ctx context.Context, apiKey, model string, req Request) ([]Song, error) {
+	body, err := p.request(apiKey, model, req, false).do(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	body, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("openai api error: %d - %s", resp.StatusCode, string(body))
-	}
 
 	var data struct {
 		Choices []struct {
@@ -58,3 +84,26 @@ Example: [{"title": "Blue in Green", "artist": "Miles Davis"}, {"title": "Take F
 	}
 	return parsePlaylistResponse(text)
 }
+
+func (p chatCompletionsProvider) GenerateStream(ctx context.Context, apiKey, model string, req Request, emit func(Song)) ([]Song, error) {
+	parser := newSongStreamParser(emit)
+	err := p.request(apiKey, model, req, true).stream(ctx, func(r io.Reader) error {
+		return readSSE(r, func(data []byte) error {
+			var chunk struct {
+				Choices []struct {
+					Delta struct {
+						Content string `json:"content"`
+					} `json:"delta"`
+				} `json:"choices"`
+			}
+			if err := json.Unmarshal(data, &chunk); err != nil {
+				return nil
+			}
+			if len(chunk.Choices) > 0 {
+				parser.Write(chunk.Choices[0].Delta.Content)
+			}
+			return nil
+		})
+	})
+	return finishStream(parser, err)
+}
@@ -3,6 +3,7 @@ package ai
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -20,19 +21,70 @@ func parsePlaylistResponse(text string) ([]Song, error) {
 	return nil, fmt.Errorf("failed to parse playlist from ai response")
 }
 
+// parseSongsJSON accepts either a bare song array or a structured-output
+// object of the form {"songs": [...]}.
 func parseSongsJSON(raw string) ([]Song, bool) {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "{") {
+		var wrapped struct {
+			Songs []map[string]any `json:"songs"`
+		}
+		if err := json.Unmarshal([]byte(raw), &wrapped); err != nil || wrapped.Songs == nil {
+			return nil, false
+		}
+		return songsFromMaps(wrapped.Songs), true
+	}
 	var anyArr []map[string]any
 	if err := json.Unmarshal([]byte(raw), &anyArr); err != nil {
 		return nil, false
 	}
-	out := make([]Song, 0, len(anyArr))
-	for _, item := range anyArr {
-		title, _ := item["title"].(string)
-		artist, _ := item["artist"].(string)
-		if title == "" || artist == "" {
-			continue
+	return songsFromMaps(anyArr), true
+}
+
+func songsFromMaps(items []map[string]any) []Song {
+	out := make([]Song, 0, len(items))
+	for _, item := range items {
+		if song, ok := songFromMap(item); ok {
+			out = append(out, song)
+		}
+	}
+	return out
+}
+
+func songFromMap(item map[string]any) (Song, bool) {
+	title, _ := item["title"].(string)
+	artist, _ := item["artist"].(string)
+	title = strings.TrimSpace(title)
+	artist = strings.TrimSpace(artist)
+	if title == "" || artist == "" {
+		return Song{}, false
+	}
+	album, _ := item["album"].(string)
+	isrc, _ := item["isrc"].(string)
+	reason, _ := item["reason"].(string)
+	return Song{
+		Title:           title,
+		Artist:          artist,
+		Album:           strings.TrimSpace(album),
+		Year:            intField(item["year"]),
+		DurationSeconds: intField(item["durationSeconds"]),
+		ISRC:            strings.ToUpper(strings.TrimSpace(isrc)),
+		Reason:          strings.TrimSpace(reason),
+	}, true
+}
+
+// intField tolerates models that emit numbers as strings.
+func intField(v any) int {
+	switch t := v.(type) {
+	case float64:
+		return int(t)
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(t))
+		if err != nil {
+			return 0
 		}
-		out = append(out, Song{Title: title, Artist: artist})
+		return n
+	default:
+		return 0
 	}
-	return out, true
 }
@@ -0,0 +1,65 @@
+package ai
+
+import "testing"
+
+func TestParsePlaylistResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want []Song
+	}{
+		{
+			name: "bare array",
+			in:   `[{"title":"Take Five","artist":"Dave Brubeck"}]`,
+			want: []Song{{Title: "Take Five", Artist: "Dave Brubeck"}},
+		},
+		{
+			name: "array inside prose",
+			in:   "Here you go:\n```json\n[{\"title\":\"So What\",\"artist\":\"Miles Davis\"}]\n```",
+			want: []Song{{Title: "So What", Artist: "Miles Davis"}},
+		},
+		{
+			name: "structured object with metadata",
+			in:   `{"songs":[{"title":"Blue in Green","artist":"Miles Davis","album":"Kind of Blue","year":1959,"durationSeconds":337,"isrc":"usrc15900001","reason":"Quiet and modal."}]}`,
+			want: []Song{{Title: "Blue in Green", Artist: "Miles Davis", Album: "Kind of Blue", Year: 1959, DurationSeconds: 337, ISRC: "USRC15900001", Reason: "Quiet and modal."}},
+		},
+		{
+			name: "strict nulls and string year",
+			in:   `{"songs":[{"title":"Naima","artist":"John Coltrane","album":null,"year":"1960","durationSeconds":null,"isrc":null,"reason":null}]}`,
+			want: []Song{{Title: "Naima", Artist: "John Coltrane", Year: 1960}},
+		},
+		{
+			name: "skips incomplete entries",
+			in:   `[{"title":"","artist":"Nobody"},{"title":"Footprints","artist":"Wayne Shorter"}]`,
+			want: []Song{{Title: "Footprints", Artist: "Wayne Shorter"}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parsePlaylistResponse(tt.in)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d songs, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("song %d: got %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParsePlaylistResponseRejectsGarbage(t *testing.T) {
+	t.Parallel()
+
+	if _, err := parsePlaylistResponse("no playlist here"); err == nil {
+		t.Fatalf("expected error")
+	}
+}
@@ -0,0 +1,354 @@
+package ai
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"sonos-playlist/internal/taste"
+)
+
+// Request is a rendered playlist request. Every provider receives the same
+// one, so prompt wording lives in templates rather than provider code.
+type Request struct {
+	System string
+	User   string
+	Count  int
+	// ExcludeArtists and ExcludeSongs are dropped from every provider's
+	// answer, whether or not the model honored the prompt.
+	ExcludeArtists []string
+	ExcludeSongs   []Song
+}
+
+// PromptVars are the values available to prompt templates.
+type PromptVars struct {
+	Prompt string
+	Count  int
+	Room   string
+	// TimeOfDay is one of morning, afternoon, evening, or night.
+	TimeOfDay string
+	Weekday   string
+	// Taste profile entries; ExcludedArtists are the disliked artists.
+	LikedArtists    []string
+	ExcludedArtists []string
+	LikedGenres     []string
+	DislikedGenres  []string
+	// ExcludedSongs are "Artist - Title" strings the model should not repeat.
+	ExcludedSongs []string
+	// Seed is "Artist - Title" (plus " from Album" when known) of the song
+	// the playlist should follow on from; empty for unseeded requests.
+	Seed string
+	// Instruction and CurrentSongs are set for refine requests only.
+	// CurrentSongs are "Artist - Title" strings in queue order.
+	Instruction  string
+	CurrentSongs []string
+}
+
+// Prompt template file names inside the prompts directory.
+const (
+	SystemPromptFile = "system.tmpl"
+	UserPromptFile   = "user.tmpl"
+	RefinePromptFile = "refine.tmpl"
+)
+
+const defaultSystemTemplate = `You are a music expert. Generate playlists based on user requests.
+Return ONLY a JSON object with a "songs" array, no other text. Each song must have "title" and "artist" fields.
+When you know them, also include "album", "year" (release year), "durationSeconds", and "isrc" for the original studio recording,
+plus a one-sentence "reason" explaining why the song fits the request.
+Example: {"songs": [{"title": "Blue in Green", "artist": "Miles Davis", "album": "Kind of Blue", "year": 1959, "reason": "Slow, modal ballad for a quiet morning."}]}`
+
+const defaultUserTemplate = `Generate a playlist of exactly {{.Count}} songs for: "{{.Prompt}}"
+{{- if .Seed}}
+The playlist continues from the song playing now: {{.Seed}}. Pick songs that flow naturally from it.
+{{- end}}
+{{- template "preferences" .}}
+Return only the JSON object, no explanation.`
+
+const defaultRefineTemplate = `The listeners are hearing a playlist{{if .Prompt}} made for: "{{.Prompt}}"{{end}}.
+Upcoming songs, in order:
+{{- range .CurrentSongs}}
+- {{.}}
+{{- end}}
+
+Revise the upcoming songs following this instruction: "{{.Instruction}}"
+Return exactly {{.Count}} songs. Keep songs from the list above that still fit, spelled exactly as listed,
+and replace the rest with new songs that follow the instruction.
+{{- template "preferences" .}}
+Return only the JSON object, no explanation.`
+
+// preferencesTemplate is available to custom templates as
+// {{template "preferences" .}}.
+const preferencesTemplate = `{{define "preferences"}}
+{{- if .LikedArtists}}
+The listeners enjoy: {{join .LikedArtists ", "}}.
+{{- end}}
+{{- if .LikedGenres}}
+Favorite genres: {{join .LikedGenres ", "}}.
+{{- end}}
+{{- if .ExcludedArtists}}
+Never include songs by: {{join .ExcludedArtists ", "}}.
+{{- end}}
+{{- if .DislikedGenres}}
+Avoid these genres: {{join .DislikedGenres ", "}}.
+{{- end}}
+{{- if .ExcludedSongs}}
+Do not include any of these songs: {{join .ExcludedSongs "; "}}. Give me different songs.
+{{- end}}
+{{- end}}`
+
+// PromptTemplates holds the system, user, and refine message templates.
+type PromptTemplates struct {
+	System *template.Template
+	User   *template.Template
+	Refine *template.Template
+}
+
+var promptFuncs = template.FuncMap{"join": strings.Join}
+
+func newPromptTemplate(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(promptFuncs).Parse(preferencesTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return t.Parse(text)
+}
+
+// DefaultPromptTemplates returns the built-in templates.
+func DefaultPromptTemplates() PromptTemplates {
+	return PromptTemplates{
+		System: template.Must(newPromptTemplate("system", defaultSystemTemplate)),
+		User:   template.Must(newPromptTemplate("user", defaultUserTemplate)),
+		Refine: template.Must(newPromptTemplate("refine", defaultRefineTemplate)),
+	}
+}
+
+// LoadPromptTemplates reads system.tmpl, user.tmpl, and refine.tmpl from dir,
+// falling back to the built-in template for any file that doesn't exist.
+func LoadPromptTemplates(dir string) (PromptTemplates, error) {
+	out := DefaultPromptTemplates()
+	for _, f := range []struct {
+		file string
+		dst  **template.Template
+	}{
+		{SystemPromptFile, &out.System},
+		{UserPromptFile, &out.User},
+		{RefinePromptFile, &out.Refine},
+	} {
+		b, err := os.ReadFile(filepath.Join(dir, f.file))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return PromptTemplates{}, err
+		}
+		t, err := newPromptTemplate(f.file, string(b))
+		if err != nil {
+			return PromptTemplates{}, fmt.Errorf("parse prompt template: %w", err)
+		}
+		*f.dst = t
+	}
+	return out, nil
+}
+
+// maxExcludedSongs bounds how many excluded songs are spelled out in the
+// prompt. The full list is still filtered out of the results.
+const maxExcludedSongs = 100
+
+// PromptBuilder renders Requests from templates plus the household taste
+// profile and playback context.
+type PromptBuilder struct {
+	// Templates defaults to DefaultPromptTemplates when unset.
+	Templates PromptTemplates
+	Taste     taste.Profile
+	Room      string
+	// Seed, when set, is the song the playlist should follow on from.
+	Seed *Song
+	// Now defaults to time.Now.
+	Now func() time.Time
+}
+
+// Build renders a request for count songs matching prompt. exclude lists
+// songs already played or queued, most important first; only the first
+// maxExcludedSongs appear in the prompt text.
+func (b PromptBuilder) Build(prompt string, count int, exclude []Song) (Request, error) {
+	listed := exclude
+	if len(listed) > maxExcludedSongs {
+		listed = listed[:maxExcludedSongs]
+	}
+	vars := b.vars(prompt, count)
+	vars.ExcludedSongs = songLines(listed)
+
+	req, err := b.render(b.templates().User, vars)
+	if err != nil {
+		return Request{}, err
+	}
+	req.ExcludeSongs = exclude
+	return req, nil
+}
+
+// BuildRefine renders a request that revises current, the upcoming part of a
+// playlist made for prompt, according to instruction. The answer is the full
+// revised list of count songs.
+func (b PromptBuilder) BuildRefine(prompt, instruction string, current []Song, count int) (Request, error) {
+	vars := b.vars(prompt, count)
+	vars.Instruction = instruction
+	vars.CurrentSongs = songLines(current)
+	return b.render(b.templates().Refine, vars)
+}
+
+// builtinTemplates fills in whatever a PromptBuilder's Templates leave unset.
+var builtinTemplates = DefaultPromptTemplates()
+
+func (b PromptBuilder) templates() PromptTemplates {
+	tmpl := b.Templates
+	def := builtinTemplates
+	if tmpl.System == nil {
+		tmpl.System = def.System
+	}
+	if tmpl.User == nil {
+		tmpl.User = def.User
+	}
+	if tmpl.Refine == nil {
+		tmpl.Refine = def.Refine
+	}
+	return tmpl
+}
+
+func (b PromptBuilder) vars(prompt string, count int) PromptVars {
+	now := time.Now()
+	if b.Now != nil {
+		now = b.Now()
+	}
+	return PromptVars{
+		Prompt:          prompt,
+		Count:           count,
+		Room:            b.Room,
+		TimeOfDay:       timeOfDay(now),
+		Weekday:         now.Weekday().String(),
+		LikedArtists:    b.Taste.LikedArtists,
+		ExcludedArtists: b.Taste.DislikedArtists,
+		LikedGenres:     b.Taste.LikedGenres,
+		DislikedGenres:  b.Taste.DislikedGenres,
+		Seed:            seedLine(b.Seed),
+	}
+}
+
+func seedLine(seed *Song) string {
+	if seed == nil {
+		return ""
+	}
+	line := seed.Artist + " - " + seed.Title
+	if seed.Album != "" {
+		line += " from " + seed.Album
+	}
+	return line
+}
+
+// render executes the system template and user (the message template for
+// this kind of request) against vars.
+func (b PromptBuilder) render(user *template.Template, vars PromptVars) (Request, error) {
+	system, err := renderPrompt(b.templates().System, vars)
+	if err != nil {
+		return Request{}, err
+	}
+	text, err := renderPrompt(user, vars)
+	if err != nil {
+		return Request{}, err
+	}
+	return Request{
+		System:         system,
+		User:           text,
+		Count:          vars.Count,
+		ExcludeArtists: b.Taste.DislikedArtists,
+	}, nil
+}
+
+func songLines(songs []Song) []string {
+	out := make([]string, 0, len(songs))
+	for _, s := range songs {
+		out = append(out, s.Artist+" - "+s.Title)
+	}
+	return out
+}
+
+func renderPrompt(t *template.Template, vars PromptVars) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("render %s prompt: %w", t.Name(), err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func timeOfDay(t time.Time) string {
+	switch h := t.Hour(); {
+	case h >= 5 && h < 12:
+		return "morning"
+	case h >= 12 && h < 17:
+		return "afternoon"
+	case h >= 17 && h < 22:
+		return "evening"
+	default:
+		return "night"
+	}
+}
+
+// playlistSchema returns the JSON schema requested from providers that support
+// structured output. The document is an object wrapping the song array since
+// most structured-output APIs require an object at the top level.
+//
+// strict produces the OpenAI strict-mode shape: every property is required and
+// optional values are expressed as nullable types.
+func playlistSchema(strict bool) map[string]any {
+	optional := func(kind string) map[string]any {
+		if strict {
+			return map[string]any{"type": []string{kind, "null"}}
+		}
+		return map[string]any{"type": kind}
+	}
+	properties := map[string]any{
+		"title":           map[string]any{"type": "string"},
+		"artist":          map[string]any{"type": "string"},
+		"album":           optional("string"),
+		"year":            optional("integer"),
+		"durationSeconds": optional("integer"),
+		"isrc":            optional("string"),
+		"reason":          optional("string"),
+	}
+	required := []string{"title", "artist"}
+	if strict {
+		required = []string{"title", "artist", "album", "year", "durationSeconds", "isrc", "reason"}
+	}
+	song := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"songs": map[string]any{"type": "array", "items": song},
+		},
+		"required":             []string{"songs"},
+		"additionalProperties": false,
+	}
+}
+
+// Output budget for structured answers. Each song carries up to seven fields,
+// so the limit grows with the requested count instead of truncating long
+// playlists mid-document.
+const (
+	minOutputTokens     = 2048
+	maxOutputTokens     = 16384
+	outputTokensPerSong = 150
+	outputTokenOverhead = 512
+)
+
+// outputTokenLimit returns the max output tokens to request for count songs.
+func outputTokenLimit(count int) int {
+	return min(max(count*outputTokensPerSong+outputTokenOverhead, minOutputTokens), maxOutputTokens)
+}
@@ -0,0 +1,169 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sonos-playlist/internal/taste"
+)
+
+func TestPromptBuilderDefaultTemplates(t *testing.T) {
+	t.Parallel()
+
+	b := PromptBuilder{
+		Taste: taste.Profile{
+			LikedArtists:    []string{"Sade"},
+			DislikedArtists: []string{"Nickelback"},
+			DislikedGenres:  []string{"nu metal"},
+		},
+	}
+	req, err := b.Build("dinner party", 12, []Song{{Artist: "Sade", Title: "Smooth Operator"}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if req.Count != 12 || !strings.Contains(req.System, `"title" and "artist"`) {
+		t.Fatalf("unexpected request: %+v", req)
+	}
+	// The default prompts ask for the same shape as the structured-output schema.
+	if !strings.Contains(req.System, `{"songs": [`) || strings.Contains(req.System+req.User, "JSON array") {
+		t.Fatalf("prompts should ask for a songs object:\n%s\n%s", req.System, req.User)
+	}
+	for _, want := range []string{
+		`exactly 12 songs for: "dinner party"`,
+		"The listeners enjoy: Sade.",
+		"Never include songs by: Nickelback.",
+		"Avoid these genres: nu metal.",
+		"Do not include any of these songs: Sade - Smooth Operator.",
+	} {
+		if !strings.Contains(req.User, want) {
+			t.Errorf("user prompt missing %q:\n%s", want, req.User)
+		}
+	}
+	if len(req.ExcludeArtists) != 1 || req.ExcludeArtists[0] != "Nickelback" {
+		t.Fatalf("ExcludeArtists: %v", req.ExcludeArtists)
+	}
+}
+
+func TestPromptBuilderCustomTemplates(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	user := `{{.Count}} songs for {{.Prompt}} in the {{.Room}} on a {{.Weekday}} {{.TimeOfDay}}.{{template "preferences" .}}`
+	if err := os.WriteFile(filepath.Join(dir, UserPromptFile), []byte(user), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := LoadPromptTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadPromptTemplates: %v", err)
+	}
+
+	b := PromptBuilder{
+		Templates: tmpl,
+		Taste:     taste.Profile{DislikedArtists: []string{"Nickelback"}},
+		Room:      "Kitchen",
+		Now:       func() time.Time { return time.Date(2026, 10, 17, 19, 30, 0, 0, time.UTC) },
+	}
+	req, err := b.Build("cooking", 5, nil)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	want := "5 songs for cooking in the Kitchen on a Saturday evening.\nNever include songs by: Nickelback."
+	if req.User != want {
+		t.Fatalf("user prompt:\n%q\nwant:\n%q", req.User, want)
+	}
+	if req.System != strings.TrimSpace(defaultSystemTemplate) {
+		t.Fatalf("missing system.tmpl should fall back to the default")
+	}
+}
+
+func TestLoadPromptTemplatesReportsParseErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, SystemPromptFile), []byte("{{.Count"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPromptTemplates(dir); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
+
+func TestTimeOfDay(t *testing.T) {
+	t.Parallel()
+
+	tests := map[int]string{0: "night", 4: "night", 5: "morning", 11: "morning", 12: "afternoon", 17: "evening", 21: "evening", 22: "night"}
+	for hour, want := range tests {
+		if got := timeOfDay(time.Date(2026, 1, 1, hour, 0, 0, 0, time.UTC)); got != want {
+			t.Errorf("hour %d: got %q, want %q", hour, got, want)
+		}
+	}
+}
+
+func TestPromptBuilderRefine(t *testing.T) {
+	t.Parallel()
+
+	b := PromptBuilder{Taste: taste.Profile{DislikedArtists: []string{"Nickelback"}}}
+	current := []Song{{Artist: "Miles Davis", Title: "So What"}, {Artist: "Sade", Title: "Cherish the Day"}}
+	req, err := b.BuildRefine("late night jazz", "more upbeat", current, 2)
+	if err != nil {
+		t.Fatalf("BuildRefine: %v", err)
+	}
+	for _, want := range []string{
+		`made for: "late night jazz"`,
+		"- Miles Davis - So What\n- Sade - Cherish the Day",
+		`instruction: "more upbeat"`,
+		"Return exactly 2 songs.",
+		"Never include songs by: Nickelback.",
+	} {
+		if !strings.Contains(req.User, want) {
+			t.Errorf("refine prompt missing %q:\n%s", want, req.User)
+		}
+	}
+	if req.Count != 2 || len(req.ExcludeArtists) != 1 {
+		t.Fatalf("unexpected request: %+v", req)
+	}
+}
+
+func TestPromptBuilderSeed(t *testing.T) {
+	t.Parallel()
+
+	b := PromptBuilder{Seed: &Song{Artist: "Khruangbin", Title: "Maria También", Album: "Con Todo El Mundo"}}
+	req, err := b.Build("keep this vibe going", 10, nil)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	want := "continues from the song playing now: Khruangbin - Maria También from Con Todo El Mundo."
+	if !strings.Contains(req.User, want) {
+		t.Fatalf("user prompt missing %q:\n%s", want, req.User)
+	}
+}
+
+func TestProviderPayloadsScaleOutputTokens(t *testing.T) {
+	t.Parallel()
+
+	req := Request{System: "s", User: "u", Count: 50}
+	want := 50*outputTokensPerSong + outputTokenOverhead
+
+	claude := claudeProvider{}.request("key", DefaultClaudeModel, req, false).Payload.(map[string]any)
+	if got := claude["max_tokens"]; got != want {
+		t.Fatalf("claude max_tokens = %v, want %d", got, want)
+	}
+	openai := openAIProvider.request("key", DefaultOpenAIModel, req, false).Payload.(map[string]any)
+	if got := openai["max_tokens"]; got != want {
+		t.Fatalf("openai max_tokens = %v, want %d", got, want)
+	}
+	gemini := geminiProvider{}.request("key", DefaultGeminiModel, req, false).Payload.(map[string]any)
+	if got := gemini["generationConfig"].(map[string]any)["maxOutputTokens"]; got != want {
+		t.Fatalf("gemini maxOutputTokens = %v, want %d", got, want)
+	}
+
+	if got := outputTokenLimit(5); got != minOutputTokens {
+		t.Fatalf("outputTokenLimit(5) = %d, want %d", got, minOutputTokens)
+	}
+	if got := outputTokenLimit(1000); got != maxOutputTokens {
+		t.Fatalf("outputTokenLimit(1000) = %d, want %d", got, maxOutputTokens)
+	}
+}
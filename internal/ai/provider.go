@@ -0,0 +1,171 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// ProviderInfo describes a registered playlist backend.
+type ProviderInfo struct {
+	// Name is the canonical, lower-case identifier used for --provider,
+	// config files, and the APIKeys/Models maps.
+	Name string
+	// DisplayName is shown to users (e.g. "Claude").
+	DisplayName string
+	// Aliases are additional names accepted by Lookup (e.g. "xai" for grok).
+	Aliases []string
+	// EnvKeys lists environment variables consulted for the API key, in
+	// priority order.
+	EnvKeys []string
+	// DefaultModel is used when Models has no entry for the provider.
+	DefaultModel string
+	// KeyOptional marks providers that are usable without an API key (e.g. a
+	// self-hosted endpoint).
+	KeyOptional bool
+	// SetupHint names the setting users must provide to enable the provider.
+	// Defaults to the first EnvKeys entry.
+	SetupHint string
+	// MaxConcurrent limits in-flight requests to the provider (0 = default).
+	MaxConcurrent int
+}
+
+// Provider generates a playlist for a rendered request.
+type Provider interface {
+	Info() ProviderInfo
+	Generate(ctx context.Context, apiKey, model string, req Request) ([]Song, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []Provider
+)
+
+func init() {
+	Register(claudeProvider{})
+	Register(openAIProvider)
+	Register(geminiProvider{})
+	Register(grokProvider)
+	Register(NewLocalProvider("", ""))
+}
+
+// Register adds a provider to the registry. Registering a name that already
+// exists replaces the earlier provider in place, keeping its position.
+func Register(p Provider) {
+	name := strings.ToLower(p.Info().Name)
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for i, existing := range registry {
+		if strings.ToLower(existing.Info().Name) == name {
+			registry[i] = p
+			return
+		}
+	}
+	registry = append(registry, p)
+}
+
+// Providers returns all registered providers in registration order.
+func Providers() []Provider {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]Provider(nil), registry...)
+}
+
+// Lookup resolves a provider by name or alias (case-insensitive).
+func Lookup(name string) (Provider, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return nil, false
+	}
+	for _, p := range Providers() {
+		info := p.Info()
+		if strings.ToLower(info.Name) == name {
+			return p, true
+		}
+		for _, alias := range info.Aliases {
+			if strings.ToLower(alias) == name {
+				return p, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// ProviderNames returns every accepted provider name, including aliases.
+func ProviderNames() []string {
+	out := []string{}
+	for _, p := range Providers() {
+		info := p.Info()
+		out = append(out, info.Name)
+		out = append(out, info.Aliases...)
+	}
+	return out
+}
+
+// ProviderEnvKeys returns the setting that enables each registered provider,
+// usually its primary API key environment variable.
+func ProviderEnvKeys() []string {
+	out := []string{}
+	for _, p := range Providers() {
+		info := p.Info()
+		switch {
+		case info.SetupHint != "":
+			out = append(out, info.SetupHint)
+		case len(info.EnvKeys) > 0:
+			out = append(out, info.EnvKeys[0])
+		}
+	}
+	return out
+}
+
+// APIKeys maps a canonical provider name to its API key.
+type APIKeys map[string]string
+
+// Configured returns the providers that have a key set, in registration
+// order. Providers that need no key count once k has an entry for them, even
+// an empty one, so Only still excludes them.
+func (k APIKeys) Configured() []Provider {
+	out := []Provider{}
+	for _, p := range Providers() {
+		info := p.Info()
+		key, ok := k[info.Name]
+		if key != "" || (info.KeyOptional && ok) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Only returns a copy of k restricted to the named provider.
+func (k APIKeys) Only(name string) APIKeys {
+	return APIKeys{name: k[name]}
+}
+
+// Models maps a canonical provider name to a model identifier.
+type Models map[string]string
+
+// WithDefaults returns a copy of m with every registered provider's default
+// model filled in where no model was set.
+func (m Models) WithDefaults() Models {
+	out := Models{}
+	for k, v := range m {
+		out[k] = v
+	}
+	for _, p := range Providers() {
+		info := p.Info()
+		if out[info.Name] == "" {
+			out[info.Name] = info.DefaultModel
+		}
+	}
+	return out
+}
+
+// ConfiguredDisplayNames returns display names of the providers with keys set.
+func ConfiguredDisplayNames(keys APIKeys) []string {
+	providers := keys.Configured()
+	out := make([]string, 0, len(providers))
+	for _, p := range providers {
+		out = append(out, p.Info().DisplayName)
+	}
+	return out
+}
@@ -0,0 +1,136 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type stubProvider struct {
+	info  ProviderInfo
+	songs []Song
+	err   error
+}
+
+func (p stubProvider) Info() ProviderInfo { return p.info }
+
+func (p stubProvider) Generate(ctx context.Context, apiKey, model string, req Request) ([]Song, error) {
+	return p.songs, p.err
+}
+
+// registerForTest registers p until the test ends, then restores whatever
+// provider held its name before, so stubs never leak into other tests.
+func registerForTest(t *testing.T, p Provider) {
+	t.Helper()
+	name := strings.ToLower(p.Info().Name)
+	var prev Provider
+	for _, existing := range Providers() {
+		if strings.ToLower(existing.Info().Name) == name {
+			prev = existing
+		}
+	}
+	Register(p)
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		for i, existing := range registry {
+			if strings.ToLower(existing.Info().Name) != name {
+				continue
+			}
+			if prev != nil {
+				registry[i] = prev
+			} else {
+				registry = append(registry[:i:i], registry[i+1:]...)
+			}
+			return
+		}
+	})
+}
+
+func TestLookupResolvesAliases(t *testing.T) {
+	t.Parallel()
+
+	p, ok := Lookup(" XAI ")
+	if !ok {
+		t.Fatalf("expected xai alias to resolve")
+	}
+	if got := p.Info().Name; got != "grok" {
+		t.Fatalf("name: %q", got)
+	}
+	if _, ok := Lookup("nope"); ok {
+		t.Fatalf("expected unknown provider to fail")
+	}
+}
+
+func TestAPIKeysConfiguredKeepsRegistrationOrder(t *testing.T) {
+	t.Parallel()
+
+	keys := APIKeys{"grok": "x", "claude": "a"}
+	got := ConfiguredDisplayNames(keys)
+	if len(got) != 2 || got[0] != "Claude" || got[1] != "Grok" {
+		t.Fatalf("unexpected providers: %v", got)
+	}
+	only := keys.Only("grok")
+	if len(only) != 1 || only["grok"] != "x" {
+		t.Fatalf("unexpected Only result: %v", only)
+	}
+}
+
+func TestAPIKeysOnlyExcludesKeyOptionalProviders(t *testing.T) {
+	t.Parallel()
+
+	registerForTest(t, stubProvider{info: ProviderInfo{Name: "optional-a", KeyOptional: true}})
+	keys := APIKeys{"claude": "a", "optional-a": ""}
+	if got := providerNames(keys.Configured()); !containsName(got, "optional-a") || !containsName(got, "claude") {
+		t.Fatalf("configured: %v", got)
+	}
+	if got := providerNames(keys.Only("claude").Configured()); len(got) != 1 || got[0] != "claude" {
+		t.Fatalf("only claude: %v", got)
+	}
+	if got := providerNames(APIKeys{"claude": "a"}.Configured()); containsName(got, "optional-a") {
+		t.Fatalf("key-optional provider without an entry: %v", got)
+	}
+}
+
+func providerNames(providers []Provider) []string {
+	out := make([]string, 0, len(providers))
+	for _, p := range providers {
+		out = append(out, p.Info().Name)
+	}
+	return out
+}
+
+func containsName(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRegisterForTestRestoresTheRegistry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("register", func(t *testing.T) {
+		registerForTest(t, stubProvider{info: ProviderInfo{Name: "cleanup-a"}})
+		if _, ok := Lookup("cleanup-a"); !ok {
+			t.Fatalf("stub not registered")
+		}
+	})
+	if _, ok := Lookup("cleanup-a"); ok {
+		t.Fatalf("stub left in the registry")
+	}
+}
+
+func TestModelsWithDefaults(t *testing.T) {
+	t.Parallel()
+
+	m := Models{"claude": "custom"}.WithDefaults()
+	if m["claude"] != "custom" {
+		t.Fatalf("claude: %q", m["claude"])
+	}
+	if m["gemini"] != DefaultGeminiModel {
+		t.Fatalf("gemini: %q", m["gemini"])
+	}
+}
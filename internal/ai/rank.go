@@ -0,0 +1,201 @@
+package ai
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Weights maps a canonical provider name to how much its votes count.
+// Providers without an entry (or with a non-positive weight) count as 1.
+type Weights map[string]float64
+
+func (w Weights) of(provider string) float64 {
+	if v := w[provider]; v > 0 {
+		return v
+	}
+	return 1
+}
+
+// DefaultPositionWeight is the share of a vote that depends on list position.
+const DefaultPositionWeight = 0.5
+
+// Ballot is one provider's answer, in the order the model listed it.
+type Ballot struct {
+	Provider string
+	Songs    []Song
+}
+
+// Ranker merges ballots from several providers into a single consensus list.
+//
+// Songs are grouped by a normalized artist/title key (see SongKey), or by
+// ISRC when two providers agree on one. Each provider votes for a song at most
+// once; the vote is worth the provider's weight, scaled down linearly by the
+// song's position in that provider's list so the first pick counts fully and
+// the last counts (1 - PositionWeight).
+type Ranker struct {
+	Weights Weights
+	// PositionWeight is in [0, 1]; 0 ignores list order entirely.
+	PositionWeight float64
+}
+
+// NewRanker returns a Ranker with the default position weighting.
+func NewRanker(weights Weights) Ranker {
+	return Ranker{Weights: weights, PositionWeight: DefaultPositionWeight}
+}
+
+// Rank returns the merged songs ordered by score, then votes, then first
+// appearance. The first spelling seen for a song is kept, with metadata
+// merged in from later duplicates.
+func (r Ranker) Rank(ballots []Ballot) []RankedSong {
+	type entry struct {
+		song      RankedSong
+		firstSeen int
+	}
+	entries := map[string]*entry{}
+	isrcKeys := map[string]string{}
+	order := 0
+
+	for _, ballot := range ballots {
+		weight := r.Weights.of(ballot.Provider)
+		voted := map[string]struct{}{}
+		songs := uniqueBallot(ballot.Songs)
+		for pos, song := range songs {
+			key := SongKey(song)
+			if song.ISRC != "" {
+				if k, ok := isrcKeys[song.ISRC]; ok {
+					key = k
+				} else {
+					isrcKeys[song.ISRC] = key
+				}
+			}
+			if _, dup := voted[key]; dup {
+				continue
+			}
+			voted[key] = struct{}{}
+
+			score := weight * (1 - r.PositionWeight*float64(pos)/float64(len(songs)))
+			e, ok := entries[key]
+			if !ok {
+				e = &entry{song: RankedSong{Song: song}, firstSeen: order}
+				entries[key] = e
+				order++
+			} else {
+				e.song.Song = e.song.Song.mergeMetadata(song)
+			}
+			e.song.Votes++
+			e.song.Score += score
+			e.song.Providers = append(e.song.Providers, ballot.Provider)
+		}
+	}
+
+	list := make([]*entry, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.song.Score != b.song.Score {
+			return a.song.Score > b.song.Score
+		}
+		if a.song.Votes != b.song.Votes {
+			return a.song.Votes > b.song.Votes
+		}
+		return a.firstSeen < b.firstSeen
+	})
+	out := make([]RankedSong, 0, len(list))
+	for _, e := range list {
+		out = append(out, e.song)
+	}
+	return out
+}
+
+// uniqueBallot drops repeats within a single provider's list so they don't
+// push later songs down.
+func uniqueBallot(songs []Song) []Song {
+	seen := map[string]struct{}{}
+	out := make([]Song, 0, len(songs))
+	for _, s := range songs {
+		key := SongKey(s)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, s)
+	}
+	return out
+}
+
+// SongKey identifies a song independent of formatting differences between
+// providers: case, punctuation, a leading "The", featured artists, and
+// remaster/live/edit suffixes are ignored.
+func SongKey(song Song) string {
+	return NormalizeArtist(song.Artist) + ":::" + NormalizeTitle(song.Title)
+}
+
+var (
+	// featuredRE matches a featured-artist clause through the end of the
+	// string. The clause must follow whitespace or a bracket after some other
+	// text, so names like "Feat of Clay" are left alone; group 1 is the last
+	// character kept.
+	featuredRE = regexp.MustCompile(`(?i)(\S)\s*(?:\s|[\(\[]\s*)(?:feat\.?|ft\.?|featuring)\s.*$`)
+	// bracketRE matches one parenthesized or bracketed segment.
+	bracketRE = regexp.MustCompile(`\s*[\(\[][^\)\]]*[\)\]]`)
+	// versionRE recognizes segments that describe a recording rather than a
+	// different song.
+	versionRE = regexp.MustCompile(`(?i)\b(remaster(ed)?|live|mono|stereo|version|edit|single|album|deluxe|bonus|anniversary|explicit|clean|demo|acoustic|recorded|session)\b`)
+	// dashSuffixRE matches a trailing " - Something" segment.
+	dashSuffixRE = regexp.MustCompile(`\s+[-–—]\s+([^-–—]+)$`)
+)
+
+// NormalizeArtist lower-cases the artist, drops featured artists and a
+// leading "The", and strips punctuation.
+func NormalizeArtist(artist string) string {
+	s := featuredRE.ReplaceAllString(artist, "$1")
+	s = normalizeText(s)
+	s = strings.TrimPrefix(s, "the ")
+	if s == "" {
+		return normalizeText(artist)
+	}
+	return s
+}
+
+// NormalizeTitle lower-cases the title, drops featured artists and
+// parenthesized or dash-separated version notes such as "(Remastered 2009)"
+// or "- Live at Leeds", and strips punctuation. A title that is nothing but
+// such notes is kept as written so it never collapses into an empty key.
+func NormalizeTitle(title string) string {
+	s := featuredRE.ReplaceAllString(title, "$1")
+	s = bracketRE.ReplaceAllStringFunc(s, func(seg string) string {
+		if versionRE.MatchString(seg) {
+			return ""
+		}
+		return seg
+	})
+	if m := dashSuffixRE.FindStringSubmatch(s); m != nil && versionRE.MatchString(m[1]) {
+		s = s[:len(s)-len(m[0])]
+	}
+	if s = normalizeText(s); s == "" {
+		return normalizeText(title)
+	}
+	return s
+}
+
+// normalizeText lower-cases s, spells out "&", replaces punctuation with
+// spaces, and collapses whitespace.
+func normalizeText(s string) string {
+	s = strings.ToLower(strings.ReplaceAll(s, "&", " and "))
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\'' || r == '’' || r == '.':
+			// "Don't" and "Dont", "R.E.M." and "REM" compare equal.
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,227 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSongKeyNormalization(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a, b Song
+		same bool
+	}{
+		{
+			name: "leading the and remaster suffix",
+			a:    Song{Artist: "The Beatles", Title: "Let It Be (Remastered 2009)"},
+			b:    Song{Artist: "Beatles", Title: "Let It Be"},
+			same: true,
+		},
+		{
+			name: "dash suffix",
+			a:    Song{Artist: "The Who", Title: "Baba O'Riley - Live at Leeds"},
+			b:    Song{Artist: "Who", Title: "Baba ORiley"},
+			same: true,
+		},
+		{
+			name: "featured artist in artist field",
+			a:    Song{Artist: "Daft Punk feat. Pharrell Williams", Title: "Get Lucky"},
+			b:    Song{Artist: "Daft Punk", Title: "Get Lucky (feat. Pharrell Williams)"},
+			same: true,
+		},
+		{
+			name: "punctuation and ampersand",
+			a:    Song{Artist: "Simon & Garfunkel", Title: "Mrs. Robinson"},
+			b:    Song{Artist: "simon and garfunkel", Title: "Mrs Robinson!"},
+			same: true,
+		},
+		{
+			name: "unicode dash and radio edit",
+			a:    Song{Artist: "Robyn", Title: "Dancing On My Own – Radio Edit"},
+			b:    Song{Artist: "Robyn", Title: "Dancing on My Own"},
+			same: true,
+		},
+		{
+			name: "meaningful parenthetical kept",
+			a:    Song{Artist: "Kate Bush", Title: "Running Up That Hill (A Deal with God)"},
+			b:    Song{Artist: "Kate Bush", Title: "Running Up That Hill"},
+			same: false,
+		},
+		{
+			name: "remix is a different recording",
+			a:    Song{Artist: "Robyn", Title: "Dancing on My Own (Remix)"},
+			b:    Song{Artist: "Robyn", Title: "Dancing on My Own"},
+			same: false,
+		},
+		{
+			name: "club mix is a different recording",
+			a:    Song{Artist: "Robyn", Title: "Dancing on My Own (Club Mix)"},
+			b:    Song{Artist: "Robyn", Title: "Dancing on My Own"},
+			same: false,
+		},
+		{
+			name: "title starting with feat",
+			a:    Song{Artist: "Ice Nine Kills", Title: "Feat of Clay"},
+			b:    Song{Artist: "Ice Nine Kills", Title: "Hunger"},
+			same: false,
+		},
+		{
+			name: "artist starting with ft",
+			a:    Song{Artist: "Ft Smith Band", Title: "Intro"},
+			b:    Song{Artist: "Other Band", Title: "Intro"},
+			same: false,
+		},
+		{
+			name: "title that is only a version note",
+			a:    Song{Artist: "Sigur Rós", Title: "(Live)"},
+			b:    Song{Artist: "Sigur Rós", Title: "(Demo)"},
+			same: false,
+		},
+		{
+			name: "featured artist without brackets in title",
+			a:    Song{Artist: "Calvin Harris", Title: "This Is What You Came For ft. Rihanna"},
+			b:    Song{Artist: "Calvin Harris", Title: "This Is What You Came For"},
+			same: true,
+		},
+		{
+			name: "different artists",
+			a:    Song{Artist: "Miles Davis", Title: "So What"},
+			b:    Song{Artist: "Bill Evans", Title: "So What"},
+			same: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ka, kb := SongKey(tt.a), SongKey(tt.b)
+			if (ka == kb) != tt.same {
+				t.Fatalf("SongKey(a)=%q SongKey(b)=%q, want same=%v", ka, kb, tt.same)
+			}
+		})
+	}
+}
+
+func TestRankerRank(t *testing.T) {
+	t.Parallel()
+
+	songs := func(titles ...string) []Song {
+		out := make([]Song, 0, len(titles))
+		for _, title := range titles {
+			out = append(out, Song{Artist: "Band", Title: title})
+		}
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		ranker  Ranker
+		ballots []Ballot
+		want    []string
+		votes   []int
+	}{
+		{
+			name:   "votes beat position",
+			ranker: NewRanker(nil),
+			ballots: []Ballot{
+				{Provider: "a", Songs: songs("One", "Two", "Three")},
+				{Provider: "b", Songs: songs("X", "Y", "Three")},
+			},
+			want:  []string{"Three", "One", "X", "Two", "Y"},
+			votes: []int{2, 1, 1, 1, 1},
+		},
+		{
+			name:   "position breaks ties between single votes",
+			ranker: NewRanker(nil),
+			ballots: []Ballot{
+				{Provider: "a", Songs: songs("First", "Second", "Third", "Fourth")},
+			},
+			want: []string{"First", "Second", "Third", "Fourth"},
+		},
+		{
+			name:   "no position weight falls back to first appearance",
+			ranker: Ranker{},
+			ballots: []Ballot{
+				{Provider: "a", Songs: songs("A1", "A2")},
+				{Provider: "b", Songs: songs("B1", "A2")},
+			},
+			want:  []string{"A2", "A1", "B1"},
+			votes: []int{2, 1, 1},
+		},
+		{
+			name:   "provider weight",
+			ranker: NewRanker(Weights{"trusted": 3}),
+			ballots: []Ballot{
+				{Provider: "a", Songs: songs("Common", "Filler")},
+				{Provider: "b", Songs: songs("Common", "Other")},
+				{Provider: "trusted", Songs: songs("Pick")},
+			},
+			want: []string{"Pick", "Common", "Filler", "Other"},
+		},
+		{
+			name:   "fuzzy duplicates merge and vote once per provider",
+			ranker: NewRanker(nil),
+			ballots: []Ballot{
+				{Provider: "a", Songs: []Song{
+					{Artist: "The Beatles", Title: "Let It Be (Remastered 2009)"},
+					{Artist: "Beatles", Title: "Let It Be"},
+				}},
+				{Provider: "b", Songs: []Song{{Artist: "Beatles", Title: "Let It Be", Year: 1970}}},
+			},
+			want:  []string{"Let It Be (Remastered 2009)"},
+			votes: []int{2},
+		},
+		{
+			name:   "shared isrc merges different spellings",
+			ranker: NewRanker(nil),
+			ballots: []Ballot{
+				{Provider: "a", Songs: []Song{{Artist: "Prince", Title: "Purple Rain", ISRC: "USWB10400049"}}},
+				{Provider: "b", Songs: []Song{{Artist: "Prince & The Revolution", Title: "Purple Rain", ISRC: "USWB10400049"}}},
+			},
+			want:  []string{"Purple Rain"},
+			votes: []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ranked := tt.ranker.Rank(tt.ballots)
+			got := make([]string, 0, len(ranked))
+			for _, s := range ranked {
+				got = append(got, s.Title)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Fatalf("order: got %v, want %v", got, tt.want)
+			}
+			for i, v := range tt.votes {
+				if ranked[i].Votes != v {
+					t.Fatalf("%s votes: got %d, want %d", ranked[i].Title, ranked[i].Votes, v)
+				}
+			}
+		})
+	}
+}
+
+func TestRankerMergesMetadataAndProviders(t *testing.T) {
+	t.Parallel()
+
+	ranked := NewRanker(nil).Rank([]Ballot{
+		{Provider: "a", Songs: []Song{{Artist: "Miles Davis", Title: "So What"}}},
+		{Provider: "b", Songs: []Song{{Artist: "Miles Davis", Title: "So What", Album: "Kind of Blue", Year: 1959}}},
+	})
+	if len(ranked) != 1 {
+		t.Fatalf("ranked: %+v", ranked)
+	}
+	got := ranked[0]
+	if got.Album != "Kind of Blue" || got.Year != 1959 {
+		t.Fatalf("metadata not merged: %+v", got)
+	}
+	if strings.Join(got.Providers, ",") != "a,b" || got.Score != 2 {
+		t.Fatalf("providers=%v score=%v", got.Providers, got.Score)
+	}
+}
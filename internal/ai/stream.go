@@ -0,0 +1,155 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// StreamingProvider is implemented by providers that can stream their answer.
+// emit is called for each song as soon as its JSON object is complete; the
+// returned slice is the full parsed answer (which may include songs the
+// incremental parser could not recognize).
+type StreamingProvider interface {
+	Provider
+	GenerateStream(ctx context.Context, apiKey, model string, req Request, emit func(Song)) ([]Song, error)
+}
+
+// songStreamParser incrementally scans model output and emits every JSON
+// object that sits directly inside an array and looks like a song. It works
+// for bare arrays as well as structured {"songs": [...]} documents, and ignores
+// prose or markdown fences around the JSON.
+type songStreamParser struct {
+	buf      bytes.Buffer
+	scanned  int
+	stack    []byte
+	inString bool
+	escaped  bool
+	objStart int
+	emit     func(Song)
+	// songs holds every song emitted so far, in order.
+	songs []Song
+}
+
+func newSongStreamParser(emit func(Song)) *songStreamParser {
+	return &songStreamParser{objStart: -1, emit: emit}
+}
+
+func (p *songStreamParser) Write(chunk string) {
+	p.buf.WriteString(chunk)
+	data := p.buf.Bytes()
+	for ; p.scanned < len(data); p.scanned++ {
+		c := data[p.scanned]
+		if p.inString {
+			switch {
+			case p.escaped:
+				p.escaped = false
+			case c == '\\':
+				p.escaped = true
+			case c == '"':
+				p.inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			// Quotes in prose before the JSON starts are not string delimiters.
+			if len(p.stack) > 0 {
+				p.inString = true
+			}
+		case '[':
+			p.stack = append(p.stack, c)
+		case '{':
+			if len(p.stack) > 0 && p.stack[len(p.stack)-1] == '[' {
+				p.objStart = p.scanned
+			}
+			p.stack = append(p.stack, c)
+		case ']', '}':
+			if len(p.stack) == 0 {
+				continue
+			}
+			p.stack = p.stack[:len(p.stack)-1]
+			if c == '}' && p.objStart >= 0 && len(p.stack) > 0 && p.stack[len(p.stack)-1] == '[' {
+				p.emitObject(data[p.objStart : p.scanned+1])
+				p.objStart = -1
+			}
+		}
+	}
+}
+
+func (p *songStreamParser) emitObject(raw []byte) {
+	var item map[string]any
+	if err := json.Unmarshal(raw, &item); err != nil {
+		return
+	}
+	song, ok := songFromMap(item)
+	if !ok {
+		return
+	}
+	p.songs = append(p.songs, song)
+	if p.emit != nil {
+		p.emit(song)
+	}
+}
+
+// Text returns everything written so far.
+func (p *songStreamParser) Text() string { return p.buf.String() }
+
+// readSSE calls onData with the payload of every server-sent event in r.
+// Multi-line data fields are joined with newlines, and the OpenAI "[DONE]"
+// sentinel ends the stream.
+func readSSE(r io.Reader, onData func(data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4<<20)
+	var data []string
+	flush := func() error {
+		if len(data) == 0 {
+			return nil
+		}
+		payload := strings.Join(data, "\n")
+		data = data[:0]
+		if payload == "[DONE]" {
+			return io.EOF
+		}
+		return onData([]byte(payload))
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if err := flush(); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "data:") {
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// finishStream parses the complete streamed text. On a mid-stream error, or
+// when the full text doesn't parse, the songs emitted so far are returned
+// instead.
+func finishStream(parser *songStreamParser, streamErr error) ([]Song, error) {
+	if streamErr != nil {
+		return parser.songs, streamErr
+	}
+	songs, err := parsePlaylistResponse(parser.Text())
+	if err != nil && len(parser.songs) > 0 {
+		return parser.songs, nil
+	}
+	return songs, err
+}
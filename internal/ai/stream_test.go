@@ -0,0 +1,147 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSongStreamParserEmitsAcrossChunks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "bare array",
+			text: `[{"title":"So What","artist":"Miles Davis"},{"title":"Naima","artist":"John Coltrane"}]`,
+			want: []string{"So What", "Naima"},
+		},
+		{
+			name: "structured object",
+			text: `{"songs":[{"title":"Take Five","artist":"Dave Brubeck","album":"Time Out","year":1959}]}`,
+			want: []string{"Take Five"},
+		},
+		{
+			name: "prose and fences",
+			text: "Here's a \"chill\" list:\n```json\n[{\"title\":\"Blue in Green\",\"artist\":\"Miles Davis\"}]\n```",
+			want: []string{"Blue in Green"},
+		},
+		{
+			name: "braces inside strings",
+			text: `[{"title":"A {weird} ] title","artist":"Someone \"Quoted\""}]`,
+			want: []string{"A {weird} ] title"},
+		},
+		{
+			name: "incomplete object not emitted",
+			text: `[{"title":"Done","artist":"A"},{"title":"Half`,
+			want: []string{"Done"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// Feed one byte at a time so every token boundary is exercised.
+			var got []string
+			p := newSongStreamParser(func(s Song) { got = append(got, s.Title) })
+			for _, r := range tt.text {
+				p.Write(string(r))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadSSEStopsAtDone(t *testing.T) {
+	t.Parallel()
+
+	in := "event: message\ndata: one\n\n: comment\ndata: two\ndata: lines\n\ndata: [DONE]\n\ndata: ignored\n\n"
+	var got []string
+	if err := readSSE(strings.NewReader(in), func(data []byte) error {
+		got = append(got, string(data))
+		return nil
+	}); err != nil {
+		t.Fatalf("readSSE: %v", err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint([]string{"one", "two\nlines"}) {
+		t.Fatalf("unexpected events: %q", got)
+	}
+}
+
+func TestChatCompletionsStreamEmitsSongsBeforeFinish(t *testing.T) {
+	t.Parallel()
+
+	chunks := []string{
+		`{"songs":[{"title":"So What",`,
+		`"artist":"Miles Davis"},`,
+		`{"title":"Naima","artist":"John Coltrane"}]}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"stream":true`) {
+			t.Errorf("request did not ask for a stream")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, c := range chunks {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", c)
+			w.(http.Flusher).Flush()
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	p := NewLocalProvider(srv.URL, "test").(StreamingProvider)
+	var emitted []string
+	songs, err := p.GenerateStream(context.Background(), "", "test", Request{User: "jazz", Count: 2}, func(s Song) {
+		emitted = append(emitted, s.Title)
+	})
+	if err != nil {
+		t.Fatalf("GenerateStream: %v", err)
+	}
+	if len(songs) != 2 || fmt.Sprint(emitted) != "[So What Naima]" {
+		t.Fatalf("songs=%+v emitted=%v", songs, emitted)
+	}
+}
+
+func TestStreamPlaylistAllProvidersEmitsEachSongOnce(t *testing.T) {
+	t.Parallel()
+
+	registerForTest(t, stubProvider{
+		info:  ProviderInfo{Name: "stream-a"},
+		songs: []Song{{Title: "Shared", Artist: "X"}, {Title: "Only A", Artist: "Y"}},
+	})
+	registerForTest(t, stubProvider{
+		info:  ProviderInfo{Name: "stream-b"},
+		songs: []Song{{Title: "shared", Artist: "x"}},
+	})
+
+	keys := APIKeys{"stream-a": "k", "stream-b": "k"}
+	seen := map[string]int{}
+	result, err := StreamPlaylistAllProviders(context.Background(), keys, Models{}, nil, Request{Count: 2}, func(s Song) {
+		seen[SongKey(s)]++
+	})
+	if err != nil {
+		t.Fatalf("StreamPlaylistAllProviders: %v", err)
+	}
+	for key, n := range seen {
+		if n != 1 {
+			t.Fatalf("%s emitted %d times", key, n)
+		}
+	}
+	if seen["x:::shared"] != 1 || seen["y:::only a"] != 1 {
+		t.Fatalf("missing emissions: %v", seen)
+	}
+	if len(result.Songs) == 0 || result.Songs[0].Votes < 2 {
+		t.Fatalf("expected shared song ranked first: %+v", result.Songs)
+	}
+}
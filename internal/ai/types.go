@@ -1,48 +1,44 @@
 package ai
 
+// Song is a single playlist entry. Title and Artist are always set; the
+// remaining fields are filled in when the provider knows them.
 type Song struct {
-	Title  string `json:"title"`
-	Artist string `json:"artist"`
+	Title           string `json:"title"`
+	Artist          string `json:"artist"`
+	Album           string `json:"album,omitempty"`
+	Year            int    `json:"year,omitempty"`
+	DurationSeconds int    `json:"durationSeconds,omitempty"`
+	ISRC            string `json:"isrc,omitempty"`
+	// Reason is a short note from the model on why the song fits the prompt.
+	Reason string `json:"reason,omitempty"`
 }
 
-type APIKeys struct {
-	Anthropic string
-	OpenAI    string
-	Google    string
-	XAI       string
-}
-
-const (
-	DefaultClaudeModel = "claude-sonnet-4-6"
-	DefaultOpenAIModel = "gpt-5.2"
-	DefaultGeminiModel = "gemini-3-flash-preview"
-	DefaultGrokModel   = "grok-4-1-fast-reasoning"
-)
-
-type Models struct {
-	Claude string
-	OpenAI string
-	Gemini string
-	Grok   string
-}
-
-func (m Models) WithDefaults() Models {
-	if m.Claude == "" {
-		m.Claude = DefaultClaudeModel
+// mergeMetadata fills empty optional fields of s from other.
+func (s Song) mergeMetadata(other Song) Song {
+	if s.Album == "" {
+		s.Album = other.Album
+	}
+	if s.Year == 0 {
+		s.Year = other.Year
 	}
-	if m.OpenAI == "" {
-		m.OpenAI = DefaultOpenAIModel
+	if s.DurationSeconds == 0 {
+		s.DurationSeconds = other.DurationSeconds
 	}
-	if m.Gemini == "" {
-		m.Gemini = DefaultGeminiModel
+	if s.ISRC == "" {
+		s.ISRC = other.ISRC
 	}
-	if m.Grok == "" {
-		m.Grok = DefaultGrokModel
+	if s.Reason == "" {
+		s.Reason = other.Reason
 	}
-	return m
+	return s
 }
 
+// RankedSong is a merged song with the consensus behind it.
 type RankedSong struct {
 	Song
 	Votes int `json:"votes"`
+	// Score is the weighted, position-adjusted vote total used for ordering.
+	Score float64 `json:"score"`
+	// Providers lists the providers that suggested the song.
+	Providers []string `json:"providers,omitempty"`
 }
@@ -4,28 +4,72 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+
+	"sonos-playlist/internal/ai"
+	"sonos-playlist/internal/resolve"
+	"sonos-playlist/internal/sonos"
+	"sonos-playlist/internal/storage"
 )
 
+// Provider is the canonical name of a provider registered in the ai package.
 type Provider string
 
+const fallbackProvider Provider = "claude"
+
+// Backend selects how AI playlists reach the speakers.
+type Backend string
+
 const (
-	ProviderClaude Provider = "claude"
-	ProviderOpenAI Provider = "openai"
-	ProviderGemini Provider = "gemini"
-	ProviderGrok   Provider = "grok"
+	// BackendHTTP goes through node-sonos-http-api.
+	BackendHTTP Backend = "http"
+	// BackendNative talks UPnP to the speakers directly.
+	BackendNative Backend = "native"
 )
 
+// ParseBackend accepts "http" or "native" (case-insensitive).
+func ParseBackend(s string) (Backend, bool) {
+	switch b := Backend(strings.ToLower(strings.TrimSpace(s))); b {
+	case BackendHTTP, BackendNative:
+		return b, true
+	default:
+		return "", false
+	}
+}
+
 type Config struct {
-	AnthropicAPIKey string
-	OpenAIAPIKey    string
-	GoogleAPIKey    string
-	XAIAPIKey       string
+	APIKeys         ai.APIKeys
 	SonosAPIURL     string
 	DefaultRoom     string
 	DefaultProvider Provider
 	DefaultCount    int
+	// ProviderWeights scales each provider's votes when merging playlists.
+	ProviderWeights ai.Weights
+	// PromptDir holds optional system.tmpl/user.tmpl prompt overrides.
+	PromptDir string
+	// MatchRulesFile optionally adjusts how search results are scored.
+	MatchRulesFile string
+	// ExcludeRecentDays skips songs queued within this many days.
+	ExcludeRecentDays int
+	// Backend defaults to BackendHTTP. BackendSet reports that it was chosen
+	// in the environment or config file; otherwise the CLI switches to the
+	// native backend when the HTTP API is unreachable.
+	Backend    Backend
+	BackendSet bool
+	// Services is the preferred order of music services for matching songs
+	// with the native backend.
+	Services []string
+	// SearchCacheTTL is how long song search results are reused; zero
+	// disables the cache.
+	SearchCacheTTL        time.Duration
+	SearchCacheMaxEntries int
+	// BlocklistTTL is how long a track that failed to play stays blocked;
+	// zero keeps blocks until they are removed.
+	BlocklistTTL time.Duration
 }
 
 type fileConfig struct {
@@ -33,6 +77,18 @@ type fileConfig struct {
 	DefaultRoom     string   `json:"defaultRoom"`
 	DefaultProvider Provider `json:"defaultProvider"`
 	DefaultCount    int      `json:"defaultCount"`
+	LocalBaseURL    string   `json:"localBaseUrl"`
+	LocalModel      string   `json:"localModel"`
+	// ProviderWeights is keyed by provider name or alias, e.g. {"claude": 1.5}.
+	ProviderWeights   map[string]float64 `json:"providerWeights"`
+	ExcludeRecentDays int                `json:"excludeRecentDays"`
+	Backend           string             `json:"backend"`
+	Services          []string           `json:"services"`
+	// SearchCacheTTL is a Go duration, e.g. "72h"; "0" disables the cache.
+	SearchCacheTTL        string `json:"searchCacheTtl"`
+	SearchCacheMaxEntries int    `json:"searchCacheMaxEntries"`
+	// BlocklistTTL is a Go duration, e.g. "720h"; "0" never expires blocks.
+	BlocklistTTL string `json:"blocklistTtl"`
 }
 
 func init() {
@@ -45,9 +101,48 @@ func Load() Config {
 	sonosURL := firstNonEmpty(os.Getenv("SONOS_API_URL"), fc.SonosAPIURL, "http://localhost:5005")
 	defaultRoom := firstNonEmpty(os.Getenv("SONOS_DEFAULT_ROOM"), fc.DefaultRoom)
 
-	provider := fc.DefaultProvider
-	if provider == "" {
-		provider = ProviderClaude
+	localURL := firstNonEmpty(os.Getenv("LOCAL_LLM_BASE_URL"), fc.LocalBaseURL)
+	localModel := firstNonEmpty(os.Getenv("LOCAL_LLM_MODEL"), fc.LocalModel)
+	if localURL != "" || localModel != "" {
+		ai.Register(ai.NewLocalProvider(localURL, localModel))
+	}
+
+	provider := fallbackProvider
+	if p, ok := ai.Lookup(string(fc.DefaultProvider)); ok {
+		provider = Provider(p.Info().Name)
+	}
+
+	promptDir, rulesFile := "", ""
+	if dir := configDir(); dir != "" {
+		promptDir = filepath.Join(dir, "prompts")
+		rulesFile = filepath.Join(dir, sonos.MatchRulesFile)
+	}
+
+	backend, backendSet := ParseBackend(firstNonEmpty(os.Getenv("SONOS_BACKEND"), fc.Backend))
+	if !backendSet {
+		backend = BackendHTTP
+	}
+
+	services := resolve.ParseServices(firstNonEmpty(os.Getenv("SONOS_SERVICES"), strings.Join(fc.Services, ",")))
+	if len(services) == 0 {
+		services = resolve.DefaultServices
+	}
+
+	cacheTTL := storage.DefaultSearchCacheTTL
+	if d, err := time.ParseDuration(firstNonEmpty(os.Getenv("SONOS_SEARCH_CACHE_TTL"), fc.SearchCacheTTL)); err == nil && d >= 0 {
+		cacheTTL = d
+	}
+	cacheMax := fc.SearchCacheMaxEntries
+	if n, err := strconv.Atoi(os.Getenv("SONOS_SEARCH_CACHE_MAX_ENTRIES")); err == nil {
+		cacheMax = n
+	}
+	if cacheMax <= 0 {
+		cacheMax = storage.DefaultSearchCacheMaxEntries
+	}
+
+	blockTTL := storage.DefaultBlocklistTTL
+	if d, err := time.ParseDuration(firstNonEmpty(os.Getenv("SONOS_BLOCKLIST_TTL"), fc.BlocklistTTL)); err == nil && d >= 0 {
+		blockTTL = d
 	}
 
 	count := fc.DefaultCount
@@ -56,24 +151,70 @@ func Load() Config {
 	}
 
 	return Config{
-		AnthropicAPIKey: os.Getenv("ANTHROPIC_API_KEY"),
-		OpenAIAPIKey:    os.Getenv("OPENAI_API_KEY"),
-		GoogleAPIKey:    os.Getenv("GOOGLE_API_KEY"),
-		XAIAPIKey:       firstNonEmpty(os.Getenv("XAI_API_KEY"), os.Getenv("GROK_API_KEY")),
-		SonosAPIURL:     sonosURL,
-		DefaultRoom:     defaultRoom,
-		DefaultProvider: provider,
-		DefaultCount:    count,
+		APIKeys:               loadAPIKeys(),
+		SonosAPIURL:           sonosURL,
+		DefaultRoom:           defaultRoom,
+		DefaultProvider:       provider,
+		DefaultCount:          count,
+		ProviderWeights:       providerWeights(fc.ProviderWeights),
+		PromptDir:             promptDir,
+		MatchRulesFile:        rulesFile,
+		ExcludeRecentDays:     fc.ExcludeRecentDays,
+		Backend:               backend,
+		BackendSet:            backendSet,
+		Services:              services,
+		SearchCacheTTL:        cacheTTL,
+		SearchCacheMaxEntries: cacheMax,
+		BlocklistTTL:          blockTTL,
 	}
 }
 
-func loadFileConfig() fileConfig {
+// providerWeights resolves aliases to canonical provider names and drops
+// unknown providers.
+func providerWeights(raw map[string]float64) ai.Weights {
+	weights := ai.Weights{}
+	for name, w := range raw {
+		if p, ok := ai.Lookup(name); ok {
+			weights[p.Info().Name] = w
+		}
+	}
+	return weights
+}
+
+// loadAPIKeys reads each registered provider's key from its environment
+// variables, first non-empty wins. Providers that need no key get an entry
+// even when it is empty, so they count as configured.
+func loadAPIKeys() ai.APIKeys {
+	keys := ai.APIKeys{}
+	for _, p := range ai.Providers() {
+		info := p.Info()
+		values := make([]string, 0, len(info.EnvKeys))
+		for _, env := range info.EnvKeys {
+			values = append(values, os.Getenv(env))
+		}
+		if v := firstNonEmpty(values...); v != "" || info.KeyOptional {
+			keys[info.Name] = v
+		}
+	}
+	return keys
+}
+
+// configDir is ~/.config/sonos-playlist, or "" when there is no home
+// directory.
+func configDir() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "sonos-playlist")
+}
+
+func loadFileConfig() fileConfig {
+	dir := configDir()
+	if dir == "" {
 		return fileConfig{}
 	}
-	configPath := filepath.Join(home, ".config", "sonos-playlist", "config.json")
-	b, err := os.ReadFile(configPath)
+	b, err := os.ReadFile(filepath.Join(dir, "config.json"))
 	if err != nil {
 		return fileConfig{}
 	}
@@ -0,0 +1,173 @@
+// Package daemon runs the playback monitor as a background process. CLI runs
+// hand it rooms to watch over a Unix socket, so they can exit as soon as the
+// queue is built.
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"sonos-playlist/internal/config"
+	"sonos-playlist/internal/playlist"
+	"sonos-playlist/internal/sonos"
+	"sonos-playlist/internal/storage"
+)
+
+// ErrNotRunning is returned by Client calls when no daemon answers.
+var ErrNotRunning = errors.New("daemon is not running")
+
+// Paths locates the daemon's PID file, control socket, and log.
+type Paths struct {
+	PIDFile string
+	Socket  string
+	Log     string
+}
+
+// DefaultPaths keeps the daemon's files in the storage directory.
+func DefaultPaths() Paths {
+	return PathsIn(storage.Dir())
+}
+
+// PathsIn returns the daemon's files inside dir.
+func PathsIn(dir string) Paths {
+	return Paths{
+		PIDFile: filepath.Join(dir, "daemon.pid"),
+		Socket:  filepath.Join(dir, "daemon.sock"),
+		Log:     filepath.Join(dir, "daemon.log"),
+	}
+}
+
+// ReadPID returns the PID recorded by a running (or crashed) daemon.
+func (p Paths) ReadPID() (int, error) {
+	b, err := os.ReadFile(p.PIDFile)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("invalid pid file %s", p.PIDFile)
+	}
+	return pid, nil
+}
+
+// WatchRequest hands a room's newly queued songs to the daemon.
+type WatchRequest struct {
+	Room string `json:"room"`
+	// Backend, SonosAPI, and Services say how to reach the room, as given to
+	// the CLI run that queued the songs.
+	Backend  config.Backend `json:"backend"`
+	SonosAPI string         `json:"sonosApi,omitempty"`
+	Services []string       `json:"services,omitempty"`
+	// MatchRules are the run's effective search rules, after its flag
+	// overrides and prompt relaxation. Nil uses the daemon's rules file. The
+	// room keeps the rules of the request that started watching it.
+	MatchRules *sonos.MatchRules      `json:"matchRules,omitempty"`
+	Songs      []playlist.WatchedSong `json:"songs"`
+}
+
+// RoomStatus describes one monitored room.
+type RoomStatus struct {
+	Room    string         `json:"room"`
+	Backend config.Backend `json:"backend"`
+	Songs   int            `json:"songs"`
+	Since   string         `json:"since"`
+}
+
+// Status describes the running daemon.
+type Status struct {
+	PID       int          `json:"pid"`
+	StartedAt string       `json:"startedAt"`
+	Rooms     []RoomStatus `json:"rooms"`
+}
+
+// Client talks to the daemon over its control socket.
+type Client struct {
+	http *http.Client
+}
+
+// NewClient returns a client for the daemon listening on paths.Socket.
+func NewClient(paths Paths) *Client {
+	socket := paths.Socket
+	return &Client{http: &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		},
+	}}
+}
+
+// Status reports what the daemon is doing.
+func (c *Client) Status(ctx context.Context) (Status, error) {
+	var st Status
+	err := c.do(ctx, http.MethodGet, "/status", nil, &st)
+	return st, err
+}
+
+// Watch asks the daemon to monitor req.Room.
+func (c *Client) Watch(ctx context.Context, req WatchRequest) error {
+	return c.do(ctx, http.MethodPost, "/watch", req, nil)
+}
+
+// Stop asks the daemon to exit. It returns once the daemon has acknowledged.
+func (c *Client) Stop(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/stop", nil, nil)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	// The host is ignored; every request goes to the socket.
+	req, err := http.NewRequestWithContext(ctx, method, "http://daemon"+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return ErrNotRunning
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(b, &e) == nil && e.Error != "" {
+			return errors.New(e.Error)
+		}
+		return fmt.Errorf("daemon: %s", resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(b, out)
+}
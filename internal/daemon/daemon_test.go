@@ -0,0 +1,114 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"sonos-playlist/internal/ai"
+	"sonos-playlist/internal/config"
+	"sonos-playlist/internal/output"
+	"sonos-playlist/internal/playlist"
+	"sonos-playlist/internal/sonos"
+)
+
+// idleBackend monitors nothing until the daemon stops.
+type idleBackend struct {
+	playlist.Backend
+}
+
+func (idleBackend) StartMonitor(ctx context.Context, options sonos.MonitorOptions) sonos.MonitorHandle {
+	done := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		close(done)
+	}()
+	return sonos.MonitorHandle{Stop: func() {}, Done: done}
+}
+
+func TestServerLifecycle(t *testing.T) {
+	paths := PathsIn(t.TempDir())
+	client := NewClient(paths)
+	ctx := context.Background()
+
+	if _, err := client.Status(ctx); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Status before start: %v, want ErrNotRunning", err)
+	}
+
+	connects := 0
+	srv := &Server{
+		Paths: paths,
+		Out:   output.New(output.Options{Quiet: true}),
+		Connect: func(ctx context.Context, req WatchRequest, out *output.Output) (playlist.Backend, error) {
+			connects++
+			return idleBackend{}, nil
+		},
+	}
+	done := make(chan error, 1)
+	go func() { done <- srv.Run(ctx) }()
+
+	var st Status
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		var err error
+		if st, err = client.Status(ctx); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("daemon never answered: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if st.PID != os.Getpid() || len(st.Rooms) != 0 {
+		t.Fatalf("status = %+v", st)
+	}
+	if pid, err := paths.ReadPID(); err != nil || pid != os.Getpid() {
+		t.Fatalf("ReadPID = %d, %v", pid, err)
+	}
+	if err := (&Server{Paths: paths, Out: srv.Out}).Run(ctx); err == nil {
+		t.Fatal("second daemon started")
+	}
+
+	song := func(title string) playlist.WatchedSong {
+		return playlist.WatchedSong{Song: ai.Song{Artist: "Bill Evans", Title: title}}
+	}
+	req := WatchRequest{Room: "Kitchen", Backend: config.BackendNative, Songs: []playlist.WatchedSong{song("Peace Piece"), song("Waltz for Debby")}}
+	if err := client.Watch(ctx, req); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	req.Songs = []playlist.WatchedSong{song("Peace Piece"), song("My Foolish Heart")}
+	if err := client.Watch(ctx, req); err != nil {
+		t.Fatalf("Watch again: %v", err)
+	}
+	if err := client.Watch(ctx, WatchRequest{}); err == nil {
+		t.Fatal("Watch without a room succeeded")
+	}
+
+	st, err := client.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if connects != 1 || len(st.Rooms) != 1 || st.Rooms[0].Room != "Kitchen" || st.Rooms[0].Songs != 3 || st.Rooms[0].Backend != config.BackendNative {
+		t.Fatalf("status = %+v after %d connects", st, connects)
+	}
+
+	if err := client.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("daemon did not stop")
+	}
+	if err := WaitStopped(ctx, paths, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(paths.Socket); !os.IsNotExist(err) {
+		t.Fatalf("socket left behind: %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package daemon
+
+import "syscall"
+
+// detachedProcAttr puts the daemon in its own session, so it outlives the
+// terminal that started it and doesn't get its Ctrl+C.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,74 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// Spawn starts exe with args as a detached daemon process whose output goes
+// to paths.Log, and waits until it answers on the control socket.
+func Spawn(ctx context.Context, paths Paths, exe string, args ...string) (Status, error) {
+	client := NewClient(paths)
+	if st, err := client.Status(ctx); err == nil {
+		return st, errors.New("daemon is already running")
+	}
+	logFile, err := os.OpenFile(paths.Log, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return Status{}, err
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		return Status{}, err
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.NewTimer(5 * time.Second)
+	defer timeout.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return Status{}, ctx.Err()
+		case err := <-exited:
+			if err == nil {
+				err = errors.New("exited")
+			}
+			return Status{}, errors.New("daemon did not start (" + err.Error() + "); see " + paths.Log)
+		case <-timeout.C:
+			return Status{}, errors.New("daemon did not answer in time; see " + paths.Log)
+		case <-ticker.C:
+			if st, err := client.Status(ctx); err == nil {
+				return st, nil
+			}
+		}
+	}
+}
+
+// WaitStopped waits for the daemon's PID file to disappear after a stop
+// request.
+func WaitStopped(ctx context.Context, paths Paths, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		if _, err := os.Stat(paths.PIDFile); os.IsNotExist(err) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.New("daemon did not stop in time")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sonos-playlist/internal/config"
+	nativesonos "sonos-playlist/internal/native/sonos"
+	"sonos-playlist/internal/output"
+	"sonos-playlist/internal/playlist"
+	"sonos-playlist/internal/sonos"
+)
+
+// Server is the daemon process. It monitors the rooms it is handed until
+// asked to stop.
+type Server struct {
+	Paths Paths
+	Out   *output.Output
+	// Connect reaches a room's speakers; it defaults to ConnectBackend.
+	Connect func(ctx context.Context, req WatchRequest, out *output.Output) (playlist.Backend, error)
+	// MatchRules apply to rooms whose request carries none; nil uses the
+	// built-in rules.
+	MatchRules *sonos.MatchRules
+
+	mu      sync.Mutex
+	rooms   map[string]*roomWatch
+	started time.Time
+	stop    context.CancelFunc
+}
+
+type roomWatch struct {
+	backend config.Backend
+	watcher *playlist.Watcher
+	handle  sonos.MonitorHandle
+	since   time.Time
+}
+
+// Run serves the control socket until ctx is done or a stop request
+// arrives, then stops every monitor and removes the PID file and socket.
+func (s *Server) Run(ctx context.Context) error {
+	if _, err := NewClient(s.Paths).Status(ctx); err == nil {
+		return errors.New("daemon is already running")
+	}
+	// Whatever is left over belongs to a daemon that didn't shut down cleanly.
+	_ = os.Remove(s.Paths.Socket)
+	ln, err := net.Listen("unix", s.Paths.Socket)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(s.Paths.Socket) }()
+	_ = os.Chmod(s.Paths.Socket, 0o600)
+	if err := os.WriteFile(s.Paths.PIDFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644); err != nil {
+		_ = ln.Close()
+		return err
+	}
+	defer func() { _ = os.Remove(s.Paths.PIDFile) }()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.mu.Lock()
+	s.rooms = map[string]*roomWatch{}
+	s.started = time.Now()
+	s.stop = cancel
+	s.mu.Unlock()
+
+	srv := &http.Server{Handler: s.handler(ctx), ReadHeaderTimeout: 5 * time.Second}
+	go func() { _ = srv.Serve(ln) }()
+	s.Out.Info(fmt.Sprintf("sonos daemon started (pid %d)", os.Getpid()))
+
+	<-ctx.Done()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelShutdown()
+	_ = srv.Shutdown(shutdownCtx)
+
+	// Room monitors run on ctx, so they are already stopping.
+	s.mu.Lock()
+	rooms := make([]*roomWatch, 0, len(s.rooms))
+	for _, w := range s.rooms {
+		rooms = append(rooms, w)
+	}
+	s.mu.Unlock()
+	for _, w := range rooms {
+		<-w.handle.Done
+	}
+	s.Out.Info("sonos daemon stopped")
+	return nil
+}
+
+func (s *Server) handler(ctx context.Context) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.status())
+	})
+	mux.HandleFunc("POST /watch", func(w http.ResponseWriter, r *http.Request) {
+		var req WatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid watch request: "+err.Error())
+			return
+		}
+		if err := s.watch(ctx, req); err != nil {
+			writeError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+	})
+	mux.HandleFunc("POST /stop", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+		s.stop()
+	})
+	return mux
+}
+
+func (s *Server) status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := Status{PID: os.Getpid(), StartedAt: s.started.UTC().Format(time.RFC3339), Rooms: []RoomStatus{}}
+	for room, w := range s.rooms {
+		st.Rooms = append(st.Rooms, RoomStatus{
+			Room:    room,
+			Backend: w.backend,
+			Songs:   len(w.watcher.Songs()),
+			Since:   w.since.UTC().Format(time.RFC3339),
+		})
+	}
+	sort.Slice(st.Rooms, func(i, j int) bool { return st.Rooms[i].Room < st.Rooms[j].Room })
+	return st
+}
+
+// watch starts monitoring req.Room, or adds the songs to the room's monitor
+// if it is already watched. The monitor runs until the daemon stops.
+func (s *Server) watch(ctx context.Context, req WatchRequest) error {
+	room := strings.TrimSpace(req.Room)
+	if room == "" {
+		return errors.New("watch request has no room")
+	}
+	if s.addSongs(room, req.Songs) {
+		s.Out.Info(fmt.Sprintf("Watching %d more songs in %s", len(req.Songs), room))
+		return nil
+	}
+
+	rules := req.MatchRules
+	if rules == nil {
+		rules = s.MatchRules
+	}
+	if rules != nil {
+		ctx = sonos.WithMatchRules(ctx, *rules)
+	}
+	connect := s.Connect
+	if connect == nil {
+		connect = ConnectBackend
+	}
+	backend, err := connect(ctx, req, s.Out)
+	if err != nil {
+		return err
+	}
+	watcher := playlist.NewWatcher(backend, s.Out)
+	watcher.Add(req.Songs...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if w, ok := s.rooms[room]; ok {
+		// Another request for the room got there first.
+		w.watcher.Add(req.Songs...)
+		return nil
+	}
+	w := &roomWatch{backend: req.Backend, watcher: watcher, handle: watcher.Start(ctx), since: time.Now()}
+	s.rooms[room] = w
+	s.Out.Info(fmt.Sprintf("Monitoring %d songs in %s", len(req.Songs), room))
+	go func() {
+		<-w.handle.Done
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.rooms[room] == w {
+			delete(s.rooms, room)
+		}
+	}()
+	return nil
+}
+
+func (s *Server) addSongs(room string, songs []playlist.WatchedSong) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.rooms[room]
+	if ok {
+		w.watcher.Add(songs...)
+	}
+	return ok
+}
+
+// ConnectBackend reaches req.Room the way the CLI run that queued the songs
+// did.
+func ConnectBackend(ctx context.Context, req WatchRequest, out *output.Output) (playlist.Backend, error) {
+	speaker, err := nativesonos.CoordinatorClientForName(ctx, req.Room, 5*time.Second)
+	if req.Backend == config.BackendNative {
+		if err != nil {
+			return nil, fmt.Errorf("could not reach %s: %w", req.Room, err)
+		}
+		return playlist.NewNativeBackend(speaker, playlist.NativeResolver(ctx, speaker, req.Services, out)), nil
+	}
+	if err != nil {
+		out.Debug("Could not reach " + req.Room + " directly: " + err.Error())
+		speaker = nil
+	}
+	return playlist.HTTPBackend{Client: sonos.NewClient(req.SonosAPI), Room: req.Room, Speaker: speaker}, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]any{"error": msg})
+}
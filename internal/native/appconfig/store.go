@@ -11,9 +11,40 @@ import (
 )
 
 type Config struct {
-	DefaultRoom string `json:"defaultRoom,omitempty"`
-	Format      string `json:"format,omitempty"`
-	Timeout     string `json:"timeout,omitempty"`
+	DefaultRoom string       `json:"defaultRoom,omitempty"`
+	Format      string       `json:"format,omitempty"`
+	Timeout     string       `json:"timeout,omitempty"`
+	MaxVolume   VolumeLimits `json:"maxVolume,omitempty"`
+}
+
+// VolumeLimits maps room names to the highest volume (0-100) any command may
+// set in that room. Room names match case-insensitively.
+type VolumeLimits map[string]int
+
+// For returns the limit for room, if one is configured.
+func (l VolumeLimits) For(room string) (int, bool) {
+	room = strings.TrimSpace(room)
+	if v, ok := l[room]; ok {
+		return v, true
+	}
+	for name, v := range l {
+		if strings.EqualFold(name, room) {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// Clamp lowers volume to the room's limit.
+func (l VolumeLimits) Clamp(room string, volume int) int {
+	if limit, ok := l.For(room); ok && volume > limit {
+		return limit
+	}
+	return volume
+}
+
+func (c Config) isZero() bool {
+	return c.DefaultRoom == "" && c.Format == "" && c.Timeout == "" && len(c.MaxVolume) == 0
 }
 
 func (c Config) Normalize() Config {
@@ -22,6 +53,16 @@ func (c Config) Normalize() Config {
 		Format:      strings.ToLower(strings.TrimSpace(c.Format)),
 		Timeout:     strings.TrimSpace(c.Timeout),
 	}
+	for room, v := range c.MaxVolume {
+		room = strings.TrimSpace(room)
+		if room == "" || v < 0 || v > 100 {
+			continue
+		}
+		if out.MaxVolume == nil {
+			out.MaxVolume = VolumeLimits{}
+		}
+		out.MaxVolume[room] = v
+	}
 	if out.Format == "" {
 		out.Format = "plain"
 	}
@@ -90,9 +131,9 @@ func (s *FileStore) Load() (Config, error) {
 		return Config{}, fmt.Errorf("parse config: %w", err)
 	}
 	// Backward compatibility: older configs may store keys at top level.
-	if ff.Config == (Config{}) {
+	if ff.Config.isZero() {
 		var legacy Config
-		if err := json.Unmarshal(raw, &legacy); err == nil && legacy != (Config{}) {
+		if err := json.Unmarshal(raw, &legacy); err == nil && !legacy.isZero() {
 			return legacy.Normalize(), nil
 		}
 	}
@@ -50,3 +50,39 @@ func TestConfigNormalize_InvalidFormat(t *testing.T) {
 		t.Fatalf("expected plain fallback, got %q", got.Format)
 	}
 }
+
+func TestVolumeLimits(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{MaxVolume: VolumeLimits{" Kids Room ": 40, "Office": 101, "": 10}}.Normalize()
+	if len(cfg.MaxVolume) != 1 {
+		t.Fatalf("expected only the valid limit to survive, got %v", cfg.MaxVolume)
+	}
+	if v, ok := cfg.MaxVolume.For("kids room"); !ok || v != 40 {
+		t.Fatalf("For(kids room) = %d, %v", v, ok)
+	}
+	if got := cfg.MaxVolume.Clamp("Kids Room", 80); got != 40 {
+		t.Fatalf("Clamp above limit = %d", got)
+	}
+	if got := cfg.MaxVolume.Clamp("Kids Room", 25); got != 25 {
+		t.Fatalf("Clamp below limit = %d", got)
+	}
+	if got := cfg.MaxVolume.Clamp("Office", 80); got != 80 {
+		t.Fatalf("Clamp without limit = %d", got)
+	}
+
+	s, err := NewFileStore(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	if err := s.Save(Config{MaxVolume: VolumeLimits{"Kids Room": 40}}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if v, ok := got.MaxVolume.For("Kids Room"); !ok || v != 40 {
+		t.Fatalf("loaded limit = %d, %v", v, ok)
+	}
+}
@@ -0,0 +1,456 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sonos-playlist/internal/native/sonos"
+)
+
+type alarmClient interface {
+	ListAlarms(ctx context.Context) ([]sonos.Alarm, error)
+	CreateAlarm(ctx context.Context, a sonos.Alarm) (int, error)
+	UpdateAlarm(ctx context.Context, a sonos.Alarm) error
+	DestroyAlarm(ctx context.Context, id int) error
+	GetTopology(ctx context.Context) (sonos.Topology, error)
+	ListFavorites(ctx context.Context, start, count int) (sonos.FavoritesPage, error)
+}
+
+// newAlarmClient returns a client for any speaker: alarms are shared by the
+// whole household, so there is no need to find a coordinator.
+var newAlarmClient = func(ctx context.Context, flags *rootFlags) (alarmClient, error) {
+	if flags.IP != "" {
+		return newSonosClient(flags.IP, flags.Timeout), nil
+	}
+	devs, err := sonosDiscover(ctx, sonos.DiscoverOptions{Timeout: flags.Timeout})
+	if err != nil {
+		return nil, err
+	}
+	if len(devs) == 0 {
+		return nil, errors.New("no speakers found")
+	}
+	return newSonosClient(devs[0].IP, flags.Timeout), nil
+}
+
+type alarmOutput struct {
+	sonos.Alarm
+	Room    string `json:"room,omitempty"`
+	Program string `json:"program"`
+}
+
+func newAlarmCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alarm",
+		Short: "Manage Sonos alarms",
+		Long:  "Lists and edits the household's alarms through the AlarmClock service. Times are the speakers' local time; recurrence is ONCE, DAILY, WEEKDAYS, WEEKENDS, or ON_<days> with 0 = Sunday (e.g. ON_12345).",
+		Example: "  sonos alarm list\n" +
+			"  sonos alarm create --room \"Kids Room\" --start 7:00 --recurrence WEEKDAYS --volume 15 --favorite \"Morning Radio\"\n" +
+			"  sonos alarm update 12 --start 6:45 --duration 30m\n" +
+			"  sonos alarm disable 12 14",
+	}
+	cmd.AddCommand(newAlarmListCmd(flags))
+	cmd.AddCommand(newAlarmCreateCmd(flags))
+	cmd.AddCommand(newAlarmUpdateCmd(flags))
+	cmd.AddCommand(newAlarmDeleteCmd(flags))
+	cmd.AddCommand(newAlarmEnableCmd(flags, true))
+	cmd.AddCommand(newAlarmEnableCmd(flags, false))
+	return cmd
+}
+
+func newAlarmListCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "list",
+		Short:        "List alarms",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			c, err := newAlarmClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			alarms, err := c.ListAlarms(ctx)
+			if err != nil {
+				return err
+			}
+			// Room names are cosmetic; fall back to UUIDs if topology fails.
+			top, _ := c.GetTopology(ctx)
+			out := make([]alarmOutput, 0, len(alarms))
+			for _, a := range alarms {
+				out = append(out, newAlarmOutput(a, top))
+			}
+			if isJSON(flags) {
+				return writeJSON(cmd, out)
+			}
+			if isTSV(flags) {
+				for _, a := range out {
+					_, _ = fmt.Fprintln(cmd.OutOrStdout(), alarmRow(a))
+				}
+				return nil
+			}
+			if len(out) == 0 {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No alarms")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "ID\tSTART\tDURATION\tRECURRENCE\tROOM\tVOLUME\tLINKED\tENABLED\tPROGRAM")
+			for _, a := range out {
+				_, _ = fmt.Fprintln(w, alarmRow(a))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newAlarmCreateCmd(flags *rootFlags) *cobra.Command {
+	var af alarmFlags
+	cmd := &cobra.Command{
+		Use:          "create",
+		Short:        "Create an alarm (room defaults to --name)",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			c, err := newAlarmClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			top, err := c.GetTopology(ctx)
+			if err != nil {
+				return err
+			}
+			a := sonos.Alarm{
+				Enabled:    true,
+				ProgramURI: sonos.AlarmChimeURI,
+				PlayMode:   "NORMAL",
+			}
+			if af.room == "" {
+				af.room = flags.Name
+			}
+			if af.room == "" && flags.IP != "" {
+				m, err := resolveMember(top, "", flags.IP)
+				if err != nil {
+					return err
+				}
+				af.room = m.Name
+			}
+			if af.room == "" {
+				return errors.New("provide --room or --name")
+			}
+			if err := af.apply(ctx, cmd, c, top, &a, true); err != nil {
+				return err
+			}
+			capAlarmVolume(flags, top, &a)
+			id, err := c.CreateAlarm(ctx, a)
+			if err != nil {
+				return err
+			}
+			a.ID = id
+			writePlainLine(cmd, flags, fmt.Sprintf("Created alarm %d", id))
+			return writeOK(cmd, flags, "alarm.create", map[string]any{"alarm": newAlarmOutput(a, top)})
+		},
+	}
+	af.register(cmd, true)
+	_ = cmd.MarkFlagRequired("start")
+	return cmd
+}
+
+func newAlarmUpdateCmd(flags *rootFlags) *cobra.Command {
+	var af alarmFlags
+	cmd := &cobra.Command{
+		Use:          "update <id>",
+		Short:        "Change an alarm (settings not given are kept)",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseAlarmID(args[0])
+			if err != nil {
+				return err
+			}
+			if !af.changed(cmd) {
+				return errors.New("nothing to update (see --help for settings)")
+			}
+			ctx := cmd.Context()
+			c, err := newAlarmClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			alarms, err := c.ListAlarms(ctx)
+			if err != nil {
+				return err
+			}
+			a, err := findAlarm(alarms, id)
+			if err != nil {
+				return err
+			}
+			top, err := c.GetTopology(ctx)
+			if err != nil {
+				return err
+			}
+			if err := af.apply(ctx, cmd, c, top, &a, false); err != nil {
+				return err
+			}
+			capAlarmVolume(flags, top, &a)
+			if err := c.UpdateAlarm(ctx, a); err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, fmt.Sprintf("Updated alarm %d", id))
+			return writeOK(cmd, flags, "alarm.update", map[string]any{"alarm": newAlarmOutput(a, top)})
+		},
+	}
+	af.register(cmd, false)
+	return cmd
+}
+
+func newAlarmDeleteCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "delete <id>...",
+		Aliases:      []string{"rm"},
+		Short:        "Delete alarms",
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseAlarmIDs(args)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newAlarmClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				if err := c.DestroyAlarm(ctx, id); err != nil {
+					return fmt.Errorf("delete alarm %d: %w", id, err)
+				}
+				writePlainLine(cmd, flags, fmt.Sprintf("Deleted alarm %d", id))
+			}
+			return writeOK(cmd, flags, "alarm.delete", map[string]any{"ids": ids})
+		},
+	}
+}
+
+func newAlarmEnableCmd(flags *rootFlags, enable bool) *cobra.Command {
+	use, short, verb := "enable", "Enable alarms", "Enabled"
+	if !enable {
+		use, short, verb = "disable", "Disable alarms", "Disabled"
+	}
+	return &cobra.Command{
+		Use:          use + " <id>...",
+		Short:        short,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseAlarmIDs(args)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := newAlarmClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			alarms, err := c.ListAlarms(ctx)
+			if err != nil {
+				return err
+			}
+			top, err := c.GetTopology(ctx)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				a, err := findAlarm(alarms, id)
+				if err != nil {
+					return err
+				}
+				// Alarms set before a maxVolume was configured are capped as
+				// they are switched.
+				updated := a
+				updated.Enabled = enable
+				capAlarmVolume(flags, top, &updated)
+				if updated != a {
+					if err := c.UpdateAlarm(ctx, updated); err != nil {
+						return fmt.Errorf("%s alarm %d: %w", use, id, err)
+					}
+				}
+				writePlainLine(cmd, flags, fmt.Sprintf("%s alarm %d", verb, id))
+			}
+			return writeOK(cmd, flags, "alarm."+use, map[string]any{"ids": ids})
+		},
+	}
+}
+
+// alarmFlags holds the settings shared by alarm create and update.
+type alarmFlags struct {
+	start      string
+	duration   string
+	recurrence string
+	room       string
+	volume     int
+	linked     bool
+	favorite   string
+	uri        string
+}
+
+func (f *alarmFlags) register(cmd *cobra.Command, create bool) {
+	duration, recurrence, volume := "", "", 0
+	if create {
+		duration, recurrence, volume = "1h", "DAILY", 20
+	}
+	cmd.Flags().StringVar(&f.start, "start", "", "Start time, HH:MM[:SS] in local time")
+	cmd.Flags().StringVar(&f.duration, "duration", duration, "How long to play, e.g. 45m or 01:00:00")
+	cmd.Flags().StringVar(&f.recurrence, "recurrence", recurrence, "ONCE|DAILY|WEEKDAYS|WEEKENDS|ON_<days 0-6>")
+	cmd.Flags().StringVar(&f.room, "room", "", "Room the alarm plays in")
+	cmd.Flags().IntVar(&f.volume, "volume", volume, "Alarm volume (0-100)")
+	cmd.Flags().BoolVar(&f.linked, "include-linked-zones", false, "Also play in rooms grouped with the alarm's room")
+	cmd.Flags().StringVar(&f.favorite, "favorite", "", "Sonos Favorite to play (by title)")
+	cmd.Flags().StringVar(&f.uri, "uri", "", "URI to play (default: the Sonos chime)")
+}
+
+var alarmFlagNames = []string{"start", "duration", "recurrence", "room", "volume", "include-linked-zones", "favorite", "uri"}
+
+func (f *alarmFlags) changed(cmd *cobra.Command) bool {
+	for _, name := range alarmFlagNames {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply copies the flags onto a. On update (all == false) only flags given
+// on the command line are applied.
+func (f *alarmFlags) apply(ctx context.Context, cmd *cobra.Command, c alarmClient, top sonos.Topology, a *sonos.Alarm, all bool) error {
+	set := func(name string) bool { return all || cmd.Flags().Changed(name) }
+	var err error
+	if set("start") {
+		if a.StartTime, err = sonos.ParseAlarmTime(f.start); err != nil {
+			return err
+		}
+	}
+	if set("duration") {
+		if a.Duration, err = parseAlarmDuration(f.duration); err != nil {
+			return err
+		}
+	}
+	if set("recurrence") {
+		if a.Recurrence, err = sonos.ParseRecurrence(f.recurrence); err != nil {
+			return err
+		}
+	}
+	if set("volume") {
+		if f.volume < 0 || f.volume > 100 {
+			return fmt.Errorf("volume must be 0-100: %d", f.volume)
+		}
+		a.Volume = f.volume
+	}
+	if set("include-linked-zones") {
+		a.IncludeLinkedZones = f.linked
+	}
+	if f.room != "" {
+		m, err := resolveMember(top, f.room, "")
+		if err != nil {
+			return err
+		}
+		a.RoomUUID = m.UUID
+	}
+	switch {
+	case f.favorite != "" && f.uri != "":
+		return errors.New("use --favorite or --uri, not both")
+	case f.favorite != "":
+		fav, err := findFavorite(ctx, c, f.favorite)
+		if err != nil {
+			return err
+		}
+		a.ProgramURI = sonos.FavoriteURI(fav.Item)
+		if a.ProgramURI == "" {
+			return errors.New("favorite has no URI: " + f.favorite)
+		}
+		a.ProgramMetaData = fav.Item.ResMD
+	case f.uri != "":
+		a.ProgramURI = f.uri
+		a.ProgramMetaData = ""
+	}
+	return nil
+}
+
+// capAlarmVolume holds the alarm to its room's maxVolume.
+func capAlarmVolume(flags *rootFlags, top sonos.Topology, a *sonos.Alarm) {
+	if m, ok := top.FindByUUID(a.RoomUUID); ok {
+		a.Volume = flags.MaxVolume.Clamp(m.Name, a.Volume)
+	}
+}
+
+// parseAlarmDuration accepts a Go duration (45m) or HH:MM[:SS].
+func parseAlarmDuration(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, ":") {
+		v, err := sonos.ParseAlarmTime(s)
+		if err != nil || v == "00:00:00" {
+			return "", fmt.Errorf("invalid alarm duration %q (e.g. 45m, 1h, 01:00:00)", s)
+		}
+		return v, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid alarm duration %q (e.g. 45m, 1h, 01:00:00)", s)
+	}
+	return sonos.FormatAlarmDuration(d)
+}
+
+func parseAlarmID(s string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid alarm id %q", s)
+	}
+	return id, nil
+}
+
+func parseAlarmIDs(args []string) ([]int, error) {
+	ids := make([]int, 0, len(args))
+	for _, arg := range args {
+		id, err := parseAlarmID(arg)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func findAlarm(alarms []sonos.Alarm, id int) (sonos.Alarm, error) {
+	for _, a := range alarms {
+		if a.ID == id {
+			return a, nil
+		}
+	}
+	return sonos.Alarm{}, fmt.Errorf("alarm not found: %d", id)
+}
+
+func newAlarmOutput(a sonos.Alarm, top sonos.Topology) alarmOutput {
+	out := alarmOutput{Alarm: a, Program: a.ProgramURI}
+	if m, ok := top.FindByUUID(a.RoomUUID); ok {
+		out.Room = m.Name
+	}
+	if a.ProgramURI == sonos.AlarmChimeURI {
+		out.Program = "Chime"
+	} else if items, err := sonos.ParseDIDLItems(a.ProgramMetaData); err == nil && len(items) > 0 && items[0].Title != "" {
+		out.Program = items[0].Title
+	}
+	return out
+}
+
+func alarmRow(a alarmOutput) string {
+	room := a.Room
+	if room == "" {
+		room = a.RoomUUID
+	}
+	return fmt.Sprintf("%d\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s",
+		a.ID, a.StartTime, a.Duration, a.Recurrence, room, a.Volume, onOff(a.IncludeLinkedZones), onOff(a.Enabled), a.Program)
+}
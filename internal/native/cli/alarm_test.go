@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"sonos-playlist/internal/native/appconfig"
+	"sonos-playlist/internal/native/sonos"
+)
+
+type fakeAlarmClient struct {
+	alarms    []sonos.Alarm
+	favorites []sonos.FavoriteItem
+	top       sonos.Topology
+	updates   int
+}
+
+func (f *fakeAlarmClient) ListAlarms(ctx context.Context) ([]sonos.Alarm, error) {
+	return append([]sonos.Alarm(nil), f.alarms...), nil
+}
+
+func (f *fakeAlarmClient) CreateAlarm(ctx context.Context, a sonos.Alarm) (int, error) {
+	a.ID = len(f.alarms) + 1
+	f.alarms = append(f.alarms, a)
+	return a.ID, nil
+}
+
+func (f *fakeAlarmClient) UpdateAlarm(ctx context.Context, a sonos.Alarm) error {
+	f.updates++
+	for i := range f.alarms {
+		if f.alarms[i].ID == a.ID {
+			f.alarms[i] = a
+		}
+	}
+	return nil
+}
+
+func (f *fakeAlarmClient) DestroyAlarm(ctx context.Context, id int) error {
+	for i := range f.alarms {
+		if f.alarms[i].ID == id {
+			f.alarms = append(f.alarms[:i], f.alarms[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (f *fakeAlarmClient) GetTopology(ctx context.Context) (sonos.Topology, error) {
+	return f.top, nil
+}
+
+func (f *fakeAlarmClient) ListFavorites(ctx context.Context, start, count int) (sonos.FavoritesPage, error) {
+	return sonos.FavoritesPage{Items: f.favorites, NumberReturned: len(f.favorites), TotalMatches: len(f.favorites)}, nil
+}
+
+func TestAlarmCreateUpdateDisableList(t *testing.T) {
+	kids := sonos.Member{Name: "Kids Room", IP: "192.0.2.10", UUID: "RINCON_KIDS1400"}
+	bed := sonos.Member{Name: "Bedroom", IP: "192.0.2.11", UUID: "RINCON_BED1400"}
+	fake := &fakeAlarmClient{
+		top: sonos.Topology{
+			Groups: []sonos.Group{{ID: "G1", Coordinator: kids, Members: []sonos.Member{kids, bed}}},
+			ByName: map[string]sonos.Member{kids.Name: kids, bed.Name: bed},
+		},
+		favorites: []sonos.FavoriteItem{{Position: 1, Item: sonos.DIDLItem{
+			Title: "Morning Radio",
+			URI:   "x-sonosapi-stream:s1234?sid=254",
+			ResMD: `<DIDL-Lite xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/" xmlns:dc="http://purl.org/dc/elements/1.1/"><item id="F1" parentID="F" restricted="true"><dc:title>Morning Radio</dc:title></item></DIDL-Lite>`,
+		}}},
+	}
+
+	orig := newAlarmClient
+	t.Cleanup(func() { newAlarmClient = orig })
+	newAlarmClient = func(ctx context.Context, flags *rootFlags) (alarmClient, error) {
+		return fake, nil
+	}
+
+	flags := &rootFlags{Name: "Kids Room", Timeout: 2 * time.Second}
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		cmd := newAlarmCmd(flags)
+		var out captureWriter
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SilenceErrors = true
+		cmd.SetArgs(args)
+		err := cmd.ExecuteContext(context.Background())
+		return out.String(), err
+	}
+
+	if _, err := run("create", "--start", "7:00", "--recurrence", "on_531", "--volume", "15", "--favorite", "morning radio"); err != nil {
+		t.Fatalf("alarm create: %v", err)
+	}
+	want := sonos.Alarm{
+		ID: 1, StartTime: "07:00:00", Duration: "01:00:00", Recurrence: "ON_135", Enabled: true,
+		RoomUUID: kids.UUID, ProgramURI: "x-sonosapi-stream:s1234?sid=254", ProgramMetaData: fake.favorites[0].Item.ResMD,
+		PlayMode: "NORMAL", Volume: 15,
+	}
+	if fake.alarms[0] != want {
+		t.Fatalf("created alarm:\n%+v\nwant:\n%+v", fake.alarms[0], want)
+	}
+
+	if _, err := run("update", "1"); err == nil {
+		t.Fatal("expected error for update without settings")
+	}
+	if _, err := run("update", "1", "--room", "bedroom", "--duration", "30m", "--include-linked-zones"); err != nil {
+		t.Fatalf("alarm update: %v", err)
+	}
+	got := fake.alarms[0]
+	if got.RoomUUID != bed.UUID || got.Duration != "00:30:00" || !got.IncludeLinkedZones || got.StartTime != "07:00:00" || got.Volume != 15 {
+		t.Fatalf("updated alarm: %+v", got)
+	}
+
+	if _, err := run("disable", "1"); err != nil {
+		t.Fatalf("alarm disable: %v", err)
+	}
+	if _, err := run("disable", "1"); err != nil {
+		t.Fatalf("alarm disable again: %v", err)
+	}
+	if fake.alarms[0].Enabled || fake.updates != 2 {
+		t.Fatalf("disable: enabled=%v updates=%d", fake.alarms[0].Enabled, fake.updates)
+	}
+	if _, err := run("enable", "7"); err == nil || !strings.Contains(err.Error(), "alarm not found: 7") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	// Enabling applies a maxVolume configured after the alarm was set.
+	flags.MaxVolume = appconfig.VolumeLimits{"bedroom": 10}
+	if _, err := run("enable", "1"); err != nil {
+		t.Fatalf("alarm enable: %v", err)
+	}
+	if !fake.alarms[0].Enabled || fake.alarms[0].Volume != 10 {
+		t.Fatalf("enable: %+v", fake.alarms[0])
+	}
+	flags.MaxVolume = nil
+
+	flags.Format = formatTSV
+	out, err := run("list")
+	if err != nil {
+		t.Fatalf("alarm list: %v", err)
+	}
+	if wantRow := "1\t07:00:00\t00:30:00\tON_135\tBedroom\t10\ton\ton\tMorning Radio\n"; out != wantRow {
+		t.Fatalf("list output:\n%q\nwant:\n%q", out, wantRow)
+	}
+
+	if _, err := run("delete", "1"); err != nil {
+		t.Fatalf("alarm delete: %v", err)
+	}
+	if len(fake.alarms) != 0 {
+		t.Fatalf("alarms after delete: %+v", fake.alarms)
+	}
+}
@@ -0,0 +1,249 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sonos-playlist/internal/config"
+	"sonos-playlist/internal/storage"
+)
+
+var (
+	listBlocklist   = storage.ListBlocklist
+	getBlockedTrack = storage.GetBlockedTrack
+	unblockTrack    = storage.UnblockTrack
+	clearBlocklist  = storage.ClearBlocklist
+	importBlocklist = storage.ImportBlocklist
+	// configureBlocklist applies blocklistTtl from config.json so expired
+	// blocks are hidden.
+	configureBlocklist = func() { storage.ConfigureBlocklist(config.Load().BlocklistTTL) }
+)
+
+// blockedTrackView adds when the block lapses.
+type blockedTrackView struct {
+	storage.BlockedTrack
+	ExpiresAt string `json:"expiresAt,omitempty"`
+}
+
+func viewBlockedTrack(b storage.BlockedTrack) blockedTrackView {
+	v := blockedTrackView{BlockedTrack: b}
+	if exp := b.ExpiresAt(); !exp.IsZero() {
+		v.ExpiresAt = exp.UTC().Format(time.RFC3339)
+	}
+	return v
+}
+
+func newBlocklistCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "blocklist",
+		Short: "Show or undo blocks on tracks that failed to play",
+		Long: "Tracks the speakers fail to play are blocked so playlists skip them. Blocks expire after " +
+			"blocklistTtl from config.json (or SONOS_BLOCKLIST_TTL, default 720h; 0 keeps them), since " +
+			"availability changes.",
+		Example:      "  sonos blocklist\n  sonos blocklist show 1440857781\n  sonos blocklist unblock 1440857781\n  sonos blocklist export > blocks.json\n  sonos blocklist import blocks.json",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBlocklistList(cmd, flags)
+		},
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:          "list",
+		Short:        "List blocked tracks (most recent first)",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBlocklistList(cmd, flags)
+		},
+	})
+	cmd.AddCommand(newBlocklistShowCmd(flags))
+	cmd.AddCommand(newBlocklistUnblockCmd(flags))
+	cmd.AddCommand(newBlocklistClearCmd(flags))
+	cmd.AddCommand(newBlocklistExportCmd(flags))
+	cmd.AddCommand(newBlocklistImportCmd(flags))
+	return cmd
+}
+
+func runBlocklistList(cmd *cobra.Command, flags *rootFlags) error {
+	configureBlocklist()
+	entries := listBlocklist()
+	views := make([]blockedTrackView, 0, len(entries))
+	for _, b := range entries {
+		views = append(views, viewBlockedTrack(b))
+	}
+	if isJSON(flags) {
+		return writeJSON(cmd, views)
+	}
+	if isTSV(flags) {
+		for _, v := range views {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%s\t%s\t%s\n", v.TrackID, v.Artist, v.Title, v.FailedAt, v.ExpiresAt, v.ReplacementTrackID)
+		}
+		return nil
+	}
+	if len(views) == 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No blocked tracks.")
+		return nil
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "TRACK\tSONG\tBLOCKED\tEXPIRES\tREPLACEMENT\n")
+	for _, v := range views {
+		_, _ = fmt.Fprintf(w, "%s\t%s - %s\t%s\t%s\t%s\n", v.TrackID, v.Artist, v.Title, localTime(v.FailedAt), blockExpiry(v), v.ReplacementTrackID)
+	}
+	return w.Flush()
+}
+
+func blockExpiry(v blockedTrackView) string {
+	if v.ExpiresAt == "" {
+		return "never"
+	}
+	return localTime(v.ExpiresAt)
+}
+
+func newBlocklistShowCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "show <trackId>",
+		Short:        "Show one blocked track",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configureBlocklist()
+			b, ok := getBlockedTrack(args[0])
+			if !ok {
+				return fmt.Errorf("track %s is not blocked", strings.TrimSpace(args[0]))
+			}
+			v := viewBlockedTrack(b)
+			if isJSON(flags) {
+				return writeJSON(cmd, v)
+			}
+			if isTSV(flags) {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%s\t%s\t%s\n", v.TrackID, v.Artist, v.Title, v.FailedAt, v.ExpiresAt, v.ReplacementTrackID)
+				return nil
+			}
+			w := cmd.OutOrStdout()
+			_, _ = fmt.Fprintf(w, "track: %s\n", v.TrackID)
+			_, _ = fmt.Fprintf(w, "song: %s - %s\n", v.Artist, v.Title)
+			if v.Album != "" {
+				_, _ = fmt.Fprintf(w, "album: %s\n", v.Album)
+			}
+			_, _ = fmt.Fprintf(w, "blocked: %s\n", localTime(v.FailedAt))
+			_, _ = fmt.Fprintf(w, "expires: %s\n", blockExpiry(v))
+			if v.ReplacementTrackID != "" {
+				_, _ = fmt.Fprintf(w, "replacement: %s\n", v.ReplacementTrackID)
+			}
+			return nil
+		},
+	}
+}
+
+func newBlocklistUnblockCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "unblock <trackId>...",
+		Short:        "Remove blocks so the tracks can be queued again",
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configureBlocklist()
+			removed := []string{}
+			for _, id := range args {
+				ok, err := unblockTrack(id)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf("track %s is not blocked", strings.TrimSpace(id))
+				}
+				removed = append(removed, strings.TrimSpace(id))
+				writePlainLine(cmd, flags, "Unblocked "+strings.TrimSpace(id))
+			}
+			return writeOK(cmd, flags, "blocklist.unblock", map[string]any{"trackIds": removed})
+		},
+	}
+}
+
+func newBlocklistClearCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "clear",
+		Short:        "Remove every block",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configureBlocklist()
+			n, err := clearBlocklist()
+			if err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, fmt.Sprintf("Removed %d blocks", n))
+			return writeOK(cmd, flags, "blocklist.clear", map[string]any{"entries": n})
+		},
+	}
+}
+
+func newBlocklistExportCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "export [file]",
+		Short:        "Write the active blocks as JSON (to stdout by default)",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configureBlocklist()
+			buf, err := json.MarshalIndent(listBlocklist(), "", "  ")
+			if err != nil {
+				return err
+			}
+			buf = append(buf, '\n')
+			if len(args) == 0 || args[0] == "-" {
+				_, err := cmd.OutOrStdout().Write(buf)
+				return err
+			}
+			if err := os.WriteFile(args[0], buf, 0o644); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "blocklist.export", map[string]any{"path": args[0]})
+		},
+	}
+}
+
+func newBlocklistImportCmd(flags *rootFlags) *cobra.Command {
+	var replace bool
+	cmd := &cobra.Command{
+		Use:          "import <file|->",
+		Short:        "Add blocks from an export (or a blocklist.json)",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configureBlocklist()
+			var data []byte
+			var err error
+			if args[0] == "-" {
+				data, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				data, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return err
+			}
+			entries, err := storage.ParseBlocklist(data)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 && !replace {
+				return errors.New("no blocks to import")
+			}
+			n, err := importBlocklist(entries, replace)
+			if err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, fmt.Sprintf("Imported %d blocks", n))
+			return writeOK(cmd, flags, "blocklist.import", map[string]any{"entries": n, "replace": replace})
+		},
+	}
+	cmd.Flags().BoolVar(&replace, "replace", false, "Drop existing blocks first")
+	return cmd
+}
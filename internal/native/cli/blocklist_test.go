@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sonos-playlist/internal/storage"
+)
+
+func TestBlocklistListAndUnblock(t *testing.T) {
+	flags := &rootFlags{Timeout: 2 * time.Second, Format: formatTSV}
+
+	origList, origUnblock, origConfigure := listBlocklist, unblockTrack, configureBlocklist
+	t.Cleanup(func() { listBlocklist, unblockTrack, configureBlocklist = origList, origUnblock, origConfigure })
+	configureBlocklist = func() { storage.ConfigureBlocklist(0) }
+	t.Cleanup(func() { storage.ConfigureBlocklist(storage.DefaultBlocklistTTL) })
+	listBlocklist = func() []storage.BlockedTrack {
+		return []storage.BlockedTrack{{TrackID: "1440857781", Artist: "Sade", Title: "Smooth Operator", FailedAt: "2026-10-01T08:00:00Z", ReplacementTrackID: "42"}}
+	}
+	unblocked := []string{}
+	unblockTrack = func(id string) (bool, error) {
+		unblocked = append(unblocked, id)
+		return id == "1440857781", nil
+	}
+
+	cmd := newBlocklistCmd(flags)
+	var out captureWriter
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{"list"})
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("blocklist list: %v", err)
+	}
+	want := "1440857781\tSade\tSmooth Operator\t2026-10-01T08:00:00Z\t\t42\n"
+	if out.String() != want {
+		t.Fatalf("output:\n%q\nwant:\n%q", out.String(), want)
+	}
+
+	flags.Format = formatPlain
+	cmd = newBlocklistCmd(flags)
+	out = captureWriter{}
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{"unblock", "1440857781", "7"})
+	if err := cmd.ExecuteContext(context.Background()); err == nil {
+		t.Fatal("unblocking a track that isn't blocked succeeded")
+	}
+	if out.String() != "Unblocked 1440857781\n" || len(unblocked) != 2 {
+		t.Fatalf("unblock output %q (calls %v)", out.String(), unblocked)
+	}
+}
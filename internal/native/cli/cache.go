@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sonos-playlist/internal/storage"
+)
+
+var (
+	searchCacheStats = storage.GetSearchCacheStats
+	clearSearchCache = storage.ClearSearchCache
+)
+
+func newCacheCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect or clear the song search cache",
+		Long: "Song searches made while building playlists are cached on disk so similar playlists resolve quickly. " +
+			"Set searchCacheTtl and searchCacheMaxEntries in config.json (or SONOS_SEARCH_CACHE_TTL and " +
+			"SONOS_SEARCH_CACHE_MAX_ENTRIES) to tune it.",
+		Example: "  sonos cache stats\n  sonos cache clear",
+	}
+	cmd.AddCommand(newCacheStatsCmd(flags))
+	cmd.AddCommand(newCacheClearCmd(flags))
+	return cmd
+}
+
+func newCacheStatsCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "stats",
+		Short:        "Show search cache size and age",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stats := searchCacheStats()
+			if isJSON(flags) {
+				return writeJSON(cmd, stats)
+			}
+			if isTSV(flags) {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%d\t%d\t%d\t%s\t%s\t%s\n", stats.Entries, stats.Expired, stats.Bytes, stats.Oldest, stats.Newest, stats.Path)
+				return nil
+			}
+			w := cmd.OutOrStdout()
+			_, _ = fmt.Fprintf(w, "path: %s\n", stats.Path)
+			_, _ = fmt.Fprintf(w, "entries: %d (%d expired)\n", stats.Entries, stats.Expired)
+			_, _ = fmt.Fprintf(w, "size: %d bytes\n", stats.Bytes)
+			if stats.Entries > 0 {
+				_, _ = fmt.Fprintf(w, "oldest: %s\n", localTime(stats.Oldest))
+				_, _ = fmt.Fprintf(w, "newest: %s\n", localTime(stats.Newest))
+			}
+			return nil
+		},
+	}
+}
+
+func newCacheClearCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "clear",
+		Short:        "Delete every cached search",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := clearSearchCache()
+			if err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, fmt.Sprintf("Cleared %d cached searches", n))
+			return writeOK(cmd, flags, "cache.clear", map[string]any{"entries": n})
+		},
+	}
+}
+
+// localTime formats an RFC 3339 timestamp in local time, returning it
+// unchanged if it doesn't parse.
+func localTime(ts string) string {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return ts
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
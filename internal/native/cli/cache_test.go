@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sonos-playlist/internal/storage"
+)
+
+func TestCacheStatsAndClear(t *testing.T) {
+	flags := &rootFlags{Timeout: 2 * time.Second, Format: formatTSV}
+
+	origStats, origClear := searchCacheStats, clearSearchCache
+	t.Cleanup(func() { searchCacheStats, clearSearchCache = origStats, origClear })
+	searchCacheStats = func() storage.SearchCacheStats {
+		return storage.SearchCacheStats{Path: "/tmp/search-cache.json", Entries: 12, Expired: 2, Bytes: 4096, Oldest: "2026-10-01T08:00:00Z", Newest: "2026-10-02T08:00:00Z"}
+	}
+	cleared := false
+	clearSearchCache = func() (int, error) {
+		cleared = true
+		return 12, nil
+	}
+
+	cmd := newCacheCmd(flags)
+	var out captureWriter
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{"stats"})
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("cache stats: %v", err)
+	}
+	want := "12\t2\t4096\t2026-10-01T08:00:00Z\t2026-10-02T08:00:00Z\t/tmp/search-cache.json\n"
+	if out.String() != want {
+		t.Fatalf("output:\n%q\nwant:\n%q", out.String(), want)
+	}
+
+	flags.Format = formatPlain
+	cmd = newCacheCmd(flags)
+	out = captureWriter{}
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"clear"})
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("cache clear: %v", err)
+	}
+	if !cleared || out.String() != "Cleared 12 cached searches\n" {
+		t.Fatalf("clear output %q (cleared=%v)", out.String(), cleared)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,7 +18,7 @@ func newConfigCmd(flags *rootFlags) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "config",
 		Short: "Manage local CLI defaults",
-		Long:  "Stores small, local defaults under your user config directory (e.g. ~/.config/sonos-playlist/config.json).",
+		Long:  "Stores small, local defaults under your user config directory (e.g. ~/.config/sonos-playlist/config.json). Keys: defaultRoom, format, timeout, and maxVolume.<room> (a 0-100 cap every volume command enforces for that room).",
 	}
 	cmd.AddCommand(newConfigGetCmd(flags))
 	cmd.AddCommand(newConfigSetCmd(flags))
@@ -144,6 +145,9 @@ func printConfigPlain(cmd *cobra.Command, cfg appconfig.Config) {
 		"format":      cfg.Format,
 		"timeout":     cfg.Timeout,
 	}
+	for room, v := range cfg.MaxVolume {
+		entries[maxVolumeKeyPrefix+room] = strconv.Itoa(v)
+	}
 	keys := make([]string, 0, len(entries))
 	for k := range entries {
 		keys = append(keys, k)
@@ -154,7 +158,17 @@ func printConfigPlain(cmd *cobra.Command, cfg appconfig.Config) {
 	}
 }
 
+// maxVolumeKeyPrefix prefixes per-room volume limits, e.g. "maxVolume.Kids Room".
+const maxVolumeKeyPrefix = "maxVolume."
+
 func getConfigKey(cfg appconfig.Config, key string) (string, bool) {
+	if room, ok := strings.CutPrefix(key, maxVolumeKeyPrefix); ok {
+		v, ok := cfg.MaxVolume.For(room)
+		if !ok {
+			return "", true
+		}
+		return strconv.Itoa(v), true
+	}
 	switch key {
 	case "defaultRoom":
 		return cfg.DefaultRoom, true
@@ -168,6 +182,25 @@ func getConfigKey(cfg appconfig.Config, key string) (string, bool) {
 }
 
 func setConfigKey(cfg appconfig.Config, key, value string) (appconfig.Config, error) {
+	if room, ok := strings.CutPrefix(key, maxVolumeKeyPrefix); ok {
+		room = strings.TrimSpace(room)
+		if room == "" {
+			return appconfig.Config{}, errors.New("room is required: " + maxVolumeKeyPrefix + "<room>")
+		}
+		v, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || v < 0 || v > 100 {
+			return appconfig.Config{}, errors.New("invalid max volume (expected 0-100): " + value)
+		}
+		limits := appconfig.VolumeLimits{}
+		for name, limit := range cfg.MaxVolume {
+			if !strings.EqualFold(name, room) {
+				limits[name] = limit
+			}
+		}
+		limits[room] = v
+		cfg.MaxVolume = limits
+		return cfg, nil
+	}
 	switch key {
 	case "defaultRoom":
 		cfg.DefaultRoom = value
@@ -196,6 +229,16 @@ func setConfigKey(cfg appconfig.Config, key, value string) (appconfig.Config, er
 }
 
 func unsetConfigKey(cfg appconfig.Config, key string) (appconfig.Config, error) {
+	if room, ok := strings.CutPrefix(key, maxVolumeKeyPrefix); ok {
+		limits := appconfig.VolumeLimits{}
+		for name, limit := range cfg.MaxVolume {
+			if !strings.EqualFold(name, strings.TrimSpace(room)) {
+				limits[name] = limit
+			}
+		}
+		cfg.MaxVolume = limits
+		return cfg, nil
+	}
 	switch key {
 	case "defaultRoom":
 		cfg.DefaultRoom = ""
@@ -154,3 +154,30 @@ func TestGetConfigKey(t *testing.T) {
 		t.Fatalf("expected ok=false")
 	}
 }
+
+func TestMaxVolumeConfigKeys(t *testing.T) {
+	cfg, err := setConfigKey(appconfig.Config{}, "maxVolume.Kids Room", "40")
+	if err != nil {
+		t.Fatalf("set maxVolume: %v", err)
+	}
+	if cfg, err = setConfigKey(cfg, "maxVolume.kids room", "35"); err != nil {
+		t.Fatalf("set maxVolume again: %v", err)
+	}
+	if len(cfg.MaxVolume) != 1 {
+		t.Fatalf("expected one limit per room, got %v", cfg.MaxVolume)
+	}
+	if v, ok := getConfigKey(cfg, "maxVolume.Kids Room"); !ok || v != "35" {
+		t.Fatalf("get maxVolume: ok=%v v=%q", ok, v)
+	}
+	for _, value := range []string{"101", "-1", "loud"} {
+		if _, err := setConfigKey(cfg, "maxVolume.Office", value); err == nil {
+			t.Fatalf("expected error for max volume %q", value)
+		}
+	}
+	if _, err := setConfigKey(cfg, "maxVolume.", "40"); err == nil {
+		t.Fatal("expected error for missing room")
+	}
+	if cfg, err = unsetConfigKey(cfg, "maxVolume.KIDS ROOM"); err != nil || len(cfg.MaxVolume) != 0 {
+		t.Fatalf("unset maxVolume: %v %v", cfg.MaxVolume, err)
+	}
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sonos-playlist/internal/config"
+	"sonos-playlist/internal/daemon"
+	"sonos-playlist/internal/output"
+	legacysonos "sonos-playlist/internal/sonos"
+	"sonos-playlist/internal/storage"
+)
+
+var daemonPaths = daemon.DefaultPaths
+
+func newDaemonCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run the playback monitor in the background",
+		Long: "The daemon monitors playback in the background and replaces unavailable tracks, like --monitor, " +
+			"without keeping a terminal open. While it runs, `sonos --monitor \"<prompt>\"` hands its queue to it " +
+			"and exits as soon as the songs are queued. Its log is daemon.log in ~/.sonos-playlist.",
+		Example: "  sonos daemon start\n  sonos daemon status\n  sonos daemon stop",
+	}
+	cmd.AddCommand(newDaemonStartCmd(flags))
+	cmd.AddCommand(newDaemonStopCmd(flags))
+	cmd.AddCommand(newDaemonStatusCmd(flags))
+	cmd.AddCommand(newDaemonRunCmd())
+	return cmd
+}
+
+func newDaemonStartCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "start",
+		Short:        "Start the daemon as a detached process",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			exe, err := os.Executable()
+			if err != nil {
+				return err
+			}
+			st, err := daemon.Spawn(cmd.Context(), daemonPaths(), exe, "daemon", "run")
+			if err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, fmt.Sprintf("Daemon started (pid %d)", st.PID))
+			return writeOK(cmd, flags, "daemon.start", map[string]any{"pid": st.PID})
+		},
+	}
+}
+
+func newDaemonStopCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "stop",
+		Short:        "Stop the daemon",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths := daemonPaths()
+			err := daemon.NewClient(paths).Stop(cmd.Context())
+			if errors.Is(err, daemon.ErrNotRunning) {
+				// A PID file without a socket is left from a daemon that died.
+				_ = os.Remove(paths.PIDFile)
+				return err
+			}
+			if err != nil {
+				return err
+			}
+			if err := daemon.WaitStopped(cmd.Context(), paths, 10*time.Second); err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, "Daemon stopped")
+			return writeOK(cmd, flags, "daemon.stop", nil)
+		},
+	}
+}
+
+func newDaemonStatusCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "status",
+		Short:        "Show whether the daemon runs and which rooms it monitors",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := daemon.NewClient(daemonPaths()).Status(cmd.Context())
+			running := err == nil
+			if err != nil && !errors.Is(err, daemon.ErrNotRunning) {
+				return err
+			}
+			if isJSON(flags) {
+				return writeJSON(cmd, map[string]any{"running": running, "pid": st.PID, "startedAt": st.StartedAt, "rooms": st.Rooms})
+			}
+			w := cmd.OutOrStdout()
+			if isTSV(flags) {
+				if !running {
+					return nil
+				}
+				for _, r := range st.Rooms {
+					_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", r.Room, r.Backend, r.Songs, r.Since)
+				}
+				return nil
+			}
+			if !running {
+				_, _ = fmt.Fprintln(w, "Daemon not running")
+				return nil
+			}
+			_, _ = fmt.Fprintf(w, "Daemon running (pid %d) since %s\n", st.PID, localTime(st.StartedAt))
+			if len(st.Rooms) == 0 {
+				_, _ = fmt.Fprintln(w, "No rooms monitored")
+			}
+			for _, r := range st.Rooms {
+				_, _ = fmt.Fprintf(w, "  %s: %d songs (%s backend) since %s\n", r.Room, r.Songs, r.Backend, localTime(r.Since))
+			}
+			return nil
+		},
+	}
+}
+
+// newDaemonRunCmd is what `daemon start` runs in the background.
+func newDaemonRunCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "run",
+		Short:        "Run the daemon in the foreground",
+		Hidden:       true,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := config.Load()
+			storage.ConfigureSearchCache(cfg.SearchCacheTTL, cfg.SearchCacheMaxEntries)
+			defer func() { _ = storage.FlushSearchCache() }()
+			storage.ConfigureBlocklist(cfg.BlocklistTTL)
+			rules, err := legacysonos.LoadMatchRules(cfg.MatchRulesFile)
+			if err != nil {
+				return err
+			}
+
+			// A signal exits at once; the next start clears the PID file and
+			// socket left behind.
+			srv := &daemon.Server{
+				Paths:      daemonPaths(),
+				Out:        output.New(output.Options{Plain: true, NoColor: true}),
+				MatchRules: &rules,
+			}
+			return srv.Run(cmd.Context())
+		},
+	}
+}
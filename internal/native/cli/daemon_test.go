@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sonos-playlist/internal/daemon"
+)
+
+func TestDaemonStatusNotRunning(t *testing.T) {
+	flags := &rootFlags{Timeout: 2 * time.Second, Format: formatPlain}
+	dir := t.TempDir()
+	orig := daemonPaths
+	t.Cleanup(func() { daemonPaths = orig })
+	daemonPaths = func() daemon.Paths { return daemon.PathsIn(dir) }
+
+	cmd := newDaemonCmd(flags)
+	var out captureWriter
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{"status"})
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("daemon status: %v", err)
+	}
+	if out.String() != "Daemon not running\n" {
+		t.Fatalf("output %q", out.String())
+	}
+
+	cmd = newDaemonCmd(flags)
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{"stop"})
+	if err := cmd.ExecuteContext(context.Background()); err != daemon.ErrNotRunning {
+		t.Fatalf("daemon stop: %v, want ErrNotRunning", err)
+	}
+}
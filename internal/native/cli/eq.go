@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"sonos-playlist/internal/native/sonos"
+)
+
+type eqClient interface {
+	GetAudioSettings(ctx context.Context) (sonos.AudioSettings, error)
+	SetAudioSettings(ctx context.Context, s sonos.AudioSettings) error
+}
+
+// newEQClient targets the speaker itself rather than its group coordinator:
+// tone and EQ are per speaker.
+var newEQClient = func(ctx context.Context, flags *rootFlags) (eqClient, string, error) {
+	ip := flags.IP
+	if ip == "" {
+		tg, err := newTopologyGetter(ctx, flags.Timeout)
+		if err != nil {
+			return nil, "", err
+		}
+		top, err := tg.GetTopology(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		member, err := resolveMember(top, flags.Name, "")
+		if err != nil {
+			return nil, "", err
+		}
+		ip = member.IP
+	}
+	return newSonosClient(ip, flags.Timeout), ip, nil
+}
+
+type eqOutput struct {
+	sonos.AudioSettings
+	SpeakerIP string `json:"speakerIP,omitempty"`
+}
+
+func newEQCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "eq",
+		Short: "Get or set bass, treble, loudness, and home-theater EQ",
+		Long:  "Reads and changes a speaker's RenderingControl tone settings and Sonos EQ (night mode, speech enhancement, sub/surround/height levels). Settings a speaker does not support are omitted.",
+		Example: "  sonos eq get --name \"Living Room\"\n" +
+			"  sonos eq set --name \"Living Room\" --bass 2 --treble -1 --loudness on\n" +
+			"  sonos eq set --name \"Living Room\" --night-mode on --dialog-level on --sub-gain -3",
+	}
+	cmd.AddCommand(newEQGetCmd(flags))
+	cmd.AddCommand(newEQSetCmd(flags))
+	return cmd
+}
+
+func newEQGetCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "get",
+		Short:        "Show tone and EQ settings",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, ip, err := newEQClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			s, err := c.GetAudioSettings(ctx)
+			if err != nil {
+				return err
+			}
+			return writeEQ(cmd, flags, eqOutput{AudioSettings: s, SpeakerIP: ip})
+		},
+	}
+}
+
+func newEQSetCmd(flags *rootFlags) *cobra.Command {
+	var bass, treble, subGain, surround, height int
+	var loudness, nightMode, dialog string
+	cmd := &cobra.Command{
+		Use:          "set",
+		Short:        "Change tone or EQ settings (others are kept)",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			changed := cmd.Flags().Changed
+			var s sonos.AudioSettings
+			levels := []struct {
+				flag   string
+				v      int
+				lo, hi int
+				dst    **int
+			}{
+				{"bass", bass, sonos.MinToneLevel, sonos.MaxToneLevel, &s.Bass},
+				{"treble", treble, sonos.MinToneLevel, sonos.MaxToneLevel, &s.Treble},
+				{"sub-gain", subGain, -15, 15, &s.SubGain},
+				{"surround-level", surround, -15, 15, &s.SurroundLevel},
+				{"height-level", height, -10, 10, &s.HeightChannelLevel},
+			}
+			for _, l := range levels {
+				if !changed(l.flag) {
+					continue
+				}
+				if l.v < l.lo || l.v > l.hi {
+					return fmt.Errorf("--%s must be between %d and %d: %d", l.flag, l.lo, l.hi, l.v)
+				}
+				v := l.v
+				*l.dst = &v
+			}
+			switches := []struct {
+				flag string
+				v    string
+				dst  **bool
+			}{
+				{"loudness", loudness, &s.Loudness},
+				{"night-mode", nightMode, &s.NightMode},
+				{"dialog-level", dialog, &s.DialogLevel},
+			}
+			for _, sw := range switches {
+				if !changed(sw.flag) {
+					continue
+				}
+				on, err := parseOnOff("--"+sw.flag, sw.v)
+				if err != nil {
+					return err
+				}
+				*sw.dst = &on
+			}
+			if s.IsZero() {
+				return errors.New("provide at least one setting (see --help)")
+			}
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, ip, err := newEQClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			if err := c.SetAudioSettings(ctx, s); err != nil {
+				return err
+			}
+			current, err := c.GetAudioSettings(ctx)
+			if err != nil {
+				return err
+			}
+			return writeEQ(cmd, flags, eqOutput{AudioSettings: current, SpeakerIP: ip})
+		},
+	}
+	cmd.Flags().IntVar(&bass, "bass", 0, "Bass (-10 to 10)")
+	cmd.Flags().IntVar(&treble, "treble", 0, "Treble (-10 to 10)")
+	cmd.Flags().StringVar(&loudness, "loudness", "", "Loudness: on|off")
+	cmd.Flags().StringVar(&nightMode, "night-mode", "", "Night mode: on|off (home theater)")
+	cmd.Flags().StringVar(&dialog, "dialog-level", "", "Speech enhancement: on|off (home theater)")
+	cmd.Flags().IntVar(&subGain, "sub-gain", 0, "Sub level (-15 to 15)")
+	cmd.Flags().IntVar(&surround, "surround-level", 0, "Surround level (-15 to 15)")
+	cmd.Flags().IntVar(&height, "height-level", 0, "Height channel level (-10 to 10)")
+	return cmd
+}
+
+func writeEQ(cmd *cobra.Command, flags *rootFlags, out eqOutput) error {
+	if isJSON(flags) {
+		return writeJSON(cmd, out)
+	}
+	w := cmd.OutOrStdout()
+	s := out.AudioSettings
+	type row struct {
+		key, label string
+		v          string
+	}
+	var rows []row
+	addInt := func(key, label string, v *int) {
+		if v != nil {
+			rows = append(rows, row{key, label, fmt.Sprint(*v)})
+		}
+	}
+	addBool := func(key, label string, v *bool) {
+		if v != nil {
+			rows = append(rows, row{key, label, onOff(*v)})
+		}
+	}
+	addInt("bass", "Bass:\t\t", s.Bass)
+	addInt("treble", "Treble:\t\t", s.Treble)
+	addBool("loudness", "Loudness:\t", s.Loudness)
+	addBool("night_mode", "Night mode:\t", s.NightMode)
+	addBool("dialog_level", "Speech:\t\t", s.DialogLevel)
+	addInt("sub_gain", "Sub:\t\t", s.SubGain)
+	addInt("surround_level", "Surround:\t", s.SurroundLevel)
+	addInt("height_channel_level", "Height:\t\t", s.HeightChannelLevel)
+	for _, r := range rows {
+		if isTSV(flags) {
+			_, _ = fmt.Fprintf(w, "%s\t%s\n", r.key, r.v)
+		} else {
+			_, _ = fmt.Fprintf(w, "%s%s\n", r.label, r.v)
+		}
+	}
+	return nil
+}
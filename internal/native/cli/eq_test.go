@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sonos-playlist/internal/native/sonos"
+)
+
+type fakeEQClient struct {
+	settings sonos.AudioSettings
+	sets     []sonos.AudioSettings
+}
+
+func (f *fakeEQClient) GetAudioSettings(ctx context.Context) (sonos.AudioSettings, error) {
+	return f.settings, nil
+}
+
+func (f *fakeEQClient) SetAudioSettings(ctx context.Context, s sonos.AudioSettings) error {
+	f.sets = append(f.sets, s)
+	if s.Bass != nil {
+		f.settings.Bass = s.Bass
+	}
+	if s.NightMode != nil {
+		f.settings.NightMode = s.NightMode
+	}
+	return nil
+}
+
+func TestEQSetChangesOnlyGivenSettings(t *testing.T) {
+	flags := &rootFlags{Name: "Living Room", Timeout: 2 * time.Second, Format: formatTSV}
+	zero, off := 0, false
+	fake := &fakeEQClient{settings: sonos.AudioSettings{Bass: &zero, Treble: &zero, Loudness: &off, NightMode: &off}}
+
+	orig := newEQClient
+	t.Cleanup(func() { newEQClient = orig })
+	newEQClient = func(ctx context.Context, flags *rootFlags) (eqClient, string, error) {
+		return fake, "192.0.2.1", nil
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd := newEQCmd(flags)
+		var out captureWriter
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SilenceErrors = true
+		cmd.SetArgs(args)
+		err := cmd.ExecuteContext(context.Background())
+		return out.String(), err
+	}
+
+	out, err := run("set", "--bass", "-3", "--night-mode", "on")
+	if err != nil {
+		t.Fatalf("eq set: %v", err)
+	}
+	want := "bass\t-3\ntreble\t0\nloudness\toff\nnight_mode\ton\n"
+	if out != want {
+		t.Fatalf("output:\n%q\nwant:\n%q", out, want)
+	}
+	if len(fake.sets) != 1 || fake.sets[0].Treble != nil || fake.sets[0].Loudness != nil {
+		t.Fatalf("unexpected SetAudioSettings calls: %+v", fake.sets)
+	}
+
+	for _, args := range [][]string{
+		{"set"},
+		{"set", "--bass", "11"},
+		{"set", "--sub-gain", "-16"},
+		{"set", "--loudness", "maybe"},
+	} {
+		if _, err := run(args...); err == nil {
+			t.Fatalf("eq %v: expected error", args)
+		}
+	}
+	if len(fake.sets) != 1 {
+		t.Fatalf("invalid input reached the speaker: %+v", fake.sets)
+	}
+}
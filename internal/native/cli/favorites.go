@@ -124,32 +124,43 @@ func newFavoritesOpenCmd(flags *rootFlags) *cobra.Command {
 				return writeOK(cmd, flags, "favorites.open", map[string]any{"favorite": page.Items[0]})
 			}
 
-			// Search pages until we find a matching title.
-			const pageSize = 100
-			start := 0
-			for {
-				page, err := c.ListFavorites(cmd.Context(), start, pageSize)
-				if err != nil {
-					return err
-				}
-				for _, it := range page.Items {
-					if strings.EqualFold(it.Item.Title, title) {
-						if err := c.PlayFavorite(cmd.Context(), it.Item); err != nil {
-							return err
-						}
-						return writeOK(cmd, flags, "favorites.open", map[string]any{"favorite": it})
-					}
-				}
-				start += page.NumberReturned
-				if page.NumberReturned == 0 || start >= page.TotalMatches {
-					break
-				}
+			fav, err := findFavorite(cmd.Context(), c, title)
+			if err != nil {
+				return err
 			}
-
-			return errors.New("favorite not found: " + title)
+			if err := c.PlayFavorite(cmd.Context(), fav.Item); err != nil {
+				return err
+			}
+			return writeOK(cmd, flags, "favorites.open", map[string]any{"favorite": fav})
 		},
 	}
 
 	cmd.Flags().IntVar(&index, "index", 0, "1-based favorite index from favorites list")
 	return cmd
 }
+
+type favoritesLister interface {
+	ListFavorites(ctx context.Context, start, count int) (sonos.FavoritesPage, error)
+}
+
+// findFavorite pages through Sonos Favorites for a case-insensitive title match.
+func findFavorite(ctx context.Context, c favoritesLister, title string) (sonos.FavoriteItem, error) {
+	const pageSize = 100
+	start := 0
+	for {
+		page, err := c.ListFavorites(ctx, start, pageSize)
+		if err != nil {
+			return sonos.FavoriteItem{}, err
+		}
+		for _, it := range page.Items {
+			if strings.EqualFold(it.Item.Title, title) {
+				return it, nil
+			}
+		}
+		start += page.NumberReturned
+		if page.NumberReturned == 0 || start >= page.TotalMatches {
+			break
+		}
+	}
+	return sonos.FavoriteItem{}, errors.New("favorite not found: " + title)
+}
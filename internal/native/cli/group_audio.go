@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
+	"sonos-playlist/internal/native/sonos"
 )
 
 type groupAudioClient interface {
@@ -20,11 +22,15 @@ var newGroupAudioClient = func(ctx context.Context, flags *rootFlags) (groupAudi
 	return coordinatorClient(ctx, flags)
 }
 
+var newMemberVolumeClient = func(ip string, timeout time.Duration) volumeClient {
+	return newSonosClient(ip, timeout)
+}
+
 func newGroupVolumeCmd(flags *rootFlags) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "volume",
 		Short: "Get or set group volume",
-		Long:  "Controls GroupRenderingControl group volume on the group coordinator (0-100).",
+		Long:  "Controls GroupRenderingControl group volume on the group coordinator (0-100). The group volume is capped so no member rises above its room's maxVolume from `sonos config`.",
 	}
 
 	cmd.AddCommand(&cobra.Command{
@@ -64,14 +70,33 @@ func newGroupVolumeCmd(flags *rootFlags) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			c, err := newGroupAudioClient(cmd.Context(), flags)
+			ctx := cmd.Context()
+			c, err := newGroupAudioClient(ctx, flags)
 			if err != nil {
 				return err
 			}
-			if err := c.SetGroupVolume(cmd.Context(), v); err != nil {
+			capped, err := cappedGroupMembers(ctx, flags)
+			if err != nil {
+				return err
+			}
+			applied := v
+			if len(capped) > 0 {
+				current, err := c.GetGroupVolume(ctx)
+				if err != nil {
+					return err
+				}
+				applied = min(v, groupVolumeCeiling(capped, current))
+				if applied < v {
+					writePlainLine(cmd, flags, fmt.Sprintf("Group volume capped at %d (maxVolume)", applied))
+				}
+			}
+			if err := c.SetGroupVolume(ctx, applied); err != nil {
+				return err
+			}
+			if err := enforceGroupVolumeLimits(ctx, capped); err != nil {
 				return err
 			}
-			return writeOK(cmd, flags, "group.volume.set", map[string]any{"volume": v})
+			return writeOK(cmd, flags, "group.volume.set", map[string]any{"volume": applied, "capped": applied < v})
 		},
 	})
 
@@ -203,3 +228,83 @@ func newGroupMuteCmd(flags *rootFlags) *cobra.Command {
 
 	return cmd
 }
+
+// cappedMember is a group member whose room has a maxVolume.
+type cappedMember struct {
+	client volumeClient
+	limit  int
+	volume int
+}
+
+// cappedGroupMembers returns the members of the target's group that have a
+// maxVolume, with their current volumes.
+func cappedGroupMembers(ctx context.Context, flags *rootFlags) ([]cappedMember, error) {
+	if len(flags.MaxVolume) == 0 {
+		return nil, nil
+	}
+	tg, err := newTopologyGetter(ctx, flags.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	top, err := tg.GetTopology(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var group sonos.Group
+	var ok bool
+	if flags.IP != "" {
+		group, ok = top.GroupForIP(flags.IP)
+	} else {
+		group, ok = top.GroupForName(flags.Name)
+	}
+	if !ok {
+		return nil, errors.New("cannot check maxVolume: group not found in topology")
+	}
+	var capped []cappedMember
+	for _, m := range group.Members {
+		limit, ok := flags.MaxVolume.For(m.Name)
+		if !ok || !m.IsVisible {
+			continue
+		}
+		c := newMemberVolumeClient(m.IP, flags.Timeout)
+		v, err := c.GetVolume(ctx)
+		if err != nil {
+			return nil, err
+		}
+		capped = append(capped, cappedMember{client: c, limit: limit, volume: v})
+	}
+	return capped, nil
+}
+
+// groupVolumeCeiling returns the highest group volume that keeps every capped
+// member at or below its limit. Group volume scales members proportionally
+// from the current group volume; from 0, every member moves to the new value.
+func groupVolumeCeiling(capped []cappedMember, current int) int {
+	ceiling := 100
+	for _, m := range capped {
+		switch {
+		case current <= 0:
+			ceiling = min(ceiling, m.limit)
+		case m.volume > 0:
+			ceiling = min(ceiling, m.limit*current/m.volume)
+		}
+	}
+	return ceiling
+}
+
+// enforceGroupVolumeLimits lowers any capped member that a group volume change
+// still left above its limit, e.g. because the speaker rounded differently.
+func enforceGroupVolumeLimits(ctx context.Context, capped []cappedMember) error {
+	for _, m := range capped {
+		v, err := m.client.GetVolume(ctx)
+		if err != nil {
+			return err
+		}
+		if v > m.limit {
+			if err := m.client.SetVolume(ctx, m.limit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sonos-playlist/internal/storage"
+)
+
+var searchHistory = storage.SearchHistory
+
+type historyOpts struct {
+	Room  string
+	Days  int
+	Limit int
+}
+
+func newHistoryCmd(flags *rootFlags) *cobra.Command {
+	opts := &historyOpts{}
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show songs queued by generated playlists",
+		Long: "Every song queued from an AI prompt is recorded with its room, prompt, and time. " +
+			"When playback is monitored, entries also note whether the song actually played.",
+		Example:      "  sonos history\n  sonos history --days 7 --room Kitchen\n  sonos history search \"miles davis\"",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistory(cmd, flags, opts, "")
+		},
+	}
+	addHistoryFlags(cmd, opts)
+	cmd.AddCommand(newHistoryListCmd(flags))
+	cmd.AddCommand(newHistorySearchCmd(flags))
+	return cmd
+}
+
+func newHistoryListCmd(flags *rootFlags) *cobra.Command {
+	opts := &historyOpts{}
+	cmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List recent history (newest first)",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistory(cmd, flags, opts, "")
+		},
+	}
+	addHistoryFlags(cmd, opts)
+	return cmd
+}
+
+func newHistorySearchCmd(flags *rootFlags) *cobra.Command {
+	opts := &historyOpts{}
+	cmd := &cobra.Command{
+		Use:          "search <query>",
+		Short:        "Search history by artist, title, album, or prompt",
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := strings.TrimSpace(strings.Join(args, " "))
+			if query == "" {
+				return errors.New("query is required")
+			}
+			return runHistory(cmd, flags, opts, query)
+		},
+	}
+	addHistoryFlags(cmd, opts)
+	return cmd
+}
+
+func addHistoryFlags(cmd *cobra.Command, opts *historyOpts) {
+	cmd.Flags().StringVar(&opts.Room, "room", "", "Only show songs queued in this room")
+	cmd.Flags().IntVar(&opts.Days, "days", 0, "Only show songs queued in the last N days (0 = all)")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 50, "Maximum entries to show (0 = no limit)")
+}
+
+func runHistory(cmd *cobra.Command, flags *rootFlags, opts *historyOpts, query string) error {
+	if opts.Days < 0 || opts.Limit < 0 {
+		return errors.New("--days and --limit must not be negative")
+	}
+	q := storage.HistoryQuery{Text: query, Room: strings.TrimSpace(opts.Room), Limit: opts.Limit}
+	if opts.Days > 0 {
+		q.Since = time.Now().AddDate(0, 0, -opts.Days)
+	}
+	entries := searchHistory(q)
+
+	if isJSON(flags) {
+		return writeJSON(cmd, entries)
+	}
+	if isTSV(flags) {
+		for _, e := range entries {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%s\t%s\t%s\n", e.QueuedAt, e.Room, e.Artist, e.Title, historyStatus(e), historyPrompt(e))
+		}
+		return nil
+	}
+	if len(entries) == 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No history.")
+		return nil
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "QUEUED\tROOM\tSONG\tSTATUS\tPROMPT\n")
+	for _, e := range entries {
+		queued := e.QueuedAt
+		if t := e.QueuedTime(); !t.IsZero() {
+			queued = t.Local().Format("2006-01-02 15:04")
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s - %s\t%s\t%s\n", queued, e.Room, e.Artist, e.Title, historyStatus(e), historyPrompt(e))
+	}
+	return w.Flush()
+}
+
+// historyPrompt shows the prompt, followed by the refine instruction for
+// songs a refine added.
+func historyPrompt(e storage.HistoryEntry) string {
+	if e.Refine == "" {
+		return e.Prompt
+	}
+	if e.Prompt == "" {
+		return "refine: " + e.Refine
+	}
+	return e.Prompt + " / refine: " + e.Refine
+}
+
+func historyStatus(e storage.HistoryEntry) string {
+	switch {
+	case e.Unplayable:
+		return "unplayable"
+	case e.Played:
+		return "played"
+	default:
+		return "queued"
+	}
+}
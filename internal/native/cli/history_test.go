@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sonos-playlist/internal/storage"
+)
+
+func TestHistorySearchPassesQueryAndPrintsTSV(t *testing.T) {
+	flags := &rootFlags{Timeout: 2 * time.Second, Format: formatTSV}
+
+	var got storage.HistoryQuery
+	orig := searchHistory
+	t.Cleanup(func() { searchHistory = orig })
+	searchHistory = func(q storage.HistoryQuery) []storage.HistoryEntry {
+		got = q
+		return []storage.HistoryEntry{{
+			Artist: "Miles Davis", Title: "So What", Room: "Kitchen", Prompt: "cool jazz",
+			QueuedAt: "2026-10-01T08:00:00Z", Played: true,
+		}}
+	}
+
+	cmd := newHistoryCmd(flags)
+	var out captureWriter
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{"search", "--room", "Kitchen", "--days", "7", "miles", "davis"})
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("history search: %v", err)
+	}
+
+	if got.Text != "miles davis" || got.Room != "Kitchen" || got.Limit != 50 {
+		t.Fatalf("unexpected query: %+v", got)
+	}
+	if got.Since.IsZero() || time.Since(got.Since) < 6*24*time.Hour {
+		t.Fatalf("expected --days to set Since, got %v", got.Since)
+	}
+	want := "2026-10-01T08:00:00Z\tKitchen\tMiles Davis\tSo What\tplayed\tcool jazz\n"
+	if out.String() != want {
+		t.Fatalf("output:\n%q\nwant:\n%q", out.String(), want)
+	}
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sonos-playlist/internal/native/sonos"
+)
+
+type modeClient interface {
+	GetPlayMode(ctx context.Context) (sonos.PlayMode, error)
+	SetPlayMode(ctx context.Context, mode sonos.PlayMode) error
+	GetCrossfadeMode(ctx context.Context) (bool, error)
+	SetCrossfadeMode(ctx context.Context, on bool) error
+}
+
+var newModeClient = func(ctx context.Context, flags *rootFlags) (modeClient, string, error) {
+	c, err := coordinatorClient(ctx, flags)
+	if err != nil {
+		return nil, "", err
+	}
+	return c, c.IP, nil
+}
+
+type modeOutput struct {
+	PlayMode      string       `json:"playMode"`
+	Repeat        sonos.Repeat `json:"repeat"`
+	Shuffle       bool         `json:"shuffle"`
+	Crossfade     bool         `json:"crossfade"`
+	CoordinatorIP string       `json:"coordinatorIP,omitempty"`
+}
+
+func newModeCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mode",
+		Short: "Get or set repeat, shuffle, and crossfade",
+		Long:  "Reads and changes the group coordinator's AVTransport play mode (repeat/shuffle) and crossfade.",
+		Example: "  sonos mode get --name Kitchen\n" +
+			"  sonos mode set --name Kitchen --repeat all --shuffle on\n" +
+			"  sonos mode set --name Kitchen --crossfade off",
+	}
+	cmd.AddCommand(newModeGetCmd(flags))
+	cmd.AddCommand(newModeSetCmd(flags))
+	return cmd
+}
+
+func newModeGetCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "get",
+		Short:        "Show repeat, shuffle, and crossfade",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, ip, err := newModeClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			mode, err := c.GetPlayMode(ctx)
+			if err != nil {
+				return err
+			}
+			crossfade, err := c.GetCrossfadeMode(ctx)
+			if err != nil {
+				return err
+			}
+			return writeMode(cmd, flags, modeOutput{
+				PlayMode: mode.String(), Repeat: mode.Repeat, Shuffle: mode.Shuffle, Crossfade: crossfade, CoordinatorIP: ip,
+			})
+		},
+	}
+}
+
+func newModeSetCmd(flags *rootFlags) *cobra.Command {
+	var repeat, shuffle, crossfade string
+	cmd := &cobra.Command{
+		Use:          "set",
+		Short:        "Change repeat, shuffle, or crossfade (others are kept)",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			changed := cmd.Flags().Changed
+			if !changed("repeat") && !changed("shuffle") && !changed("crossfade") {
+				return errors.New("provide --repeat, --shuffle, or --crossfade")
+			}
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, ip, err := newModeClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+
+			mode, err := c.GetPlayMode(ctx)
+			if err != nil {
+				return err
+			}
+			if changed("repeat") || changed("shuffle") {
+				next := mode
+				if changed("repeat") {
+					if next.Repeat, err = sonos.ParseRepeat(repeat); err != nil {
+						return err
+					}
+				}
+				if changed("shuffle") {
+					if next.Shuffle, err = parseOnOff("--shuffle", shuffle); err != nil {
+						return err
+					}
+				}
+				if next != mode {
+					if err := c.SetPlayMode(ctx, next); err != nil {
+						return err
+					}
+					mode = next
+				}
+			}
+
+			var fade bool
+			if changed("crossfade") {
+				if fade, err = parseOnOff("--crossfade", crossfade); err != nil {
+					return err
+				}
+				if err := c.SetCrossfadeMode(ctx, fade); err != nil {
+					return err
+				}
+			} else if fade, err = c.GetCrossfadeMode(ctx); err != nil {
+				return err
+			}
+			return writeMode(cmd, flags, modeOutput{
+				PlayMode: mode.String(), Repeat: mode.Repeat, Shuffle: mode.Shuffle, Crossfade: fade, CoordinatorIP: ip,
+			})
+		},
+	}
+	cmd.Flags().StringVar(&repeat, "repeat", "", "Repeat: all|one|off")
+	cmd.Flags().StringVar(&shuffle, "shuffle", "", "Shuffle: on|off")
+	cmd.Flags().StringVar(&crossfade, "crossfade", "", "Crossfade: on|off")
+	return cmd
+}
+
+func writeMode(cmd *cobra.Command, flags *rootFlags, out modeOutput) error {
+	if isJSON(flags) {
+		return writeJSON(cmd, out)
+	}
+	w := cmd.OutOrStdout()
+	if isTSV(flags) {
+		_, _ = fmt.Fprintf(w, "repeat\t%s\n", out.Repeat)
+		_, _ = fmt.Fprintf(w, "shuffle\t%s\n", onOff(out.Shuffle))
+		_, _ = fmt.Fprintf(w, "crossfade\t%s\n", onOff(out.Crossfade))
+		_, _ = fmt.Fprintf(w, "play_mode\t%s\n", out.PlayMode)
+		return nil
+	}
+	_, _ = fmt.Fprintf(w, "Repeat:\t\t%s\n", out.Repeat)
+	_, _ = fmt.Fprintf(w, "Shuffle:\t%s\n", onOff(out.Shuffle))
+	_, _ = fmt.Fprintf(w, "Crossfade:\t%s\n", onOff(out.Crossfade))
+	return nil
+}
+
+func parseOnOff(flag, s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "on", "true", "1", "yes":
+		return true, nil
+	case "off", "false", "0", "no":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid %s %q (expected on|off)", flag, s)
+	}
+}
+
+func onOff(v bool) string {
+	if v {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sonos-playlist/internal/native/sonos"
+)
+
+type fakeModeClient struct {
+	mode      sonos.PlayMode
+	crossfade bool
+	sets      int
+}
+
+func (f *fakeModeClient) GetPlayMode(ctx context.Context) (sonos.PlayMode, error) {
+	return f.mode, nil
+}
+
+func (f *fakeModeClient) SetPlayMode(ctx context.Context, mode sonos.PlayMode) error {
+	f.sets++
+	f.mode = mode
+	return nil
+}
+
+func (f *fakeModeClient) GetCrossfadeMode(ctx context.Context) (bool, error) {
+	return f.crossfade, nil
+}
+
+func (f *fakeModeClient) SetCrossfadeMode(ctx context.Context, on bool) error {
+	f.crossfade = on
+	return nil
+}
+
+func TestModeSetKeepsUnchangedSettings(t *testing.T) {
+	flags := &rootFlags{Name: "Kitchen", Timeout: 2 * time.Second, Format: formatTSV}
+	fake := &fakeModeClient{mode: sonos.PlayMode{Repeat: sonos.RepeatAll}}
+
+	orig := newModeClient
+	t.Cleanup(func() { newModeClient = orig })
+	newModeClient = func(ctx context.Context, flags *rootFlags) (modeClient, string, error) {
+		return fake, "192.0.2.1", nil
+	}
+
+	cmd := newModeCmd(flags)
+	var out captureWriter
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{"set", "--shuffle", "on", "--crossfade", "on"})
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("mode set: %v", err)
+	}
+	want := "repeat\tall\nshuffle\ton\ncrossfade\ton\nplay_mode\tSHUFFLE\n"
+	if out.String() != want {
+		t.Fatalf("output:\n%q\nwant:\n%q", out.String(), want)
+	}
+
+	cmd = newModeCmd(flags)
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{"set", "--repeat", "sometimes"})
+	if err := cmd.ExecuteContext(context.Background()); err == nil {
+		t.Fatal("expected error for invalid --repeat")
+	}
+	if fake.sets != 1 {
+		t.Fatalf("SetPlayMode called %d times, want 1", fake.sets)
+	}
+}
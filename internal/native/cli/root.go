@@ -20,6 +20,8 @@ type rootFlags struct {
 	Format  string
 	JSON    bool // Deprecated: use --format json
 	Debug   bool
+	// MaxVolume holds the per-room volume caps from the config file.
+	MaxVolume appconfig.VolumeLimits
 }
 
 func Execute() error {
@@ -44,8 +46,8 @@ func ExecuteArgs(args []string) error {
 func ShouldHandle(args []string) bool {
 	nativeCommands := map[string]struct{}{
 		"discover": {}, "status": {}, "now": {}, "play": {}, "pause": {}, "stop": {}, "next": {}, "prev": {},
-		"queue": {}, "favorites": {}, "group": {}, "config": {}, "scene": {}, "watch": {}, "volume": {}, "mute": {},
-		"help": {},
+		"queue": {}, "favorites": {}, "group": {}, "config": {}, "scene": {}, "watch": {}, "volume": {}, "mute": {}, "mode": {}, "sleep": {}, "alarm": {}, "eq": {},
+		"taste": {}, "history": {}, "cache": {}, "blocklist": {}, "daemon": {}, "help": {},
 	}
 	for _, arg := range args {
 		if strings.HasPrefix(arg, "-") {
@@ -76,6 +78,7 @@ func newRootCmd() (*cobra.Command, *rootFlags, error) {
 		return nil, nil, err
 	}
 	cfg = cfg.Normalize()
+	flags.MaxVolume = cfg.MaxVolume
 	timeout := 5 * time.Second
 	if cfg.Timeout != "" {
 		if parsed, err := time.ParseDuration(cfg.Timeout); err == nil && parsed > 0 {
@@ -140,7 +143,16 @@ func newRootCmd() (*cobra.Command, *rootFlags, error) {
 	rootCmd.AddCommand(newQueueCmd(flags))
 	rootCmd.AddCommand(newVolumeCmd(flags))
 	rootCmd.AddCommand(newMuteCmd(flags))
+	rootCmd.AddCommand(newModeCmd(flags))
+	rootCmd.AddCommand(newSleepCmd(flags))
+	rootCmd.AddCommand(newAlarmCmd(flags))
+	rootCmd.AddCommand(newEQCmd(flags))
 	rootCmd.AddCommand(newWatchCmd(flags))
+	rootCmd.AddCommand(newTasteCmd(flags))
+	rootCmd.AddCommand(newHistoryCmd(flags))
+	rootCmd.AddCommand(newCacheCmd(flags))
+	rootCmd.AddCommand(newBlocklistCmd(flags))
+	rootCmd.AddCommand(newDaemonCmd(flags))
 
 	return rootCmd, flags, nil
 }
@@ -234,42 +246,59 @@ func validateTarget(flags *rootFlags) error {
 }
 
 func resolveTargetCoordinatorIP(ctx context.Context, flags *rootFlags) (string, error) {
-	if err := validateTarget(flags); err != nil {
+	coord, err := resolveTargetCoordinator(ctx, flags)
+	if err != nil {
 		return "", err
 	}
+	return coord.IP, nil
+}
+
+// resolveTargetCoordinator returns the coordinator of the target's group. When
+// --ip is given and topology is unavailable, only the IP is filled in.
+func resolveTargetCoordinator(ctx context.Context, flags *rootFlags) (sonos.Member, error) {
+	if err := validateTarget(flags); err != nil {
+		return sonos.Member{}, err
+	}
 
 	// If IP is provided, attempt to resolve to coordinator, but fall back.
 	if flags.IP != "" {
 		c := newSonosClient(flags.IP, flags.Timeout)
 		top, err := c.GetTopology(ctx)
 		if err != nil {
-			return flags.IP, nil
+			return sonos.Member{IP: flags.IP}, nil
 		}
 		if coordIP, ok := top.CoordinatorIPFor(flags.IP); ok {
-			return coordIP, nil
+			return coordinatorMember(top, coordIP), nil
 		}
-		return flags.IP, nil
+		return sonos.Member{IP: flags.IP}, nil
 	}
 
 	// Name-based selection: discover a speaker, then use topology.
 	devs, err := sonosDiscover(ctx, sonos.DiscoverOptions{Timeout: flags.Timeout})
 	if err != nil {
-		return "", err
+		return sonos.Member{}, err
 	}
 	if len(devs) == 0 {
-		return "", errors.New("no speakers found")
+		return sonos.Member{}, errors.New("no speakers found")
 	}
 
 	c := newSonosClient(devs[0].IP, flags.Timeout)
 	top, err := c.GetTopology(ctx)
 	if err != nil {
-		return "", err
+		return sonos.Member{}, err
 	}
 	coordIP, ok := top.CoordinatorIPForName(flags.Name)
 	if !ok {
-		return "", errors.New("speaker name not found in topology: " + flags.Name)
+		return sonos.Member{}, errors.New("speaker name not found in topology: " + flags.Name)
+	}
+	return coordinatorMember(top, coordIP), nil
+}
+
+func coordinatorMember(top sonos.Topology, ip string) sonos.Member {
+	if m, ok := top.FindByIP(ip); ok {
+		return m
 	}
-	return coordIP, nil
+	return sonos.Member{IP: ip}
 }
 
 func coordinatorClient(ctx context.Context, flags *rootFlags) (*sonos.Client, error) {
@@ -25,6 +25,8 @@ type sceneSpeakerClient interface {
 	SetVolume(ctx context.Context, volume int) error
 	GetMute(ctx context.Context) (bool, error)
 	SetMute(ctx context.Context, mute bool) error
+	GetAudioSettings(ctx context.Context) (sonos.AudioSettings, error)
+	SetAudioSettings(ctx context.Context, s sonos.AudioSettings) error
 }
 
 var newSceneStore = func() (scenes.Store, error) {
@@ -49,8 +51,8 @@ var newSceneSpeakerClient = func(ip string, timeout time.Duration) sceneSpeakerC
 func newSceneCmd(flags *rootFlags) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "scene",
-		Short: "Save and apply presets (grouping + volumes)",
-		Long:  "Scenes capture grouping plus per-room volume/mute, and can be applied later to restore that state.",
+		Short: "Save and apply presets (grouping + volumes + EQ)",
+		Long:  "Scenes capture grouping plus per-room volume/mute and tone/EQ, and can be applied later to restore that state.",
 	}
 	cmd.AddCommand(newSceneListCmd(flags))
 	cmd.AddCommand(newSceneSaveCmd(flags))
@@ -153,7 +155,7 @@ func newSceneSaveCmd(flags *rootFlags) *cobra.Command {
 				})
 			}
 
-			// Per-device volume/mute.
+			// Per-device volume/mute and EQ.
 			seen := map[string]bool{}
 			for _, g := range top.Groups {
 				for _, m := range g.Members {
@@ -167,12 +169,14 @@ func newSceneSaveCmd(flags *rootFlags) *cobra.Command {
 					c := newSceneSpeakerClient(m.IP, flags.Timeout)
 					vol, _ := c.GetVolume(cmd.Context())
 					mute, _ := c.GetMute(cmd.Context())
+					audio, _ := c.GetAudioSettings(cmd.Context())
 					scene.Devices = append(scene.Devices, scenes.SceneDevice{
-						UUID:   m.UUID,
-						Name:   m.Name,
-						IP:     m.IP,
-						Volume: vol,
-						Mute:   mute,
+						UUID:          m.UUID,
+						Name:          m.Name,
+						IP:            m.IP,
+						Volume:        vol,
+						Mute:          mute,
+						AudioSettings: audio,
 					})
 				}
 			}
@@ -329,7 +333,7 @@ func newSceneApplyCmd(flags *rootFlags) *cobra.Command {
 				}
 			}
 
-			// Step 3: restore per-device volume/mute.
+			// Step 3: restore per-device EQ and volume/mute.
 			for _, dev := range scene.Devices {
 				if !involved[dev.UUID] || !isVisible(dev.UUID) {
 					continue
@@ -342,8 +346,13 @@ func newSceneApplyCmd(flags *rootFlags) *cobra.Command {
 					continue
 				}
 				c := newSceneSpeakerClient(ip, flags.Timeout)
+				_ = c.SetAudioSettings(cmd.Context(), dev.AudioSettings)
 				_ = c.SetMute(cmd.Context(), dev.Mute)
-				_ = c.SetVolume(cmd.Context(), dev.Volume)
+				room := dev.Name
+				if m, ok := uuidToMember[dev.UUID]; ok && m.Name != "" {
+					room = m.Name
+				}
+				_ = c.SetVolume(cmd.Context(), flags.MaxVolume.Clamp(room, dev.Volume))
 			}
 
 			return writeOK(cmd, flags, "scene.apply", map[string]any{"name": scene.Name, "only": strings.TrimSpace(only)})
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sonos-playlist/internal/native/scenes"
+	"sonos-playlist/internal/native/sonos"
+)
+
+type memorySceneStore struct {
+	scenes map[string]scenes.Scene
+}
+
+func (s *memorySceneStore) List() ([]scenes.SceneMeta, error) {
+	return nil, nil
+}
+
+func (s *memorySceneStore) Get(name string) (scenes.Scene, bool, error) {
+	sc, ok := s.scenes[name]
+	return sc, ok, nil
+}
+
+func (s *memorySceneStore) Put(scene scenes.Scene) error {
+	s.scenes[scene.Name] = scene
+	return nil
+}
+
+func (s *memorySceneStore) Delete(name string) error {
+	delete(s.scenes, name)
+	return nil
+}
+
+type fakeSceneSpeaker struct {
+	volume int
+	mute   bool
+	audio  sonos.AudioSettings
+}
+
+func (f *fakeSceneSpeaker) LeaveGroup(ctx context.Context) error {
+	return nil
+}
+
+func (f *fakeSceneSpeaker) JoinGroup(ctx context.Context, coordinatorUUID string) error {
+	return nil
+}
+
+func (f *fakeSceneSpeaker) GetVolume(ctx context.Context) (int, error) {
+	return f.volume, nil
+}
+
+func (f *fakeSceneSpeaker) SetVolume(ctx context.Context, volume int) error {
+	f.volume = volume
+	return nil
+}
+
+func (f *fakeSceneSpeaker) GetMute(ctx context.Context) (bool, error) {
+	return f.mute, nil
+}
+
+func (f *fakeSceneSpeaker) SetMute(ctx context.Context, mute bool) error {
+	f.mute = mute
+	return nil
+}
+
+func (f *fakeSceneSpeaker) GetAudioSettings(ctx context.Context) (sonos.AudioSettings, error) {
+	return f.audio, nil
+}
+
+func (f *fakeSceneSpeaker) SetAudioSettings(ctx context.Context, s sonos.AudioSettings) error {
+	f.audio = s
+	return nil
+}
+
+func TestSceneRestoresEQ(t *testing.T) {
+	flags := &rootFlags{Timeout: 2 * time.Second}
+	living := sonos.Member{Name: "Living Room", IP: "192.0.2.20", UUID: "RINCON_LIVING1400", IsVisible: true, IsCoordinator: true}
+	top := sonos.Topology{
+		Groups: []sonos.Group{{ID: "G1", Coordinator: living, Members: []sonos.Member{living}}},
+		ByName: map[string]sonos.Member{living.Name: living},
+		ByIP:   map[string]sonos.Member{living.IP: living},
+	}
+	bass, night, sub := 3, true, -2
+	speaker := &fakeSceneSpeaker{volume: 30, audio: sonos.AudioSettings{Bass: &bass, NightMode: &night, SubGain: &sub}}
+	store := &memorySceneStore{scenes: map[string]scenes.Scene{}}
+
+	origStore, origTop, origSpeaker := newSceneStore, newSceneTopologyGetter, newSceneSpeakerClient
+	t.Cleanup(func() {
+		newSceneStore, newSceneTopologyGetter, newSceneSpeakerClient = origStore, origTop, origSpeaker
+	})
+	newSceneStore = func() (scenes.Store, error) { return store, nil }
+	newSceneTopologyGetter = func(ctx context.Context, timeout time.Duration) (sceneTopologyGetter, error) {
+		return &fakeTopologyGetter{top: top}, nil
+	}
+	newSceneSpeakerClient = func(ip string, timeout time.Duration) sceneSpeakerClient { return speaker }
+
+	run := func(args ...string) {
+		t.Helper()
+		cmd := newSceneCmd(flags)
+		var out captureWriter
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SilenceErrors = true
+		cmd.SetArgs(args)
+		if err := cmd.ExecuteContext(context.Background()); err != nil {
+			t.Fatalf("scene %v: %v", args, err)
+		}
+	}
+
+	run("save", "Movie")
+	dev := store.scenes["Movie"].Devices[0]
+	if dev.Bass == nil || *dev.Bass != 3 || dev.NightMode == nil || !*dev.NightMode || dev.Treble != nil {
+		t.Fatalf("captured device: %+v", dev)
+	}
+
+	speaker.volume, speaker.audio = 60, sonos.AudioSettings{}
+	run("apply", "Movie")
+	if speaker.volume != 30 || speaker.audio.SubGain == nil || *speaker.audio.SubGain != -2 {
+		t.Fatalf("restored speaker: volume=%d audio=%+v", speaker.volume, speaker.audio)
+	}
+}
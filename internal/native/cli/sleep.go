@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type sleepClient interface {
+	ConfigureSleepTimer(ctx context.Context, d time.Duration) error
+	GetRemainingSleepTimerDuration(ctx context.Context) (time.Duration, error)
+}
+
+var newSleepClient = func(ctx context.Context, flags *rootFlags) (sleepClient, string, error) {
+	c, err := coordinatorClient(ctx, flags)
+	if err != nil {
+		return nil, "", err
+	}
+	return c, c.IP, nil
+}
+
+func newSleepCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "sleep",
+		Short:   "Get or set the sleep timer",
+		Long:    "Stops playback in the group after a while, using the coordinator's AVTransport sleep timer (up to 24h).",
+		Example: "  sonos sleep set 30m --name \"Kids Room\"\n  sonos sleep get --name \"Kids Room\"\n  sonos sleep set off --name \"Kids Room\"",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:          "get",
+		Short:        "Show the time left on the sleep timer",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, ip, err := newSleepClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			remaining, err := c.GetRemainingSleepTimerDuration(ctx)
+			if err != nil {
+				return err
+			}
+			if isJSON(flags) {
+				return writeJSON(cmd, map[string]any{"active": remaining > 0, "remainingSeconds": int(remaining.Seconds()), "coordinatorIP": ip})
+			}
+			if isTSV(flags) {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "sleep_timer\t%d\n", int(remaining.Seconds()))
+				return nil
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), sleepTimerText(remaining))
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:          "set <duration|off>",
+		Short:        "Stop playback after a duration such as 30m or 1h15m (off cancels)",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := parseSleepDuration(args[0])
+			if err != nil {
+				return err
+			}
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, ip, err := newSleepClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			if err := c.ConfigureSleepTimer(ctx, d); err != nil {
+				return err
+			}
+			if d == 0 {
+				writePlainLine(cmd, flags, "Sleep timer off")
+			} else {
+				writePlainLine(cmd, flags, "Sleep timer set for "+d.String())
+			}
+			return writeOK(cmd, flags, "sleep.set", map[string]any{"coordinatorIP": ip, "seconds": int(d.Seconds())})
+		},
+	})
+
+	return cmd
+}
+
+// parseSleepDuration accepts a Go duration or a bare number of minutes;
+// "off" and "0" cancel the timer.
+func parseSleepDuration(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "off" || s == "cancel" || s == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		var minutes int
+		if _, scanErr := fmt.Sscanf(s, "%d", &minutes); scanErr != nil || fmt.Sprint(minutes) != s {
+			return 0, fmt.Errorf("invalid sleep duration %q (e.g. 30m, 1h, off)", s)
+		}
+		d = time.Duration(minutes) * time.Minute
+	}
+	if d < time.Second || d >= 24*time.Hour {
+		return 0, fmt.Errorf("sleep duration must be between 1s and 24h: %s", s)
+	}
+	return d.Round(time.Second), nil
+}
+
+// sleepTimerText describes the time left on a sleep timer.
+func sleepTimerText(remaining time.Duration) string {
+	if remaining <= 0 {
+		return "off"
+	}
+	return remaining.Round(time.Second).String() + " remaining"
+}
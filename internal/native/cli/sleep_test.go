@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeSleepClient struct {
+	remaining time.Duration
+}
+
+func (f *fakeSleepClient) ConfigureSleepTimer(ctx context.Context, d time.Duration) error {
+	f.remaining = d
+	return nil
+}
+
+func (f *fakeSleepClient) GetRemainingSleepTimerDuration(ctx context.Context) (time.Duration, error) {
+	return f.remaining, nil
+}
+
+func TestParseSleepDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"30m":    30 * time.Minute,
+		"1h15m":  75 * time.Minute,
+		"45":     45 * time.Minute,
+		"off":    0,
+		"Cancel": 0,
+		"0":      0,
+	}
+	for in, want := range cases {
+		if got, err := parseSleepDuration(in); err != nil || got != want {
+			t.Fatalf("parseSleepDuration(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"soon", "24h", "500ms", "-5m", "12abc"} {
+		if _, err := parseSleepDuration(in); err == nil {
+			t.Fatalf("parseSleepDuration(%q): expected error", in)
+		}
+	}
+}
+
+func TestSleepSetThenGet(t *testing.T) {
+	flags := &rootFlags{Name: "Kids Room", Timeout: 2 * time.Second}
+	fake := &fakeSleepClient{}
+
+	orig := newSleepClient
+	t.Cleanup(func() { newSleepClient = orig })
+	newSleepClient = func(ctx context.Context, flags *rootFlags) (sleepClient, string, error) {
+		return fake, "192.0.2.1", nil
+	}
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := newSleepCmd(flags)
+		var out captureWriter
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SilenceErrors = true
+		cmd.SetArgs(args)
+		if err := cmd.ExecuteContext(context.Background()); err != nil {
+			t.Fatalf("sleep %v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	if got := run("set", "30m"); got != "Sleep timer set for 30m0s\n" {
+		t.Fatalf("sleep set output: %q", got)
+	}
+	if fake.remaining != 30*time.Minute {
+		t.Fatalf("timer = %v, want 30m", fake.remaining)
+	}
+	if got := run("get"); got != "30m0s remaining\n" {
+		t.Fatalf("sleep get output: %q", got)
+	}
+	if got := run("set", "off"); got != "Sleep timer off\n" {
+		t.Fatalf("sleep off output: %q", got)
+	}
+
+	flags.Format = formatTSV
+	if got := run("get"); got != "sleep_timer\t0\n" {
+		t.Fatalf("sleep get tsv output: %q", got)
+	}
+}
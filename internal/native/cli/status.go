@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 	"sonos-playlist/internal/native/sonos"
@@ -14,6 +15,7 @@ type statusClient interface {
 	GetPositionInfo(ctx context.Context) (sonos.PositionInfo, error)
 	GetVolume(ctx context.Context) (int, error)
 	GetMute(ctx context.Context) (bool, error)
+	GetRemainingSleepTimerDuration(ctx context.Context) (time.Duration, error)
 }
 
 var newStatusClient = func(ctx context.Context, flags *rootFlags) (statusClient, error) {
@@ -21,13 +23,14 @@ var newStatusClient = func(ctx context.Context, flags *rootFlags) (statusClient,
 }
 
 type statusOutput struct {
-	Device      sonos.Device        `json:"device"`
-	Transport   sonos.TransportInfo `json:"transport"`
-	Position    sonos.PositionInfo  `json:"position"`
-	NowPlaying  *sonos.DIDLItem     `json:"nowPlaying,omitempty"`
-	AlbumArtURL string              `json:"albumArtURL,omitempty"`
-	Volume      int                 `json:"volume"`
-	Mute        bool                `json:"mute"`
+	Device            sonos.Device        `json:"device"`
+	Transport         sonos.TransportInfo `json:"transport"`
+	Position          sonos.PositionInfo  `json:"position"`
+	NowPlaying        *sonos.DIDLItem     `json:"nowPlaying,omitempty"`
+	AlbumArtURL       string              `json:"albumArtURL,omitempty"`
+	Volume            int                 `json:"volume"`
+	Mute              bool                `json:"mute"`
+	SleepTimerSeconds int                 `json:"sleepTimerSeconds"`
 }
 
 func newStatusCmd(flags *rootFlags) *cobra.Command {
@@ -52,6 +55,7 @@ func newStatusCmd(flags *rootFlags) *cobra.Command {
 			position, _ := c.GetPositionInfo(ctx)
 			vol, _ := c.GetVolume(ctx)
 			mute, _ := c.GetMute(ctx)
+			sleep, _ := c.GetRemainingSleepTimerDuration(ctx)
 
 			var nowPlaying *sonos.DIDLItem
 			var albumArtURL string
@@ -61,13 +65,14 @@ func newStatusCmd(flags *rootFlags) *cobra.Command {
 			}
 
 			out := statusOutput{
-				Device:      dev,
-				Transport:   transport,
-				Position:    position,
-				NowPlaying:  nowPlaying,
-				AlbumArtURL: albumArtURL,
-				Volume:      vol,
-				Mute:        mute,
+				Device:            dev,
+				Transport:         transport,
+				Position:          position,
+				NowPlaying:        nowPlaying,
+				AlbumArtURL:       albumArtURL,
+				Volume:            vol,
+				Mute:              mute,
+				SleepTimerSeconds: int(sleep.Seconds()),
 			}
 
 			if isJSON(flags) {
@@ -101,6 +106,9 @@ func newStatusCmd(flags *rootFlags) *cobra.Command {
 				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "duration\t%s\n", position.TrackDuration)
 				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "volume\t%d\n", vol)
 				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "mute\t%v\n", mute)
+				if sleep > 0 {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "sleep_timer\t%d\n", int(sleep.Seconds()))
+				}
 				return nil
 			}
 
@@ -128,6 +136,9 @@ func newStatusCmd(flags *rootFlags) *cobra.Command {
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Time:\t\t%s / %s\n", position.RelTime, position.TrackDuration)
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Volume:\t\t%d\n", vol)
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Mute:\t\t%v\n", mute)
+			if sleep > 0 {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Sleep:\t\t%s\n", sleepTimerText(sleep))
+			}
 			return nil
 		},
 	}
@@ -15,6 +15,7 @@ type fakeStatusClient struct {
 	position  sonos.PositionInfo
 	volume    int
 	mute      bool
+	sleep     time.Duration
 }
 
 func (f *fakeStatusClient) GetDeviceDescription(ctx context.Context) (sonos.Device, error) {
@@ -37,6 +38,10 @@ func (f *fakeStatusClient) GetMute(ctx context.Context) (bool, error) {
 	return f.mute, nil
 }
 
+func (f *fakeStatusClient) GetRemainingSleepTimerDuration(ctx context.Context) (time.Duration, error) {
+	return f.sleep, nil
+}
+
 func TestStatusShowsNowPlayingFields(t *testing.T) {
 	flags := &rootFlags{Name: "Office", Timeout: 2 * time.Second}
 
@@ -67,6 +72,7 @@ func TestStatusShowsNowPlayingFields(t *testing.T) {
 		},
 		volume: 25,
 		mute:   false,
+		sleep:  25 * time.Minute,
 	}
 
 	orig := newStatusClient
@@ -99,6 +105,9 @@ func TestStatusShowsNowPlayingFields(t *testing.T) {
 	if !strings.Contains(s, "AlbumArt:\thttp://192.168.1.50:1400/getaa?s=1&u=abc") {
 		t.Fatalf("missing album art url: %s", s)
 	}
+	if !strings.Contains(s, "Sleep:\t\t25m0s remaining") {
+		t.Fatalf("missing sleep timer: %s", s)
+	}
 }
 
 func TestStatusJSONIncludesNowPlaying(t *testing.T) {
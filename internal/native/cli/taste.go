@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sonos-playlist/internal/taste"
+)
+
+var newTasteStore = func() (taste.Store, error) { return taste.NewDefaultStore() }
+
+func newTasteCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "taste",
+		Short: "Manage the household taste profile",
+		Long: "Liked and disliked artists and genres are added to every AI playlist request. " +
+			"Songs by disliked artists are also dropped from results, whatever the model suggests.",
+		Example: "  sonos taste dislike Nickelback\n  sonos taste like --genre \"bossa nova\"\n  sonos taste show",
+	}
+	cmd.AddCommand(newTasteShowCmd(flags))
+	cmd.AddCommand(newTasteEditCmd(flags, "like", "Add a liked artist (or --genre)", taste.Profile.Like))
+	cmd.AddCommand(newTasteEditCmd(flags, "dislike", "Add a disliked artist (or --genre)", taste.Profile.Dislike))
+	cmd.AddCommand(newTasteEditCmd(flags, "remove", "Remove an artist (or --genre) from the profile", taste.Profile.Remove))
+	cmd.AddCommand(newTasteClearCmd(flags))
+	return cmd
+}
+
+func newTasteShowCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "show",
+		Short:        "Show the taste profile",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := newTasteStore()
+			if err != nil {
+				return err
+			}
+			p, err := s.Load()
+			if err != nil {
+				return err
+			}
+			if isJSON(flags) {
+				return writeJSON(cmd, p)
+			}
+			rows := []struct {
+				label string
+				items []string
+			}{
+				{"liked artists", p.LikedArtists},
+				{"disliked artists", p.DislikedArtists},
+				{"liked genres", p.LikedGenres},
+				{"disliked genres", p.DislikedGenres},
+			}
+			if isTSV(flags) {
+				for _, r := range rows {
+					for _, item := range r.items {
+						_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", r.label, item)
+					}
+				}
+				return nil
+			}
+			if p.IsEmpty() {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Taste profile is empty.")
+				return nil
+			}
+			for _, r := range rows {
+				if len(r.items) == 0 {
+					continue
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", r.label, strings.Join(r.items, ", "))
+			}
+			return nil
+		},
+	}
+}
+
+func newTasteEditCmd(flags *rootFlags, use, short string, edit func(taste.Profile, taste.Kind, string) (taste.Profile, error)) *cobra.Command {
+	var genre bool
+	cmd := &cobra.Command{
+		Use:          use + " <name>",
+		Short:        short,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := strings.TrimSpace(strings.Join(args, " "))
+			kind := taste.Artist
+			if genre {
+				kind = taste.Genre
+			}
+
+			s, err := newTasteStore()
+			if err != nil {
+				return err
+			}
+			p, err := s.Load()
+			if err != nil {
+				return err
+			}
+			p, err = edit(p, kind, name)
+			if err != nil {
+				return err
+			}
+			if err := s.Save(p); err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, fmt.Sprintf("%s %s: %s", use, kind, name))
+			return writeOK(cmd, flags, "taste."+use, map[string]any{"kind": string(kind), "name": name})
+		},
+	}
+	cmd.Flags().BoolVar(&genre, "genre", false, "Treat the name as a genre instead of an artist")
+	return cmd
+}
+
+func newTasteClearCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:          "clear",
+		Short:        "Remove every entry from the taste profile",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := newTasteStore()
+			if err != nil {
+				return err
+			}
+			if err := s.Save(taste.Profile{}); err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, "Taste profile cleared")
+			return writeOK(cmd, flags, "taste.clear", nil)
+		},
+	}
+}
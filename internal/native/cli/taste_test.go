@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sonos-playlist/internal/taste"
+)
+
+func TestTasteDislikeThenShowJSON(t *testing.T) {
+	flags := &rootFlags{Timeout: 2 * time.Second, Format: formatJSON}
+
+	store, err := taste.NewFileStore(filepath.Join(t.TempDir(), "taste.json"))
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	orig := newTasteStore
+	t.Cleanup(func() { newTasteStore = orig })
+	newTasteStore = func() (taste.Store, error) { return store, nil }
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := newTasteCmd(flags)
+		var out captureWriter
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SilenceErrors = true
+		cmd.SetArgs(args)
+		if err := cmd.ExecuteContext(context.Background()); err != nil {
+			t.Fatalf("taste %v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	run("dislike", "Nickelback")
+	run("like", "--genre", "bossa", "nova")
+
+	var got taste.Profile
+	if err := json.Unmarshal([]byte(run("show")), &got); err != nil {
+		t.Fatalf("decode show output: %v", err)
+	}
+	if len(got.DislikedArtists) != 1 || got.DislikedArtists[0] != "Nickelback" {
+		t.Fatalf("disliked artists: %v", got.DislikedArtists)
+	}
+	if len(got.LikedGenres) != 1 || got.LikedGenres[0] != "bossa nova" {
+		t.Fatalf("liked genres: %v", got.LikedGenres)
+	}
+
+	run("remove", "nickelback")
+	p, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(p.DislikedArtists) != 0 {
+		t.Fatalf("expected artist removed: %+v", p)
+	}
+}
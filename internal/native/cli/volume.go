@@ -1,17 +1,56 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
+	"sonos-playlist/internal/native/sonos"
 )
 
+type volumeClient interface {
+	GetVolume(ctx context.Context) (int, error)
+	SetVolume(ctx context.Context, volume int) error
+	SetRelativeVolume(ctx context.Context, adjustment int) (int, error)
+}
+
+// newVolumeClient returns a client for the target's coordinator and the
+// coordinator itself, whose room name selects the configured volume cap.
+var newVolumeClient = func(ctx context.Context, flags *rootFlags) (volumeClient, sonos.Member, error) {
+	coord, err := resolveTargetCoordinator(ctx, flags)
+	if err != nil {
+		return nil, sonos.Member{}, err
+	}
+	return newSonosClient(coord.IP, flags.Timeout), coord, nil
+}
+
+// volumeRampSleep waits between ramp steps; tests replace it.
+var volumeRampSleep = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// minRampStep keeps ramps from flooding the speaker with SetVolume calls.
+const minRampStep = 250 * time.Millisecond
+
 func newVolumeCmd(flags *rootFlags) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "volume",
 		Short: "Get or set volume",
-		Long:  "Controls RenderingControl volume on the group coordinator (0-100).",
+		Long:  "Controls RenderingControl volume on the group coordinator (0-100). Changes never exceed the room's maxVolume from `sonos config`.",
+		Example: "  sonos volume set --name Kitchen 25\n" +
+			"  sonos volume up --name Kitchen\n" +
+			"  sonos volume down --name Kitchen 10\n" +
+			"  sonos volume ramp --name Bedroom --to 30 --over 2m",
 	}
 
 	cmd.AddCommand(&cobra.Command{
@@ -19,7 +58,7 @@ func newVolumeCmd(flags *rootFlags) *cobra.Command {
 		Short: "Get volume",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
-			c, err := coordinatorClient(ctx, flags)
+			c, coord, err := newVolumeClient(ctx, flags)
 			if err != nil {
 				return err
 			}
@@ -28,7 +67,7 @@ func newVolumeCmd(flags *rootFlags) *cobra.Command {
 				return err
 			}
 			if isJSON(flags) {
-				return writeJSON(cmd, map[string]any{"volume": v, "coordinatorIP": c.IP})
+				return writeJSON(cmd, map[string]any{"volume": v, "coordinatorIP": coord.IP})
 			}
 			if isTSV(flags) {
 				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "volume\t%d\n", v)
@@ -45,7 +84,7 @@ func newVolumeCmd(flags *rootFlags) *cobra.Command {
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
-			c, err := coordinatorClient(ctx, flags)
+			c, coord, err := newVolumeClient(ctx, flags)
 			if err != nil {
 				return err
 			}
@@ -53,12 +92,146 @@ func newVolumeCmd(flags *rootFlags) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			if err := c.SetVolume(ctx, v); err != nil {
+			applied := flags.MaxVolume.Clamp(volumeRoom(flags, coord), v)
+			if applied < v {
+				writePlainLine(cmd, flags, fmt.Sprintf("Volume capped at %d (maxVolume for %s)", applied, volumeRoom(flags, coord)))
+			}
+			if err := c.SetVolume(ctx, applied); err != nil {
 				return err
 			}
-			return writeOK(cmd, flags, "volume.set", map[string]any{"coordinatorIP": c.IP, "volume": v})
+			return writeOK(cmd, flags, "volume.set", map[string]any{"coordinatorIP": coord.IP, "volume": applied, "capped": applied < v})
 		},
 	})
 
+	cmd.AddCommand(newVolumeStepCmd(flags, "up", 1))
+	cmd.AddCommand(newVolumeStepCmd(flags, "down", -1))
+	cmd.AddCommand(newVolumeRampCmd(flags))
+
 	return cmd
 }
+
+func newVolumeStepCmd(flags *rootFlags, use string, sign int) *cobra.Command {
+	return &cobra.Command{
+		Use:          use + " [step]",
+		Short:        "Turn the volume " + use + " by step (default 5)",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			step := 5
+			if len(args) == 1 {
+				n, err := strconv.Atoi(args[0])
+				if err != nil || n < 1 || n > 100 {
+					return fmt.Errorf("invalid step %q (expected 1-100)", args[0])
+				}
+				step = n
+			}
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, coord, err := newVolumeClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+
+			adjustment := sign * step
+			if limit, ok := flags.MaxVolume.For(volumeRoom(flags, coord)); ok {
+				current, err := c.GetVolume(ctx)
+				if err != nil {
+					return err
+				}
+				target := min(max(current+adjustment, 0), limit)
+				adjustment = target - current
+			}
+			v, err := c.SetRelativeVolume(ctx, adjustment)
+			if err != nil {
+				return err
+			}
+			if isJSON(flags) {
+				return writeOK(cmd, flags, "volume."+use, map[string]any{"coordinatorIP": coord.IP, "volume": v})
+			}
+			if isTSV(flags) {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "volume\t%d\n", v)
+				return nil
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), v)
+			return nil
+		},
+	}
+}
+
+func newVolumeRampCmd(flags *rootFlags) *cobra.Command {
+	var to int
+	var over time.Duration
+	cmd := &cobra.Command{
+		Use:          "ramp",
+		Short:        "Fade gradually to a volume",
+		Long:         "Steps the coordinator's volume to --to over --over, one SetVolume call at a time. The target is capped at the room's maxVolume.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("to") {
+				return errors.New("provide --to")
+			}
+			if to < 0 || to > 100 {
+				return fmt.Errorf("--to must be 0-100: %d", to)
+			}
+			if over < 0 {
+				return fmt.Errorf("--over must not be negative: %s", over)
+			}
+			if err := validateTarget(flags); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, coord, err := newVolumeClient(ctx, flags)
+			if err != nil {
+				return err
+			}
+			target := flags.MaxVolume.Clamp(volumeRoom(flags, coord), to)
+			from, err := c.GetVolume(ctx)
+			if err != nil {
+				return err
+			}
+			if err := rampVolume(ctx, c, from, target, over); err != nil {
+				return err
+			}
+			writePlainLine(cmd, flags, fmt.Sprintf("Volume %d -> %d", from, target))
+			return writeOK(cmd, flags, "volume.ramp", map[string]any{"coordinatorIP": coord.IP, "from": from, "volume": target, "capped": target < to})
+		},
+	}
+	cmd.Flags().IntVar(&to, "to", 0, "Target volume (0-100)")
+	cmd.Flags().DurationVar(&over, "over", 30*time.Second, "How long the fade takes")
+	return cmd
+}
+
+// rampVolume moves from -> to in evenly spaced steps spread over the given
+// duration, ending exactly on to.
+func rampVolume(ctx context.Context, c volumeClient, from, to int, over time.Duration) error {
+	diff := to - from
+	steps := max(diff, -diff)
+	if steps == 0 || over <= 0 {
+		return c.SetVolume(ctx, to)
+	}
+	if over/time.Duration(steps) < minRampStep {
+		steps = max(int(over/minRampStep), 1)
+	}
+	interval := over / time.Duration(steps)
+	for i := 1; i <= steps; i++ {
+		if err := volumeRampSleep(ctx, interval); err != nil {
+			return err
+		}
+		if err := c.SetVolume(ctx, from+diff*i/steps); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// volumeRoom names the room whose maxVolume applies: the coordinator's, or
+// --name when topology was unavailable.
+func volumeRoom(flags *rootFlags, coord sonos.Member) string {
+	if coord.Name != "" {
+		return coord.Name
+	}
+	return flags.Name
+}
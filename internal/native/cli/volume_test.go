@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sonos-playlist/internal/native/appconfig"
+	"sonos-playlist/internal/native/sonos"
+)
+
+type fakeVolumeClient struct {
+	volume int
+	sets   []int
+	adjust []int
+}
+
+func (f *fakeVolumeClient) GetVolume(ctx context.Context) (int, error) {
+	return f.volume, nil
+}
+
+func (f *fakeVolumeClient) SetVolume(ctx context.Context, volume int) error {
+	f.sets = append(f.sets, volume)
+	f.volume = volume
+	return nil
+}
+
+func (f *fakeVolumeClient) SetRelativeVolume(ctx context.Context, adjustment int) (int, error) {
+	f.adjust = append(f.adjust, adjustment)
+	f.volume = min(max(f.volume+adjustment, 0), 100)
+	return f.volume, nil
+}
+
+type fakeGroupAudioClient struct {
+	volume int
+}
+
+func (f *fakeGroupAudioClient) GetGroupVolume(ctx context.Context) (int, error) {
+	return f.volume, nil
+}
+
+func (f *fakeGroupAudioClient) SetGroupVolume(ctx context.Context, volume int) error {
+	f.volume = volume
+	return nil
+}
+
+func (f *fakeGroupAudioClient) GetGroupMute(ctx context.Context) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeGroupAudioClient) SetGroupMute(ctx context.Context, mute bool) error {
+	return nil
+}
+
+func runVolumeCmd(t *testing.T, flags *rootFlags, args ...string) (string, error) {
+	t.Helper()
+	cmd := newVolumeCmd(flags)
+	var out captureWriter
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SilenceErrors = true
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(context.Background())
+	return out.String(), err
+}
+
+func TestVolumeCommandsEnforceMaxVolume(t *testing.T) {
+	flags := &rootFlags{Name: "Kids Room", Timeout: 2 * time.Second, MaxVolume: appconfig.VolumeLimits{"kids room": 40}}
+	fake := &fakeVolumeClient{volume: 30}
+
+	orig := newVolumeClient
+	t.Cleanup(func() { newVolumeClient = orig })
+	newVolumeClient = func(ctx context.Context, flags *rootFlags) (volumeClient, sonos.Member, error) {
+		return fake, sonos.Member{Name: "Kids Room", IP: "192.0.2.10"}, nil
+	}
+
+	out, err := runVolumeCmd(t, flags, "set", "75")
+	if err != nil {
+		t.Fatalf("volume set: %v", err)
+	}
+	if fake.volume != 40 || out != "Volume capped at 40 (maxVolume for Kids Room)\n" {
+		t.Fatalf("volume set: volume=%d output=%q", fake.volume, out)
+	}
+
+	fake.volume = 37
+	if out, err = runVolumeCmd(t, flags, "up"); err != nil || out != "40\n" {
+		t.Fatalf("volume up: %q, %v", out, err)
+	}
+	if out, err = runVolumeCmd(t, flags, "down", "15"); err != nil || out != "25\n" {
+		t.Fatalf("volume down: %q, %v", out, err)
+	}
+	// Set from the Sonos app above the cap: even "up" brings it back down.
+	fake.volume = 60
+	if out, err = runVolumeCmd(t, flags, "up", "2"); err != nil || out != "40\n" {
+		t.Fatalf("volume up above cap: %q, %v", out, err)
+	}
+	if want := []int{3, -15, -20}; len(fake.adjust) != len(want) || fake.adjust[0] != want[0] || fake.adjust[1] != want[1] || fake.adjust[2] != want[2] {
+		t.Fatalf("adjustments = %v, want %v", fake.adjust, want)
+	}
+	for _, step := range []string{"0", "101", "lots"} {
+		if _, err := runVolumeCmd(t, flags, "up", step); err == nil {
+			t.Fatalf("volume up %s: expected error", step)
+		}
+	}
+}
+
+func TestVolumeRampSteps(t *testing.T) {
+	flags := &rootFlags{Name: "Bedroom", Timeout: 2 * time.Second, Format: formatJSON, MaxVolume: appconfig.VolumeLimits{"Bedroom": 30}}
+	fake := &fakeVolumeClient{volume: 10}
+
+	origClient, origSleep := newVolumeClient, volumeRampSleep
+	t.Cleanup(func() { newVolumeClient, volumeRampSleep = origClient, origSleep })
+	newVolumeClient = func(ctx context.Context, flags *rootFlags) (volumeClient, sonos.Member, error) {
+		return fake, sonos.Member{Name: "Bedroom", IP: "192.0.2.11"}, nil
+	}
+	var slept time.Duration
+	volumeRampSleep = func(ctx context.Context, d time.Duration) error {
+		slept += d
+		return nil
+	}
+
+	if _, err := runVolumeCmd(t, flags, "ramp", "--to", "50", "--over", "2m"); err != nil {
+		t.Fatalf("volume ramp: %v", err)
+	}
+	if len(fake.sets) != 20 || fake.sets[0] != 11 || fake.sets[19] != 30 {
+		t.Fatalf("ramp steps = %v", fake.sets)
+	}
+	if slept != 2*time.Minute {
+		t.Fatalf("ramp took %s, want 2m", slept)
+	}
+
+	// Short ramps use fewer, larger steps.
+	fake.sets, slept = nil, 0
+	if _, err := runVolumeCmd(t, flags, "ramp", "--to", "0", "--over", "1s"); err != nil {
+		t.Fatalf("volume ramp down: %v", err)
+	}
+	if len(fake.sets) != 4 || fake.sets[3] != 0 {
+		t.Fatalf("short ramp steps = %v", fake.sets)
+	}
+
+	if _, err := runVolumeCmd(t, flags, "ramp", "--over", "1m"); err == nil {
+		t.Fatal("expected error without --to")
+	}
+}
+
+// scalingGroupClient scales member volumes proportionally, like the speaker
+// does, and records the loudest volume each member reached.
+type scalingGroupClient struct {
+	fakeGroupAudioClient
+	members map[string]*fakeVolumeClient
+	peak    map[string]int
+}
+
+func (f *scalingGroupClient) SetGroupVolume(ctx context.Context, volume int) error {
+	for ip, m := range f.members {
+		if f.volume > 0 {
+			m.volume = m.volume * volume / f.volume
+		} else {
+			m.volume = volume
+		}
+		f.peak[ip] = max(f.peak[ip], m.volume)
+	}
+	f.volume = volume
+	return nil
+}
+
+func TestGroupVolumeSetStaysUnderMemberCaps(t *testing.T) {
+	living := sonos.Member{Name: "Living Room", IP: "192.0.2.20", UUID: "RINCON_LIVING1400", IsVisible: true, IsCoordinator: true}
+	kids := sonos.Member{Name: "Kids Room", IP: "192.0.2.21", UUID: "RINCON_KIDS1400", IsVisible: true}
+	top := sonos.Topology{
+		Groups: []sonos.Group{{ID: "G1", Coordinator: living, Members: []sonos.Member{living, kids}}},
+		ByName: map[string]sonos.Member{living.Name: living, kids.Name: kids},
+	}
+	members := map[string]*fakeVolumeClient{living.IP: {volume: 40}, kids.IP: {volume: 30}}
+	group := &scalingGroupClient{fakeGroupAudioClient: fakeGroupAudioClient{volume: 35}, members: members, peak: map[string]int{}}
+	flags := &rootFlags{Name: "Living Room", Timeout: 2 * time.Second, MaxVolume: appconfig.VolumeLimits{"Kids Room": 35}}
+
+	origAudio, origTop, origMember := newGroupAudioClient, newTopologyGetter, newMemberVolumeClient
+	t.Cleanup(func() { newGroupAudioClient, newTopologyGetter, newMemberVolumeClient = origAudio, origTop, origMember })
+	newGroupAudioClient = func(ctx context.Context, flags *rootFlags) (groupAudioClient, error) {
+		return group, nil
+	}
+	newTopologyGetter = func(ctx context.Context, timeout time.Duration) (topologyGetter, error) {
+		return &fakeTopologyGetter{top: top}, nil
+	}
+	newMemberVolumeClient = func(ip string, timeout time.Duration) volumeClient { return members[ip] }
+
+	cmd := newGroupVolumeCmd(flags)
+	cmd.SetOut(newDiscardWriter())
+	cmd.SetErr(newDiscardWriter())
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{"set", "70"})
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("group volume set: %v", err)
+	}
+	// Kids Room may rise from 30 to 35, so the group stops at 35*35/30 = 40.
+	if group.volume != 40 {
+		t.Fatalf("group volume = %d, want 40", group.volume)
+	}
+	if group.peak[kids.IP] > 35 || len(members[kids.IP].sets) != 0 {
+		t.Fatalf("kids room went above its cap: peak=%d sets=%v", group.peak[kids.IP], members[kids.IP].sets)
+	}
+	if members[living.IP].volume != 45 {
+		t.Fatalf("living room volume = %d, want 45", members[living.IP].volume)
+	}
+}
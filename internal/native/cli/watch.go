@@ -2,16 +2,11 @@ package cli
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"io"
-	"net"
-	"net/http"
 	"os"
 	"os/signal"
 	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -26,19 +21,6 @@ type watchEvent struct {
 	Vars    map[string]string `json:"vars"`
 }
 
-func listenIPForRemote(remoteIP string) (string, error) {
-	conn, err := net.Dial("udp", net.JoinHostPort(remoteIP, "1900"))
-	if err != nil {
-		return "", err
-	}
-	defer conn.Close()
-	udpAddr, ok := conn.LocalAddr().(*net.UDPAddr)
-	if !ok || udpAddr.IP == nil {
-		return "", errors.New("could not determine local listen ip")
-	}
-	return udpAddr.IP.String(), nil
-}
-
 func newWatchCmd(flags *rootFlags) *cobra.Command {
 	var duration time.Duration
 
@@ -66,78 +48,27 @@ func newWatchCmd(flags *rootFlags) *cobra.Command {
 				return err
 			}
 
-			listenIP, err := listenIPForRemote(c.IP)
+			listener, err := sonos.NewEventListener(c.IP)
 			if err != nil {
 				return err
 			}
-			ln, err := net.Listen("tcp", net.JoinHostPort(listenIP, "0"))
-			if err != nil {
-				return err
-			}
-			defer ln.Close()
-			port := ln.Addr().(*net.TCPAddr).Port
-
-			callbackURL := fmt.Sprintf("http://%s:%d/notify", listenIP, port)
-
-			events := make(chan watchEvent, 128)
-			var sidToService sync.Map // sid -> service name
-
-			mux := http.NewServeMux()
-			mux.HandleFunc("/notify", func(w http.ResponseWriter, r *http.Request) {
-				if r.Method != "NOTIFY" {
-					w.WriteHeader(http.StatusMethodNotAllowed)
-					return
-				}
-				sid := strings.TrimSpace(r.Header.Get("SID"))
-				seq := strings.TrimSpace(r.Header.Get("SEQ"))
-				body, _ := io.ReadAll(r.Body)
-				_ = r.Body.Close()
-
-				service := "unknown"
-				if v, ok := sidToService.Load(sid); ok {
-					service = v.(string)
-				}
-
-				vars, err := sonos.ParseEvent(body)
-				if err != nil {
-					vars = map[string]string{"parse_error": err.Error()}
-				}
-
-				select {
-				case events <- watchEvent{
-					Time:    time.Now().UTC(),
-					Service: service,
-					SID:     sid,
-					Seq:     seq,
-					Vars:    vars,
-				}:
-				default:
-					// Drop if the consumer is too slow.
-				}
-
-				w.WriteHeader(http.StatusOK)
-			})
-
-			srv := &http.Server{
-				Handler:           mux,
-				ReadHeaderTimeout: 5 * time.Second,
-			}
-			go func() { _ = srv.Serve(ln) }()
-			defer func() { _ = srv.Shutdown(context.Background()) }()
+			defer func() { _ = listener.Close() }()
+			callbackURL := listener.CallbackURL()
+			sidToService := map[string]string{}
 
 			avtSub, err := c.SubscribeAVTransport(ctx, callbackURL, 0)
 			if err != nil {
 				return err
 			}
 			defer func() { _ = c.Unsubscribe(context.Background(), avtSub) }()
-			sidToService.Store(avtSub.SID, "avtransport")
+			sidToService[avtSub.SID] = "avtransport"
 
 			rcSub, err := c.SubscribeRenderingControl(ctx, callbackURL, 0)
 			if err != nil {
 				return err
 			}
 			defer func() { _ = c.Unsubscribe(context.Background(), rcSub) }()
-			sidToService.Store(rcSub.SID, "renderingcontrol")
+			sidToService[rcSub.SID] = "renderingcontrol"
 
 			if !isJSON(flags) && !isTSV(flags) {
 				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Watching events (callback %s). Press Ctrl+C to stop.\n", callbackURL)
@@ -147,7 +78,12 @@ func newWatchCmd(flags *rootFlags) *cobra.Command {
 				select {
 				case <-ctx.Done():
 					return nil
-				case ev := <-events:
+				case e := <-listener.Events():
+					service, ok := sidToService[e.SID]
+					if !ok {
+						service = "unknown"
+					}
+					ev := watchEvent{Time: e.Time, Service: service, SID: e.SID, Seq: e.Seq, Vars: e.Vars}
 					if isJSON(flags) {
 						_ = writeJSONLine(cmd, ev)
 						continue
@@ -1,6 +1,10 @@
 package scenes
 
-import "time"
+import (
+	"time"
+
+	"sonos-playlist/internal/native/sonos"
+)
 
 type Scene struct {
 	Name      string        `json:"name"`
@@ -22,6 +26,8 @@ type SceneDevice struct {
 	IP     string `json:"ip,omitempty"`
 	Volume int    `json:"volume"`
 	Mute   bool   `json:"mute"`
+	// Tone and EQ; absent in scenes saved before these were captured.
+	sonos.AudioSettings
 }
 
 type SceneMeta struct {
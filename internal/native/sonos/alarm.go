@@ -0,0 +1,207 @@
+package sonos
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AlarmChimeURI is the built-in Sonos chime, used when an alarm has nothing
+// else to play.
+const AlarmChimeURI = "x-rincon-buzzer:0"
+
+// Alarm is one entry from the household-wide AlarmClock service.
+// StartTime and Duration are HH:MM:SS strings in the speakers' local time.
+type Alarm struct {
+	ID                 int    `json:"id"`
+	StartTime          string `json:"startTime"`
+	Duration           string `json:"duration"`
+	Recurrence         string `json:"recurrence"`
+	Enabled            bool   `json:"enabled"`
+	RoomUUID           string `json:"roomUUID"`
+	ProgramURI         string `json:"programURI"`
+	ProgramMetaData    string `json:"programMetaData,omitempty"`
+	PlayMode           string `json:"playMode"`
+	Volume             int    `json:"volume"`
+	IncludeLinkedZones bool   `json:"includeLinkedZones"`
+}
+
+// ParseRecurrence normalizes an alarm recurrence: ONCE, DAILY, WEEKDAYS,
+// WEEKENDS, or ON_ followed by day digits (0 = Sunday ... 6 = Saturday).
+func ParseRecurrence(s string) (string, error) {
+	r := strings.ToUpper(strings.TrimSpace(s))
+	switch r {
+	case "ONCE", "DAILY", "WEEKDAYS", "WEEKENDS":
+		return r, nil
+	}
+	days, ok := strings.CutPrefix(r, "ON_")
+	if !ok || days == "" {
+		return "", fmt.Errorf("invalid recurrence %q (expected ONCE|DAILY|WEEKDAYS|WEEKENDS|ON_<days 0-6>)", s)
+	}
+	seen := map[rune]bool{}
+	for _, d := range days {
+		if d < '0' || d > '6' {
+			return "", fmt.Errorf("invalid recurrence %q: days must be digits 0 (Sunday) to 6 (Saturday)", s)
+		}
+		seen[d] = true
+	}
+	digits := make([]string, 0, len(seen))
+	for d := range seen {
+		digits = append(digits, string(d))
+	}
+	sort.Strings(digits)
+	return "ON_" + strings.Join(digits, ""), nil
+}
+
+// ParseAlarmTime reads a clock time such as 7:30 or 07:30:00 and returns
+// it as HH:MM:SS.
+func ParseAlarmTime(s string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return "", fmt.Errorf("invalid time %q (expected HH:MM or HH:MM:SS)", s)
+	}
+	limits := []int{23, 59, 59}
+	vals := []int{0, 0, 0}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || n > limits[i] {
+			return "", fmt.Errorf("invalid time %q (expected HH:MM or HH:MM:SS)", s)
+		}
+		vals[i] = n
+	}
+	return fmt.Sprintf("%02d:%02d:%02d", vals[0], vals[1], vals[2]), nil
+}
+
+// FormatAlarmDuration formats d as the HH:MM:SS value alarms expect.
+func FormatAlarmDuration(d time.Duration) (string, error) {
+	if d < time.Minute || d > MaxSleepTimer {
+		return "", fmt.Errorf("alarm duration must be between 1m and 24h: %s", d)
+	}
+	return formatHMS(d), nil
+}
+
+type alarmListXML struct {
+	Alarms []struct {
+		ID                 string `xml:"ID,attr"`
+		StartTime          string `xml:"StartTime,attr"`
+		StartLocalTime     string `xml:"StartLocalTime,attr"`
+		Duration           string `xml:"Duration,attr"`
+		Recurrence         string `xml:"Recurrence,attr"`
+		Enabled            string `xml:"Enabled,attr"`
+		RoomUUID           string `xml:"RoomUUID,attr"`
+		ProgramURI         string `xml:"ProgramURI,attr"`
+		ProgramMetaData    string `xml:"ProgramMetaData,attr"`
+		PlayMode           string `xml:"PlayMode,attr"`
+		Volume             string `xml:"Volume,attr"`
+		IncludeLinkedZones string `xml:"IncludeLinkedZones,attr"`
+	} `xml:"Alarm"`
+}
+
+// ListAlarms returns every alarm in the household, ordered by ID. Any
+// speaker can answer; the list is shared.
+func (c *Client) ListAlarms(ctx context.Context) ([]Alarm, error) {
+	resp, err := c.soapCall(ctx, controlAlarmClock, urnAlarmClock, "ListAlarms", nil)
+	if err != nil {
+		return nil, err
+	}
+	return parseAlarmList(resp["CurrentAlarmList"])
+}
+
+func parseAlarmList(payload string) ([]Alarm, error) {
+	if strings.TrimSpace(payload) == "" {
+		return nil, nil
+	}
+	var doc alarmListXML
+	if err := xml.Unmarshal([]byte(payload), &doc); err != nil {
+		return nil, fmt.Errorf("parse alarm list: %w", err)
+	}
+	alarms := make([]Alarm, 0, len(doc.Alarms))
+	for _, a := range doc.Alarms {
+		id, err := strconv.Atoi(a.ID)
+		if err != nil {
+			return nil, fmt.Errorf("parse alarm list: invalid ID %q", a.ID)
+		}
+		start := a.StartTime
+		if start == "" {
+			start = a.StartLocalTime
+		}
+		vol, _ := strconv.Atoi(a.Volume)
+		alarms = append(alarms, Alarm{
+			ID:                 id,
+			StartTime:          start,
+			Duration:           a.Duration,
+			Recurrence:         a.Recurrence,
+			Enabled:            a.Enabled == "1",
+			RoomUUID:           a.RoomUUID,
+			ProgramURI:         a.ProgramURI,
+			ProgramMetaData:    a.ProgramMetaData,
+			PlayMode:           a.PlayMode,
+			Volume:             vol,
+			IncludeLinkedZones: a.IncludeLinkedZones == "1",
+		})
+	}
+	sort.Slice(alarms, func(i, j int) bool { return alarms[i].ID < alarms[j].ID })
+	return alarms, nil
+}
+
+// CreateAlarm adds a and returns the ID the household assigned to it.
+func (c *Client) CreateAlarm(ctx context.Context, a Alarm) (int, error) {
+	resp, err := c.soapCall(ctx, controlAlarmClock, urnAlarmClock, "CreateAlarm", alarmArgs(a))
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.Atoi(strings.TrimSpace(resp["AssignedID"]))
+	if err != nil {
+		return 0, fmt.Errorf("CreateAlarm: invalid AssignedID %q", resp["AssignedID"])
+	}
+	return id, nil
+}
+
+// UpdateAlarm replaces every setting of the alarm with a.ID.
+func (c *Client) UpdateAlarm(ctx context.Context, a Alarm) error {
+	args := alarmArgs(a)
+	args["ID"] = strconv.Itoa(a.ID)
+	_, err := c.soapCall(ctx, controlAlarmClock, urnAlarmClock, "UpdateAlarm", args)
+	return err
+}
+
+func (c *Client) DestroyAlarm(ctx context.Context, id int) error {
+	_, err := c.soapCall(ctx, controlAlarmClock, urnAlarmClock, "DestroyAlarm", map[string]string{
+		"ID": strconv.Itoa(id),
+	})
+	return err
+}
+
+func alarmArgs(a Alarm) map[string]string {
+	uri := a.ProgramURI
+	if uri == "" {
+		uri = AlarmChimeURI
+	}
+	mode := a.PlayMode
+	if mode == "" {
+		mode = "NORMAL"
+	}
+	return map[string]string{
+		"StartLocalTime":     a.StartTime,
+		"Duration":           a.Duration,
+		"Recurrence":         a.Recurrence,
+		"Enabled":            boolArg(a.Enabled),
+		"RoomUUID":           a.RoomUUID,
+		"ProgramURI":         uri,
+		"ProgramMetaData":    a.ProgramMetaData,
+		"PlayMode":           mode,
+		"Volume":             strconv.Itoa(a.Volume),
+		"IncludeLinkedZones": boolArg(a.IncludeLinkedZones),
+	}
+}
+
+func boolArg(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,126 @@
+package sonos
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"daily":    "DAILY",
+		"Weekdays": "WEEKDAYS",
+		"ONCE":     "ONCE",
+		"on_3210":  "ON_0123",
+		"ON_155":   "ON_15",
+	}
+	for in, want := range cases {
+		if got, err := ParseRecurrence(in); err != nil || got != want {
+			t.Fatalf("ParseRecurrence(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "HOURLY", "ON_", "ON_7", "ON_1a"} {
+		if _, err := ParseRecurrence(in); err == nil {
+			t.Fatalf("ParseRecurrence(%q): expected error", in)
+		}
+	}
+}
+
+func TestParseAlarmTime(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"7:30":     "07:30:00",
+		"06:45:10": "06:45:10",
+		"23:59":    "23:59:00",
+	}
+	for in, want := range cases {
+		if got, err := ParseAlarmTime(in); err != nil || got != want {
+			t.Fatalf("ParseAlarmTime(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"7", "24:00", "7:60", "seven:30"} {
+		if _, err := ParseAlarmTime(in); err == nil {
+			t.Fatalf("ParseAlarmTime(%q): expected error", in)
+		}
+	}
+}
+
+func TestAlarmClockCalls(t *testing.T) {
+	t.Parallel()
+
+	const list = `&lt;Alarms&gt;` +
+		`&lt;Alarm ID="12" StartTime="07:00:00" Duration="01:00:00" Recurrence="WEEKDAYS" Enabled="1" RoomUUID="RINCON_KIDS1400" ProgramURI="x-rincon-buzzer:0" ProgramMetaData="" PlayMode="NORMAL" Volume="15" IncludeLinkedZones="0"/&gt;` +
+		`&lt;Alarm ID="3" StartLocalTime="06:30:00" Duration="00:30:00" Recurrence="ON_06" Enabled="0" RoomUUID="RINCON_BED1400" ProgramURI="x-sonosapi-stream:s1234?sid=254" ProgramMetaData="" PlayMode="SHUFFLE_NOREPEAT" Volume="20" IncludeLinkedZones="1"/&gt;` +
+		`&lt;/Alarms&gt;`
+
+	var bodies []string
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path != "/AlarmClock/Control" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		action := r.Header.Get("SOAPACTION")
+		switch {
+		case strings.Contains(action, "#ListAlarms"):
+			return httpResponse(200, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><u:ListAlarmsResponse xmlns:u="urn:schemas-upnp-org:service:AlarmClock:1"><CurrentAlarmList>`+list+`</CurrentAlarmList><CurrentAlarmListVersion>RINCON_1:7</CurrentAlarmListVersion></u:ListAlarmsResponse></s:Body></s:Envelope>`), nil
+		case strings.Contains(action, "#CreateAlarm"):
+			return httpResponse(200, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><u:CreateAlarmResponse xmlns:u="urn:schemas-upnp-org:service:AlarmClock:1"><AssignedID>13</AssignedID></u:CreateAlarmResponse></s:Body></s:Envelope>`), nil
+		case strings.Contains(action, "#UpdateAlarm"), strings.Contains(action, "#DestroyAlarm"):
+			return httpResponse(200, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body></s:Body></s:Envelope>`), nil
+		default:
+			t.Fatalf("unexpected SOAPACTION: %q", action)
+			return nil, nil
+		}
+	})
+	c := &Client{IP: "192.0.2.1", HTTP: &http.Client{Timeout: time.Second, Transport: rt}}
+	ctx := context.Background()
+
+	alarms, err := c.ListAlarms(ctx)
+	if err != nil {
+		t.Fatalf("ListAlarms: %v", err)
+	}
+	if len(alarms) != 2 || alarms[0].ID != 3 || alarms[1].ID != 12 {
+		t.Fatalf("alarms not sorted by ID: %+v", alarms)
+	}
+	if a := alarms[0]; a.StartTime != "06:30:00" || a.Enabled || !a.IncludeLinkedZones || a.Volume != 20 || a.Recurrence != "ON_06" {
+		t.Fatalf("unexpected alarm: %+v", a)
+	}
+
+	id, err := c.CreateAlarm(ctx, Alarm{StartTime: "07:15:00", Duration: "00:45:00", Recurrence: "DAILY", Enabled: true, RoomUUID: "RINCON_KIDS1400", Volume: 12})
+	if err != nil || id != 13 {
+		t.Fatalf("CreateAlarm = %d, %v", id, err)
+	}
+	for _, want := range []string{
+		"<StartLocalTime>07:15:00</StartLocalTime>",
+		"<Enabled>1</Enabled>",
+		"<ProgramURI>x-rincon-buzzer:0</ProgramURI>",
+		"<PlayMode>NORMAL</PlayMode>",
+		"<IncludeLinkedZones>0</IncludeLinkedZones>",
+	} {
+		if !strings.Contains(bodies[1], want) {
+			t.Fatalf("CreateAlarm body missing %s: %s", want, bodies[1])
+		}
+	}
+
+	a := alarms[1]
+	a.Enabled = false
+	if err := c.UpdateAlarm(ctx, a); err != nil {
+		t.Fatalf("UpdateAlarm: %v", err)
+	}
+	if !strings.Contains(bodies[2], "<ID>12</ID>") || !strings.Contains(bodies[2], "<Enabled>0</Enabled>") {
+		t.Fatalf("UpdateAlarm body: %s", bodies[2])
+	}
+	if err := c.DestroyAlarm(ctx, 3); err != nil {
+		t.Fatalf("DestroyAlarm: %v", err)
+	}
+	if !strings.Contains(bodies[3], "<ID>3</ID>") {
+		t.Fatalf("DestroyAlarm body: %s", bodies[3])
+	}
+}
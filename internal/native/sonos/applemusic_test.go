@@ -0,0 +1,85 @@
+package sonos
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppleMusicTrackURIAndDIDL(t *testing.T) {
+	t.Parallel()
+
+	if got, want := AppleMusicTrackURI(1440857781, 3), "x-sonos-http:song%3a1440857781.mp4?sid=204&flags=8224&sn=3"; got != want {
+		t.Fatalf("uri = %q, want %q", got, want)
+	}
+	meta := AppleMusicTrackDIDL(AppleMusicTrack{ID: 1440857781, Title: "Rock & Roll", Artist: "The Velvet Underground"})
+	for _, want := range []string{
+		`<item id="10032020song%3a1440857781"`,
+		"<dc:title>Rock &amp; Roll</dc:title>",
+		"SA_RINCON52231_X_#Svc52231-0-Token",
+	} {
+		if !strings.Contains(meta, want) {
+			t.Fatalf("metadata missing %q: %s", want, meta)
+		}
+	}
+	items, err := ParseDIDLItems(meta)
+	if err != nil || len(items) != 1 || items[0].Artist != "The Velvet Underground" {
+		t.Fatalf("metadata does not parse back: %v %+v", err, items)
+	}
+}
+
+func TestServiceTrackURI(t *testing.T) {
+	t.Parallel()
+
+	if got, want := ServiceTrackURI(2, 1, "tr:12345", "audio/mpeg"), "x-sonos-http:tr%3a12345.mp3?sid=2&flags=8224&sn=1"; got != want {
+		t.Fatalf("deezer uri = %q, want %q", got, want)
+	}
+	if got, want := ServiceTrackURI(12, 5, "spotify:track:abc", ""), "x-sonos-spotify:spotify%3atrack%3aabc?sid=12&flags=8224&sn=5"; got != want {
+		t.Fatalf("spotify uri = %q, want %q", got, want)
+	}
+	if meta := ServiceTrackDIDL(12, "spotify:track:abc", "T", "A", ""); !strings.Contains(meta, "SA_RINCON3079_X_#Svc3079-0-Token") {
+		t.Fatalf("spotify metadata: %s", meta)
+	}
+}
+
+func TestParseAccountSerial(t *testing.T) {
+	t.Parallel()
+
+	body := `<ZPSupportInfo><Accounts LastUpdateDevice="RINCON_X" Version="9" NextSerialNum="5">
+<Account Type="2311" SerialNum="1" Deleted="0"><UN>x</UN></Account>
+<Account Type="52231" SerialNum="2" Deleted="1"><UN>old</UN></Account>
+<Account Type="52231" SerialNum="4" Deleted="0"><UN>new</UN></Account>
+</Accounts></ZPSupportInfo>`
+	n, err := parseAccountSerial([]byte(body), ServiceAccountType(AppleMusicServiceID))
+	if err != nil || n != 4 {
+		t.Fatalf("serial = %d, %v; want 4", n, err)
+	}
+	if _, err := parseAccountSerial([]byte(`<ZPSupportInfo><Accounts/></ZPSupportInfo>`), 52231); err == nil {
+		t.Fatalf("expected an error without an Apple Music account")
+	}
+}
+
+func TestEnqueueAppleMusic(t *testing.T) {
+	t.Parallel()
+
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		action := r.Header.Get("SOAPACTION")
+		if !strings.Contains(action, "#AddURIToQueue") {
+			t.Fatalf("unexpected SOAPACTION: %q", action)
+		}
+		b, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(b), "<EnqueuedURI>x-sonos-http:song%3a42.mp4?sid=204&amp;flags=8224&amp;sn=2</EnqueuedURI>") {
+			t.Fatalf("unexpected body: %s", b)
+		}
+		return httpResponse(200, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><u:AddURIToQueueResponse xmlns:u="urn:schemas-upnp-org:service:AVTransport:1"><FirstTrackNumberEnqueued>9</FirstTrackNumberEnqueued></u:AddURIToQueueResponse></s:Body></s:Envelope>`), nil
+	})
+	c := &Client{IP: "192.0.2.1", HTTP: &http.Client{Timeout: time.Second, Transport: rt}}
+
+	first, err := c.EnqueueAppleMusic(context.Background(), AppleMusicTrack{ID: 42, Title: "Song"}, 2, EnqueueOptions{})
+	if err != nil || first != 9 {
+		t.Fatalf("EnqueueAppleMusic = %d, %v; want 9", first, err)
+	}
+}
@@ -130,6 +130,19 @@ func (c *Client) RemoveTrackFromQueue(ctx context.Context, oneBasedTrackNumber i
 	return err
 }
 
+// ReorderTracksInQueue moves count tracks starting at the 1-based position
+// start so they sit before the track currently at insertBefore.
+func (c *Client) ReorderTracksInQueue(ctx context.Context, start, count, insertBefore int) error {
+	_, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "ReorderTracksInQueue", map[string]string{
+		"InstanceID":     "0",
+		"StartingIndex":  strconv.Itoa(start),
+		"NumberOfTracks": strconv.Itoa(count),
+		"InsertBefore":   strconv.Itoa(insertBefore),
+		"UpdateID":       "0",
+	})
+	return err
+}
+
 func (c *Client) AddURIToQueue(ctx context.Context, enqueuedURI, enqueuedMeta string, desiredFirstTrackNumber int, enqueueAsNext bool) (firstTrackNumber int, err error) {
 	asNext := "0"
 	if enqueueAsNext {
@@ -0,0 +1,40 @@
+package sonos
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReorderTracksInQueue(t *testing.T) {
+	t.Parallel()
+
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		action := r.Header.Get("SOAPACTION")
+		if !strings.Contains(action, "#ReorderTracksInQueue") {
+			t.Fatalf("unexpected SOAPACTION: %q", action)
+		}
+		b, _ := io.ReadAll(r.Body)
+		body := string(b)
+		for _, want := range []string{"<StartingIndex>12</StartingIndex>", "<NumberOfTracks>1</NumberOfTracks>", "<InsertBefore>6</InsertBefore>"} {
+			if !strings.Contains(body, want) {
+				t.Fatalf("body missing %s: %s", want, body)
+			}
+		}
+		return httpResponse(200, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><u:ReorderTracksInQueueResponse xmlns:u="urn:schemas-upnp-org:service:AVTransport:1"></u:ReorderTracksInQueueResponse></s:Body></s:Envelope>`), nil
+	})
+
+	c := &Client{
+		IP: "192.0.2.1",
+		HTTP: &http.Client{
+			Timeout:   time.Second,
+			Transport: rt,
+		},
+	}
+	if err := c.ReorderTracksInQueue(context.Background(), 12, 1, 6); err != nil {
+		t.Fatalf("ReorderTracksInQueue: %v", err)
+	}
+}
@@ -113,3 +113,19 @@ func parseDIDLItem(dec *xml.Decoder, start xml.StartElement) (DIDLItem, error) {
 		}
 	}
 }
+
+// BuildItemDIDL renders an item back to DIDL-Lite metadata suitable for
+// AddURIToQueue, e.g. for music library tracks found with Browse.
+func BuildItemDIDL(it DIDLItem) string {
+	class := it.Class
+	if class == "" {
+		class = "object.item.audioItem.musicTrack"
+	}
+	return `<DIDL-Lite xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/" xmlns:r="urn:schemas-rinconnetworks-com:metadata-1-0/" xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/">` +
+		`<item id="` + xmlEscapeText(it.ID) + `" parentID="-1" restricted="true">` +
+		`<dc:title>` + xmlEscapeText(it.Title) + `</dc:title>` +
+		`<dc:creator>` + xmlEscapeText(it.Artist) + `</dc:creator>` +
+		`<upnp:album>` + xmlEscapeText(it.Album) + `</upnp:album>` +
+		`<upnp:class>` + xmlEscapeText(class) + `</upnp:class>` +
+		`</item></DIDL-Lite>`
+}
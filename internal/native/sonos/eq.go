@@ -0,0 +1,180 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Bass and treble range.
+const (
+	MinToneLevel = -10
+	MaxToneLevel = 10
+)
+
+// EQType names a Sonos-specific RenderingControl GetEQ/SetEQ setting. Most
+// of them only exist on home-theater speakers or when a sub or surrounds
+// are bonded.
+type EQType string
+
+const (
+	EQNightMode          EQType = "NightMode"
+	EQDialogLevel        EQType = "DialogLevel"
+	EQSubGain            EQType = "SubGain"
+	EQSurroundLevel      EQType = "SurroundLevel"
+	EQHeightChannelLevel EQType = "HeightChannelLevel"
+)
+
+// EQTypes lists the supported EQ types in display order.
+var EQTypes = []EQType{EQNightMode, EQDialogLevel, EQSubGain, EQSurroundLevel, EQHeightChannelLevel}
+
+// Range returns the values t accepts; on/off types are 0-1.
+func (t EQType) Range() (lo, hi int) {
+	switch t {
+	case EQSubGain, EQSurroundLevel:
+		return -15, 15
+	case EQHeightChannelLevel:
+		return -10, 10
+	default:
+		return 0, 1
+	}
+}
+
+func (c *Client) GetEQ(ctx context.Context, eqType EQType) (int, error) {
+	resp, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "GetEQ", map[string]string{
+		"InstanceID": "0",
+		"EQType":     string(eqType),
+	})
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.Atoi(strings.TrimSpace(resp["CurrentValue"]))
+	if err != nil {
+		return 0, fmt.Errorf("GetEQ %s: invalid value %q", eqType, resp["CurrentValue"])
+	}
+	return v, nil
+}
+
+func (c *Client) SetEQ(ctx context.Context, eqType EQType, value int) error {
+	lo, hi := eqType.Range()
+	if value < lo || value > hi {
+		return fmt.Errorf("%s must be between %d and %d: %d", eqType, lo, hi, value)
+	}
+	_, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "SetEQ", map[string]string{
+		"InstanceID":   "0",
+		"EQType":       string(eqType),
+		"DesiredValue": strconv.Itoa(value),
+	})
+	return err
+}
+
+// AudioSettings holds a speaker's tone and EQ settings. Nil fields are not
+// supported by the speaker (when read) or left alone (when applied).
+type AudioSettings struct {
+	Bass               *int  `json:"bass,omitempty"`
+	Treble             *int  `json:"treble,omitempty"`
+	Loudness           *bool `json:"loudness,omitempty"`
+	NightMode          *bool `json:"nightMode,omitempty"`
+	DialogLevel        *bool `json:"dialogLevel,omitempty"`
+	SubGain            *int  `json:"subGain,omitempty"`
+	SurroundLevel      *int  `json:"surroundLevel,omitempty"`
+	HeightChannelLevel *int  `json:"heightChannelLevel,omitempty"`
+}
+
+// IsZero reports whether no setting is present.
+func (s AudioSettings) IsZero() bool {
+	return s == AudioSettings{}
+}
+
+// GetAudioSettings reads bass, treble, loudness, and every EQ type the
+// speaker supports. EQ types the speaker rejects are left nil.
+func (c *Client) GetAudioSettings(ctx context.Context) (AudioSettings, error) {
+	var s AudioSettings
+	bass, err := c.GetBass(ctx)
+	if err != nil {
+		return AudioSettings{}, err
+	}
+	treble, err := c.GetTreble(ctx)
+	if err != nil {
+		return AudioSettings{}, err
+	}
+	loudness, err := c.GetLoudness(ctx)
+	if err != nil {
+		return AudioSettings{}, err
+	}
+	s.Bass, s.Treble, s.Loudness = &bass, &treble, &loudness
+
+	for _, t := range EQTypes {
+		v, err := c.GetEQ(ctx, t)
+		if err != nil {
+			var upnpErr *UPnPError
+			if errors.As(err, &upnpErr) {
+				continue
+			}
+			return AudioSettings{}, err
+		}
+		switch t {
+		case EQNightMode:
+			on := v != 0
+			s.NightMode = &on
+		case EQDialogLevel:
+			on := v != 0
+			s.DialogLevel = &on
+		case EQSubGain:
+			s.SubGain = &v
+		case EQSurroundLevel:
+			s.SurroundLevel = &v
+		case EQHeightChannelLevel:
+			s.HeightChannelLevel = &v
+		}
+	}
+	return s, nil
+}
+
+// SetAudioSettings applies every non-nil field of s. It keeps going when a
+// setting fails (e.g. SubGain after the sub was unbonded) and returns the
+// joined errors.
+func (c *Client) SetAudioSettings(ctx context.Context, s AudioSettings) error {
+	var errs []error
+	if s.Bass != nil {
+		errs = append(errs, c.SetBass(ctx, *s.Bass))
+	}
+	if s.Treble != nil {
+		errs = append(errs, c.SetTreble(ctx, *s.Treble))
+	}
+	if s.Loudness != nil {
+		errs = append(errs, c.SetLoudness(ctx, *s.Loudness))
+	}
+	eq := []struct {
+		t EQType
+		v *int
+	}{
+		{EQNightMode, boolInt(s.NightMode)},
+		{EQDialogLevel, boolInt(s.DialogLevel)},
+		{EQSubGain, s.SubGain},
+		{EQSurroundLevel, s.SurroundLevel},
+		{EQHeightChannelLevel, s.HeightChannelLevel},
+	}
+	for _, e := range eq {
+		if e.v == nil {
+			continue
+		}
+		if err := c.SetEQ(ctx, e.t, *e.v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.t, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func boolInt(b *bool) *int {
+	if b == nil {
+		return nil
+	}
+	v := 0
+	if *b {
+		v = 1
+	}
+	return &v
+}
@@ -0,0 +1,94 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetAudioSettingsSkipsUnsupportedEQ(t *testing.T) {
+	t.Parallel()
+
+	eqValues := map[string]string{"NightMode": "1", "DialogLevel": "0", "SubGain": "-3"}
+	eqTypeRE := regexp.MustCompile(`<EQType>([^<]+)</EQType>`)
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(r.Body)
+		action := r.Header.Get("SOAPACTION")
+		respond := func(name, field, value string) (*http.Response, error) {
+			return httpResponse(200, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><u:`+name+`Response xmlns:u="urn:schemas-upnp-org:service:RenderingControl:1"><`+field+`>`+value+`</`+field+`></u:`+name+`Response></s:Body></s:Envelope>`), nil
+		}
+		switch {
+		case strings.Contains(action, "#GetBass"):
+			return respond("GetBass", "CurrentBass", "4")
+		case strings.Contains(action, "#GetTreble"):
+			return respond("GetTreble", "CurrentTreble", "-2")
+		case strings.Contains(action, "#GetLoudness"):
+			return respond("GetLoudness", "CurrentLoudness", "1")
+		case strings.Contains(action, "#GetEQ"):
+			m := eqTypeRE.FindStringSubmatch(string(b))
+			if v, ok := eqValues[m[1]]; ok {
+				return respond("GetEQ", "CurrentValue", v)
+			}
+			return httpResponse(500, soapFaultWithUPnPCode("402")), nil
+		default:
+			t.Fatalf("unexpected SOAPACTION: %q", action)
+			return nil, nil
+		}
+	})
+	c := &Client{IP: "192.0.2.1", HTTP: &http.Client{Timeout: time.Second, Transport: rt}}
+
+	s, err := c.GetAudioSettings(context.Background())
+	if err != nil {
+		t.Fatalf("GetAudioSettings: %v", err)
+	}
+	if *s.Bass != 4 || *s.Treble != -2 || !*s.Loudness || !*s.NightMode || *s.DialogLevel || *s.SubGain != -3 {
+		t.Fatalf("unexpected settings: %+v", s)
+	}
+	if s.SurroundLevel != nil || s.HeightChannelLevel != nil {
+		t.Fatalf("unsupported EQ types should be nil: %+v", s)
+	}
+}
+
+func TestSetAudioSettingsAppliesPresentFields(t *testing.T) {
+	t.Parallel()
+
+	var bodies []string
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if strings.Contains(string(b), "<EQType>SurroundLevel</EQType>") {
+			return httpResponse(500, soapFaultWithUPnPCode("402")), nil
+		}
+		return httpResponse(200, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body></s:Body></s:Envelope>`), nil
+	})
+	c := &Client{IP: "192.0.2.1", HTTP: &http.Client{Timeout: time.Second, Transport: rt}}
+
+	bass, night, surround, height := 12, true, 5, -4
+	err := c.SetAudioSettings(context.Background(), AudioSettings{Bass: &bass, NightMode: &night, SurroundLevel: &surround, HeightChannelLevel: &height})
+	var upnpErr *UPnPError
+	if !errors.As(err, &upnpErr) || !strings.Contains(err.Error(), "SurroundLevel") {
+		t.Fatalf("expected SurroundLevel error, got %v", err)
+	}
+	if len(bodies) != 4 {
+		t.Fatalf("expected 4 requests, got %d", len(bodies))
+	}
+	for i, want := range []string{
+		"<DesiredBass>10</DesiredBass>",
+		"<DesiredValue>1</DesiredValue><EQType>NightMode</EQType>",
+		"<EQType>SurroundLevel</EQType>",
+		"<DesiredValue>-4</DesiredValue><EQType>HeightChannelLevel</EQType>",
+	} {
+		if !strings.Contains(bodies[i], want) {
+			t.Fatalf("request %d missing %s: %s", i, want, bodies[i])
+		}
+	}
+
+	if err := c.SetEQ(context.Background(), EQSubGain, 16); err == nil {
+		t.Fatal("expected range error for SubGain 16")
+	}
+}
@@ -0,0 +1,109 @@
+package sonos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Event is one UPnP NOTIFY delivered to an EventListener.
+type Event struct {
+	Time time.Time
+	SID  string
+	Seq  string
+	// Vars are the event's properties, flattened by ParseEvent.
+	Vars map[string]string
+}
+
+// EventListener is a local HTTP server that receives UPnP event callbacks.
+// Speakers must be able to reach this machine on its port.
+type EventListener struct {
+	ln     net.Listener
+	srv    *http.Server
+	url    string
+	events chan Event
+}
+
+// NewEventListener listens on the local address that routes to remoteIP (a
+// speaker), on a free port.
+func NewEventListener(remoteIP string) (*EventListener, error) {
+	listenIP, err := ListenIPForRemote(remoteIP)
+	if err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(listenIP, "0"))
+	if err != nil {
+		return nil, err
+	}
+	return newEventListener(ln, listenIP), nil
+}
+
+func newEventListener(ln net.Listener, host string) *EventListener {
+	l := &EventListener{
+		ln:     ln,
+		url:    fmt.Sprintf("http://%s/notify", net.JoinHostPort(host, fmt.Sprint(ln.Addr().(*net.TCPAddr).Port))),
+		events: make(chan Event, 128),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/notify", l.handleNotify)
+	l.srv = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() { _ = l.srv.Serve(ln) }()
+	return l
+}
+
+func (l *EventListener) handleNotify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "NOTIFY" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	body, _ := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	_ = r.Body.Close()
+
+	vars, err := ParseEvent(body)
+	if err != nil {
+		vars = map[string]string{"parse_error": err.Error()}
+	}
+	select {
+	case l.events <- Event{
+		Time: time.Now().UTC(),
+		SID:  strings.TrimSpace(r.Header.Get("SID")),
+		Seq:  strings.TrimSpace(r.Header.Get("SEQ")),
+		Vars: vars,
+	}:
+	default:
+		// Drop if the consumer is too slow.
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// CallbackURL is the URL to pass to Subscribe.
+func (l *EventListener) CallbackURL() string { return l.url }
+
+// Events delivers received events. It is never closed.
+func (l *EventListener) Events() <-chan Event { return l.events }
+
+// Close stops the server.
+func (l *EventListener) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return l.srv.Shutdown(ctx)
+}
+
+// ListenIPForRemote returns the local IP address used to reach remoteIP.
+func ListenIPForRemote(remoteIP string) (string, error) {
+	conn, err := net.Dial("udp", net.JoinHostPort(remoteIP, "1900"))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	udpAddr, ok := conn.LocalAddr().(*net.UDPAddr)
+	if !ok || udpAddr.IP == nil {
+		return "", errors.New("could not determine local listen ip")
+	}
+	return udpAddr.IP.String(), nil
+}
@@ -0,0 +1,44 @@
+package sonos
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventListenerDeliversNotify(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := newEventListener(ln, "127.0.0.1")
+	t.Cleanup(func() { _ = l.Close() })
+
+	body := `<e:propertyset xmlns:e="urn:schemas-upnp-org:event-1-0"><e:property>
+<LastChange>&lt;Event xmlns=&quot;urn:schemas-upnp-org:metadata-1-0/AVT/&quot;&gt;&lt;InstanceID val=&quot;0&quot;&gt;&lt;TransportState val=&quot;STOPPED&quot;/&gt;&lt;/InstanceID&gt;&lt;/Event&gt;</LastChange>
+</e:property></e:propertyset>`
+	req, _ := http.NewRequest("NOTIFY", l.CallbackURL(), strings.NewReader(body))
+	req.Header.Set("SID", "uuid:sub-1")
+	req.Header.Set("SEQ", "0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %s", resp.Status)
+	}
+
+	select {
+	case ev := <-l.Events():
+		if ev.SID != "uuid:sub-1" || ev.Vars["transport_state"] != "STOPPED" {
+			t.Fatalf("event = %+v", ev)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("no event delivered")
+	}
+}
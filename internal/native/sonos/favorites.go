@@ -48,14 +48,16 @@ func (c *Client) ListFavorites(ctx context.Context, start, count int) (Favorites
 }
 
 func (c *Client) PlayFavorite(ctx context.Context, favorite DIDLItem) error {
-	uri := favoriteURI(favorite)
+	uri := FavoriteURI(favorite)
 	if uri == "" {
 		return fmt.Errorf("favorite has no URI")
 	}
 	return c.PlayURI(ctx, uri, favorite.ResMD)
 }
 
-func favoriteURI(favorite DIDLItem) string {
+// FavoriteURI returns the URI a favorite plays, falling back to the one in
+// its resource metadata.
+func FavoriteURI(favorite DIDLItem) string {
 	if favorite.URI != "" {
 		return favorite.URI
 	}
@@ -84,7 +84,7 @@ func TestFavoriteURIFromResMD(t *testing.T) {
 	f := DIDLItem{
 		ResMD: `<DIDL-Lite xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/"><item id="x"><res>http://example.com/stream</res></item></DIDL-Lite>`,
 	}
-	if got := favoriteURI(f); got != "http://example.com/stream" {
-		t.Fatalf("favoriteURI: %q", got)
+	if got := FavoriteURI(f); got != "http://example.com/stream" {
+		t.Fatalf("FavoriteURI: %q", got)
 	}
 }
@@ -0,0 +1,119 @@
+package sonos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Repeat is the repeat half of a play mode.
+type Repeat string
+
+const (
+	RepeatOff Repeat = "off"
+	RepeatAll Repeat = "all"
+	RepeatOne Repeat = "one"
+)
+
+// ParseRepeat accepts off, all, or one (case-insensitive).
+func ParseRepeat(s string) (Repeat, error) {
+	switch r := Repeat(strings.ToLower(strings.TrimSpace(s))); r {
+	case RepeatOff, RepeatAll, RepeatOne:
+		return r, nil
+	default:
+		return "", fmt.Errorf("invalid repeat %q (expected all|one|off)", s)
+	}
+}
+
+// PlayMode is AVTransport's CurrentPlayMode split into repeat and shuffle.
+type PlayMode struct {
+	Repeat  Repeat `json:"repeat"`
+	Shuffle bool   `json:"shuffle"`
+}
+
+// playModes maps every Sonos play mode to its repeat and shuffle settings.
+var playModes = map[string]PlayMode{
+	"NORMAL":             {Repeat: RepeatOff},
+	"REPEAT_ALL":         {Repeat: RepeatAll},
+	"REPEAT_ONE":         {Repeat: RepeatOne},
+	"SHUFFLE_NOREPEAT":   {Repeat: RepeatOff, Shuffle: true},
+	"SHUFFLE":            {Repeat: RepeatAll, Shuffle: true},
+	"SHUFFLE_REPEAT_ONE": {Repeat: RepeatOne, Shuffle: true},
+}
+
+// ParsePlayMode reads a CurrentPlayMode value such as "SHUFFLE_NOREPEAT".
+func ParsePlayMode(s string) (PlayMode, error) {
+	m, ok := playModes[strings.ToUpper(strings.TrimSpace(s))]
+	if !ok {
+		return PlayMode{}, fmt.Errorf("unknown play mode %q", s)
+	}
+	return m, nil
+}
+
+// String returns the CurrentPlayMode value for m.
+func (m PlayMode) String() string {
+	for name, pm := range playModes {
+		if pm == m {
+			return name
+		}
+	}
+	return "NORMAL"
+}
+
+// TransportSettings is the result of GetTransportSettings.
+type TransportSettings struct {
+	PlayMode       string `json:"playMode"`
+	RecQualityMode string `json:"recQualityMode,omitempty"`
+}
+
+func (c *Client) GetTransportSettings(ctx context.Context) (TransportSettings, error) {
+	resp, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "GetTransportSettings", map[string]string{
+		"InstanceID": "0",
+	})
+	if err != nil {
+		return TransportSettings{}, err
+	}
+	return TransportSettings{
+		PlayMode:       resp["PlayMode"],
+		RecQualityMode: resp["RecQualityMode"],
+	}, nil
+}
+
+// GetPlayMode returns the coordinator's repeat and shuffle settings.
+func (c *Client) GetPlayMode(ctx context.Context) (PlayMode, error) {
+	settings, err := c.GetTransportSettings(ctx)
+	if err != nil {
+		return PlayMode{}, err
+	}
+	return ParsePlayMode(settings.PlayMode)
+}
+
+func (c *Client) SetPlayMode(ctx context.Context, mode PlayMode) error {
+	_, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "SetPlayMode", map[string]string{
+		"InstanceID":  "0",
+		"NewPlayMode": mode.String(),
+	})
+	return err
+}
+
+func (c *Client) GetCrossfadeMode(ctx context.Context) (bool, error) {
+	resp, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "GetCrossfadeMode", map[string]string{
+		"InstanceID": "0",
+	})
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(resp["CrossfadeMode"]) == "1", nil
+}
+
+func (c *Client) SetCrossfadeMode(ctx context.Context, on bool) error {
+	v := "0"
+	if on {
+		v = "1"
+	}
+	_, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "SetCrossfadeMode", map[string]string{
+		"InstanceID":    "0",
+		"CrossfadeMode": v,
+	})
+	return err
+}
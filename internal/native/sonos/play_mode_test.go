@@ -0,0 +1,74 @@
+package sonos
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlayModeRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for name, want := range playModes {
+		got, err := ParsePlayMode(strings.ToLower(name))
+		if err != nil || got != want {
+			t.Fatalf("ParsePlayMode(%q) = %+v, %v", name, got, err)
+		}
+		if got.String() != name {
+			t.Fatalf("%+v.String() = %q, want %q", got, got.String(), name)
+		}
+	}
+	if _, err := ParsePlayMode("PARTY"); err == nil {
+		t.Fatal("expected error for unknown play mode")
+	}
+	if _, err := ParseRepeat("twice"); err == nil {
+		t.Fatal("expected error for unknown repeat")
+	}
+}
+
+func TestPlayModeAndCrossfadeCalls(t *testing.T) {
+	t.Parallel()
+
+	var bodies []string
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		action := r.Header.Get("SOAPACTION")
+		switch {
+		case strings.Contains(action, "#GetTransportSettings"):
+			return httpResponse(200, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><u:GetTransportSettingsResponse xmlns:u="urn:schemas-upnp-org:service:AVTransport:1"><PlayMode>SHUFFLE_REPEAT_ONE</PlayMode><RecQualityMode>NOT_IMPLEMENTED</RecQualityMode></u:GetTransportSettingsResponse></s:Body></s:Envelope>`), nil
+		case strings.Contains(action, "#GetCrossfadeMode"):
+			return httpResponse(200, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><u:GetCrossfadeModeResponse xmlns:u="urn:schemas-upnp-org:service:AVTransport:1"><CrossfadeMode>1</CrossfadeMode></u:GetCrossfadeModeResponse></s:Body></s:Envelope>`), nil
+		case strings.Contains(action, "#SetPlayMode"), strings.Contains(action, "#SetCrossfadeMode"):
+			return httpResponse(200, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body></s:Body></s:Envelope>`), nil
+		default:
+			t.Fatalf("unexpected SOAPACTION: %q", action)
+			return nil, nil
+		}
+	})
+	c := &Client{IP: "192.0.2.1", HTTP: &http.Client{Timeout: time.Second, Transport: rt}}
+	ctx := context.Background()
+
+	mode, err := c.GetPlayMode(ctx)
+	if err != nil || mode != (PlayMode{Repeat: RepeatOne, Shuffle: true}) {
+		t.Fatalf("GetPlayMode = %+v, %v", mode, err)
+	}
+	if on, err := c.GetCrossfadeMode(ctx); err != nil || !on {
+		t.Fatalf("GetCrossfadeMode = %v, %v", on, err)
+	}
+	if err := c.SetPlayMode(ctx, PlayMode{Repeat: RepeatOff, Shuffle: true}); err != nil {
+		t.Fatalf("SetPlayMode: %v", err)
+	}
+	if err := c.SetCrossfadeMode(ctx, false); err != nil {
+		t.Fatalf("SetCrossfadeMode: %v", err)
+	}
+	if !strings.Contains(bodies[2], "<NewPlayMode>SHUFFLE_NOREPEAT</NewPlayMode>") {
+		t.Fatalf("SetPlayMode body: %s", bodies[2])
+	}
+	if !strings.Contains(bodies[3], "<CrossfadeMode>0</CrossfadeMode>") {
+		t.Fatalf("SetCrossfadeMode body: %s", bodies[3])
+	}
+}
@@ -32,6 +32,21 @@ func (c *Client) SetVolume(ctx context.Context, volume int) error {
 	return err
 }
 
+// SetRelativeVolume changes the volume by adjustment and returns the new
+// volume.
+func (c *Client) SetRelativeVolume(ctx context.Context, adjustment int) (int, error) {
+	resp, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "SetRelativeVolume", map[string]string{
+		"InstanceID": "0",
+		"Channel":    "Master",
+		"Adjustment": strconv.Itoa(adjustment),
+	})
+	if err != nil {
+		return 0, err
+	}
+	v, _ := strconv.Atoi(resp["NewVolume"])
+	return v, nil
+}
+
 func (c *Client) GetMute(ctx context.Context) (bool, error) {
 	resp, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "GetMute", map[string]string{
 		"InstanceID": "0",
@@ -55,3 +70,77 @@ func (c *Client) SetMute(ctx context.Context, mute bool) error {
 	})
 	return err
 }
+
+// GetBass returns the bass level (-10 to 10).
+func (c *Client) GetBass(ctx context.Context) (int, error) {
+	resp, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "GetBass", map[string]string{
+		"InstanceID": "0",
+	})
+	if err != nil {
+		return 0, err
+	}
+	v, _ := strconv.Atoi(resp["CurrentBass"])
+	return v, nil
+}
+
+func (c *Client) SetBass(ctx context.Context, bass int) error {
+	_, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "SetBass", map[string]string{
+		"InstanceID":  "0",
+		"DesiredBass": strconv.Itoa(clamp(bass, MinToneLevel, MaxToneLevel)),
+	})
+	return err
+}
+
+// GetTreble returns the treble level (-10 to 10).
+func (c *Client) GetTreble(ctx context.Context) (int, error) {
+	resp, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "GetTreble", map[string]string{
+		"InstanceID": "0",
+	})
+	if err != nil {
+		return 0, err
+	}
+	v, _ := strconv.Atoi(resp["CurrentTreble"])
+	return v, nil
+}
+
+func (c *Client) SetTreble(ctx context.Context, treble int) error {
+	_, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "SetTreble", map[string]string{
+		"InstanceID":    "0",
+		"DesiredTreble": strconv.Itoa(clamp(treble, MinToneLevel, MaxToneLevel)),
+	})
+	return err
+}
+
+func (c *Client) GetLoudness(ctx context.Context) (bool, error) {
+	resp, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "GetLoudness", map[string]string{
+		"InstanceID": "0",
+		"Channel":    "Master",
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp["CurrentLoudness"] == "1", nil
+}
+
+func (c *Client) SetLoudness(ctx context.Context, on bool) error {
+	v := "0"
+	if on {
+		v = "1"
+	}
+	_, err := c.soapCall(ctx, controlRenderingControl, urnRenderingControl, "SetLoudness", map[string]string{
+		"InstanceID":      "0",
+		"Channel":         "Master",
+		"DesiredLoudness": v,
+	})
+	return err
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -2,6 +2,7 @@ package sonos
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"strings"
 	"testing"
@@ -58,3 +59,26 @@ func TestRenderingVolumeAndMute(t *testing.T) {
 		t.Fatalf("SetMute: %v", err)
 	}
 }
+
+func TestSetRelativeVolume(t *testing.T) {
+	t.Parallel()
+
+	var body string
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		if !strings.Contains(r.Header.Get("SOAPACTION"), "#SetRelativeVolume") {
+			t.Fatalf("unexpected SOAPACTION: %q", r.Header.Get("SOAPACTION"))
+		}
+		return httpResponse(200, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><u:SetRelativeVolumeResponse xmlns:u="urn:schemas-upnp-org:service:RenderingControl:1"><NewVolume>17</NewVolume></u:SetRelativeVolumeResponse></s:Body></s:Envelope>`), nil
+	})
+	c := &Client{IP: "192.0.2.1", HTTP: &http.Client{Timeout: time.Second, Transport: rt}}
+
+	v, err := c.SetRelativeVolume(context.Background(), -3)
+	if err != nil || v != 17 {
+		t.Fatalf("SetRelativeVolume = %d, %v", v, err)
+	}
+	if !strings.Contains(body, "<Adjustment>-3</Adjustment>") {
+		t.Fatalf("SetRelativeVolume body: %s", body)
+	}
+}
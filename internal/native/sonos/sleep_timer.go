@@ -0,0 +1,68 @@
+package sonos
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxSleepTimer is the longest sleep timer a speaker accepts.
+const MaxSleepTimer = 24*time.Hour - time.Second
+
+// ConfigureSleepTimer stops playback after d. A duration of zero or less
+// cancels the timer.
+func (c *Client) ConfigureSleepTimer(ctx context.Context, d time.Duration) error {
+	if d > MaxSleepTimer {
+		return fmt.Errorf("sleep timer %s is longer than %s", d, MaxSleepTimer)
+	}
+	v := ""
+	if d > 0 {
+		v = formatHMS(d)
+	}
+	_, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "ConfigureSleepTimer", map[string]string{
+		"InstanceID":            "0",
+		"NewSleepTimerDuration": v,
+	})
+	return err
+}
+
+// GetRemainingSleepTimerDuration returns the time left on the sleep timer,
+// or zero when none is set.
+func (c *Client) GetRemainingSleepTimerDuration(ctx context.Context) (time.Duration, error) {
+	resp, err := c.soapCall(ctx, controlAVTransport, urnAVTransport, "GetRemainingSleepTimerDuration", map[string]string{
+		"InstanceID": "0",
+	})
+	if err != nil {
+		return 0, err
+	}
+	return parseHMS(resp["RemainingSleepTimerDuration"])
+}
+
+// formatHMS formats d as HH:MM:SS, rounded up to whole seconds.
+func formatHMS(d time.Duration) string {
+	secs := int((d + time.Second - 1) / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+}
+
+// parseHMS reads an H:MM:SS duration; empty means zero.
+func parseHMS(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	var total time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		total += time.Duration(n) * unit
+	}
+	return total, nil
+}
@@ -0,0 +1,76 @@
+package sonos
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSleepTimerCalls(t *testing.T) {
+	t.Parallel()
+
+	var bodies []string
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		action := r.Header.Get("SOAPACTION")
+		switch {
+		case strings.Contains(action, "#GetRemainingSleepTimerDuration"):
+			return httpResponse(200, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><u:GetRemainingSleepTimerDurationResponse xmlns:u="urn:schemas-upnp-org:service:AVTransport:1"><RemainingSleepTimerDuration>1:02:03</RemainingSleepTimerDuration><CurrentSleepTimerGeneration>4</CurrentSleepTimerGeneration></u:GetRemainingSleepTimerDurationResponse></s:Body></s:Envelope>`), nil
+		case strings.Contains(action, "#ConfigureSleepTimer"):
+			return httpResponse(200, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body></s:Body></s:Envelope>`), nil
+		default:
+			t.Fatalf("unexpected SOAPACTION: %q", action)
+			return nil, nil
+		}
+	})
+	c := &Client{IP: "192.0.2.1", HTTP: &http.Client{Timeout: time.Second, Transport: rt}}
+	ctx := context.Background()
+
+	got, err := c.GetRemainingSleepTimerDuration(ctx)
+	if err != nil || got != time.Hour+2*time.Minute+3*time.Second {
+		t.Fatalf("GetRemainingSleepTimerDuration = %v, %v", got, err)
+	}
+	if err := c.ConfigureSleepTimer(ctx, 30*time.Minute); err != nil {
+		t.Fatalf("ConfigureSleepTimer: %v", err)
+	}
+	if err := c.ConfigureSleepTimer(ctx, 0); err != nil {
+		t.Fatalf("ConfigureSleepTimer(0): %v", err)
+	}
+	if !strings.Contains(bodies[1], "<NewSleepTimerDuration>00:30:00</NewSleepTimerDuration>") {
+		t.Fatalf("ConfigureSleepTimer body: %s", bodies[1])
+	}
+	if !strings.Contains(bodies[2], "<NewSleepTimerDuration></NewSleepTimerDuration>") {
+		t.Fatalf("cancel body: %s", bodies[2])
+	}
+	if err := c.ConfigureSleepTimer(ctx, 24*time.Hour); err == nil {
+		t.Fatal("expected error for a 24h timer")
+	}
+	if len(bodies) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(bodies))
+	}
+}
+
+func TestParseHMS(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]time.Duration{
+		"":         0,
+		"0:00:00":  0,
+		"00:30:00": 30 * time.Minute,
+		"23:59:59": MaxSleepTimer,
+	}
+	for in, want := range cases {
+		if got, err := parseHMS(in); err != nil || got != want {
+			t.Fatalf("parseHMS(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"30", "1:x:00", "-1:00:00"} {
+		if _, err := parseHMS(in); err == nil {
+			t.Fatalf("parseHMS(%q): expected error", in)
+		}
+	}
+}
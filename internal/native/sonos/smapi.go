@@ -170,6 +170,14 @@ type SMAPIItem struct {
 	Title    string `json:"title"`
 	Summary  string `json:"summary,omitempty"`
 	MimeType string `json:"mimeType,omitempty"`
+	// Artist and Album come from trackMetadata on track results.
+	Artist string `json:"artist,omitempty"`
+	Album  string `json:"album,omitempty"`
+}
+
+type smapiTrackMetadata struct {
+	Artist string `xml:"artist"`
+	Album  string `xml:"album"`
 }
 
 type SMAPISearchResult struct {
@@ -205,11 +213,12 @@ func (c *SMAPIClient) Search(ctx context.Context, category, term string, index,
 	}
 
 	type mediaMetadata struct {
-		ID       string `xml:"id"`
-		ItemType string `xml:"itemType"`
-		Title    string `xml:"title"`
-		MimeType string `xml:"mimeType"`
-		Summary  string `xml:"summary"`
+		ID            string             `xml:"id"`
+		ItemType      string             `xml:"itemType"`
+		Title         string             `xml:"title"`
+		MimeType      string             `xml:"mimeType"`
+		Summary       string             `xml:"summary"`
+		TrackMetadata smapiTrackMetadata `xml:"trackMetadata"`
 	}
 	type mediaCollection struct {
 		ID       string `xml:"id"`
@@ -250,6 +259,8 @@ func (c *SMAPIClient) Search(ctx context.Context, category, term string, index,
 			Title:    strings.TrimSpace(md.Title),
 			Summary:  strings.TrimSpace(md.Summary),
 			MimeType: strings.TrimSpace(md.MimeType),
+			Artist:   strings.TrimSpace(md.TrackMetadata.Artist),
+			Album:    strings.TrimSpace(md.TrackMetadata.Album),
 		})
 	}
 	for _, mc := range out.Result.MC {
@@ -298,11 +309,12 @@ func (c *SMAPIClient) GetMetadata(ctx context.Context, id string, index, count i
 	}
 
 	type mediaMetadata struct {
-		ID       string `xml:"id"`
-		ItemType string `xml:"itemType"`
-		Title    string `xml:"title"`
-		MimeType string `xml:"mimeType"`
-		Summary  string `xml:"summary"`
+		ID            string             `xml:"id"`
+		ItemType      string             `xml:"itemType"`
+		Title         string             `xml:"title"`
+		MimeType      string             `xml:"mimeType"`
+		Summary       string             `xml:"summary"`
+		TrackMetadata smapiTrackMetadata `xml:"trackMetadata"`
 	}
 	type mediaCollection struct {
 		ID       string `xml:"id"`
@@ -342,6 +354,8 @@ func (c *SMAPIClient) GetMetadata(ctx context.Context, id string, index, count i
 			Title:    strings.TrimSpace(md.Title),
 			Summary:  strings.TrimSpace(md.Summary),
 			MimeType: strings.TrimSpace(md.MimeType),
+			Artist:   strings.TrimSpace(md.TrackMetadata.Artist),
+			Album:    strings.TrimSpace(md.TrackMetadata.Album),
 		})
 	}
 	for _, mc := range out.Result.MC {
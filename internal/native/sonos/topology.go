@@ -3,8 +3,10 @@ package sonos
 import (
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 type Member struct {
@@ -187,6 +189,21 @@ func (t Topology) FindByIP(ip string) (Member, bool) {
 	return mem, ok
 }
 
+// FindByUUID returns the member (room or satellite) with the given UUID.
+func (t Topology) FindByUUID(uuid string) (Member, bool) {
+	if mem, ok := t.byUUID[uuid]; ok {
+		return mem, true
+	}
+	for _, g := range t.Groups {
+		for _, m := range g.Members {
+			if m.UUID == uuid {
+				return m, true
+			}
+		}
+	}
+	return Member{}, false
+}
+
 func (t Topology) GroupForIP(ip string) (Group, bool) {
 	for _, g := range t.Groups {
 		for _, m := range g.Members {
@@ -270,3 +287,24 @@ func (t Topology) CoordinatorUUIDForName(name string) (string, bool) {
 	}
 	return g.Coordinator.UUID, true
 }
+
+// CoordinatorClientForName discovers the household and returns a client for
+// the coordinator of the group containing the named room.
+func CoordinatorClientForName(ctx context.Context, name string, timeout time.Duration) (*Client, error) {
+	devs, err := Discover(ctx, DiscoverOptions{Timeout: timeout})
+	if err != nil {
+		return nil, err
+	}
+	if len(devs) == 0 {
+		return nil, errors.New("no speakers found")
+	}
+	top, err := NewClient(devs[0].IP, timeout).GetTopology(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ip, ok := top.CoordinatorIPForName(name)
+	if !ok {
+		return nil, errors.New("speaker name not found in topology: " + name)
+	}
+	return NewClient(ip, timeout), nil
+}
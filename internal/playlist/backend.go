@@ -0,0 +1,271 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sonos-playlist/internal/ai"
+	nativesonos "sonos-playlist/internal/native/sonos"
+	"sonos-playlist/internal/output"
+	"sonos-playlist/internal/resolve"
+	"sonos-playlist/internal/sonos"
+	"sonos-playlist/internal/storage"
+)
+
+// Backend queues songs and watches playback in one room.
+type Backend interface {
+	Pause(ctx context.Context) error
+	ClearQueue(ctx context.Context) error
+	// Resolve finds the best playable track for song. It only searches, so
+	// several songs may be resolved concurrently.
+	Resolve(ctx context.Context, song ai.Song) Resolved
+	// Queue adds a resolved track where opts says.
+	Queue(ctx context.Context, r Resolved, opts QueueOptions) sonos.QueueResult
+	// AddAlternate appends the best match not in tried.
+	AddAlternate(ctx context.Context, song ai.Song, tried map[int]struct{}) sonos.QueueResult
+	StartMonitor(ctx context.Context, options sonos.MonitorOptions) sonos.MonitorHandle
+}
+
+// QueueOptions say where Backend.Queue puts a track.
+type QueueOptions struct {
+	// PlayNow starts the track once it is queued.
+	PlayNow bool
+	// Position is the 1-based queue position to insert at; 0 appends.
+	Position int
+}
+
+// Resolved is a song matched to a playable track.
+type Resolved struct {
+	Song ai.Song
+	// TrackID is the iTunes catalog ID, or 0 for tracks on other services.
+	TrackID int
+	// Match is set by backends that resolve through a resolve.Resolver.
+	Match resolve.Match
+	// Error says why no track was found.
+	Error string
+	// Elapsed is how long the search took.
+	Elapsed time.Duration
+}
+
+// Found reports whether a playable track was found.
+func (r Resolved) Found() bool { return r.Error == "" }
+
+// addSong resolves and queues one song.
+func addSong(ctx context.Context, b Backend, song ai.Song, playNow bool) sonos.QueueResult {
+	r := b.Resolve(ctx, song)
+	if !r.Found() {
+		return sonos.QueueResult{Song: song, Success: false, Error: r.Error}
+	}
+	return b.Queue(ctx, r, QueueOptions{PlayNow: playNow})
+}
+
+// HTTPBackend goes through node-sonos-http-api. Speaker, when set, is the
+// room's coordinator; playback is then monitored through its events.
+type HTTPBackend struct {
+	Client  *sonos.Client
+	Room    string
+	Speaker *nativesonos.Client
+}
+
+func (b HTTPBackend) Pause(ctx context.Context) error {
+	return sonos.Pause(ctx, b.Client, b.Room)
+}
+
+func (b HTTPBackend) ClearQueue(ctx context.Context) error {
+	return sonos.ClearQueue(ctx, b.Client, b.Room)
+}
+
+func (b HTTPBackend) Resolve(ctx context.Context, song ai.Song) Resolved {
+	candidates := sonos.SearchTrackIDs(ctx, song)
+	if len(candidates) == 0 {
+		return Resolved{Song: song, Error: "Not found on iTunes"}
+	}
+	return Resolved{Song: song, TrackID: candidates[0]}
+}
+
+// Queue can only append through the HTTP API, so a track queued at a
+// position is appended and then moved there through Speaker.
+func (b HTTPBackend) Queue(ctx context.Context, r Resolved, opts QueueOptions) sonos.QueueResult {
+	result := sonos.QueueTrack(ctx, b.Client, b.Room, r.Song, r.TrackID, opts.PlayNow)
+	if !result.Success || opts.Position <= 0 {
+		return result
+	}
+	if b.Speaker == nil {
+		result.Warning = "cannot reach the speaker directly to move it up the queue"
+	} else if err := moveLastQueuedTo(ctx, b.Speaker, opts.Position); err != nil {
+		result.Warning = "could not move it up the queue: " + err.Error()
+	}
+	return result
+}
+
+func (b HTTPBackend) AddAlternate(ctx context.Context, song ai.Song, tried map[int]struct{}) sonos.QueueResult {
+	return sonos.AddAlternateSongToQueue(ctx, b.Client, b.Room, song, tried)
+}
+
+func (b HTTPBackend) StartMonitor(ctx context.Context, options sonos.MonitorOptions) sonos.MonitorHandle {
+	if b.Speaker == nil {
+		return sonos.StartPlaybackMonitor(ctx, b.Client, b.Room, options)
+	}
+	return startSpeakerMonitor(ctx, b.Speaker, sonos.RoomTrackSource(b.Client, b.Room, options.OnStateLog), options)
+}
+
+// NativeBackend talks UPnP to the room's group coordinator and queues tracks
+// directly, so no HTTP API server is needed. Songs are matched through
+// Resolver, which defaults to Apple Music.
+type NativeBackend struct {
+	Client   *nativesonos.Client
+	Resolver resolve.Resolver
+}
+
+// NewNativeBackend returns a backend for the coordinator client. A nil
+// resolver matches songs on Apple Music.
+func NewNativeBackend(client *nativesonos.Client, resolver resolve.Resolver) *NativeBackend {
+	if resolver == nil {
+		resolver = &resolve.AppleMusicResolver{Speaker: client}
+	}
+	return &NativeBackend{Client: client, Resolver: resolver}
+}
+
+// NativeResolver matches songs on services in order. Services that can't be
+// used are skipped with a warning; nil means none was usable and Apple Music
+// should be used.
+func NativeResolver(ctx context.Context, speaker *nativesonos.Client, services []string, out *output.Output) resolve.Resolver {
+	var tokens nativesonos.SMAPITokenStore
+	if store, err := nativesonos.NewDefaultSMAPITokenStore(); err == nil {
+		tokens = store
+	}
+	chain, errs := resolve.NewChain(ctx, speaker, services, tokens)
+	for _, err := range errs {
+		out.Warn("Skipping music service " + err.Error())
+	}
+	if len(chain) == 0 {
+		out.Warn("No configured music service is usable; matching songs on Apple Music")
+		return nil
+	}
+	out.Debug("Matching songs on: " + chain.Name())
+	return chain
+}
+
+func (b *NativeBackend) Pause(ctx context.Context) error {
+	return b.Client.Pause(ctx)
+}
+
+func (b *NativeBackend) ClearQueue(ctx context.Context) error {
+	return b.Client.RemoveAllTracksFromQueue(ctx)
+}
+
+func (b *NativeBackend) Resolve(ctx context.Context, song ai.Song) Resolved {
+	matches, err := b.Resolver.Resolve(ctx, song)
+	if len(matches) == 0 {
+		msg := "Not found on " + b.Resolver.Name()
+		if err != nil {
+			msg = err.Error()
+		}
+		return Resolved{Song: song, Error: msg}
+	}
+	return Resolved{Song: song, TrackID: matchTrackID(matches[0]), Match: matches[0]}
+}
+
+func (b *NativeBackend) Queue(ctx context.Context, r Resolved, opts QueueOptions) sonos.QueueResult {
+	song, trackID, playNow := r.Song, r.TrackID, opts.PlayNow
+	if err := b.enqueue(ctx, r.Match, nativesonos.EnqueueOptions{PlayNow: playNow, Position: opts.Position}); err != nil {
+		return sonos.QueueResult{Song: song, Success: false, Error: err.Error(), TrackID: trackID}
+	}
+	if playNow && !b.waitForPlaying(ctx, matchedSong(r), 5*time.Second) {
+		if trackID != 0 {
+			storage.BlockTrack(r.Match.ID, song.Artist, song.Title, "")
+		}
+		return sonos.QueueResult{Song: song, Success: false, Error: "Track appears unavailable (stays stopped)", TrackID: trackID}
+	}
+	return sonos.QueueResult{Song: song, Success: true, TrackID: trackID}
+}
+
+// AddAlternate only retries Apple Music matches, since alternates are tracked
+// by iTunes ID.
+func (b *NativeBackend) AddAlternate(ctx context.Context, song ai.Song, tried map[int]struct{}) sonos.QueueResult {
+	matches, _ := b.Resolver.Resolve(ctx, song)
+	for _, m := range matches {
+		trackID := matchTrackID(m)
+		if trackID == 0 {
+			continue
+		}
+		if _, ok := tried[trackID]; ok {
+			continue
+		}
+		if err := b.enqueue(ctx, m, nativesonos.EnqueueOptions{}); err != nil {
+			return sonos.QueueResult{Song: song, Success: false, Error: err.Error(), TrackID: trackID}
+		}
+		return sonos.QueueResult{Song: song, Success: true, TrackID: trackID}
+	}
+	return sonos.QueueResult{Song: song, Success: false, Error: "No alternate track found"}
+}
+
+func (b *NativeBackend) enqueue(ctx context.Context, m resolve.Match, opts nativesonos.EnqueueOptions) error {
+	if m.URI == "" {
+		return fmt.Errorf("%s match %s has no queue URI", m.Service, m.ID)
+	}
+	_, err := b.Client.EnqueueURI(ctx, m.URI, m.Metadata, opts)
+	return err
+}
+
+// matchedSong is the track r matched, as the service names it; that is what
+// the speaker reports once it plays.
+func matchedSong(r Resolved) ai.Song {
+	if r.Match.Title == "" || r.Match.Artist == "" {
+		return r.Song
+	}
+	return ai.Song{Title: r.Match.Title, Artist: r.Match.Artist, Album: r.Match.Album}
+}
+
+// matchTrackID returns the iTunes ID of an Apple Music match, or 0 for other
+// services.
+func matchTrackID(m resolve.Match) int {
+	if m.Service != resolve.AppleMusic {
+		return 0
+	}
+	id, _ := strconv.Atoi(m.ID)
+	return id
+}
+
+// waitForPlaying reports whether song becomes the current, playing track
+// within timeout.
+func (b *NativeBackend) waitForPlaying(ctx context.Context, song ai.Song, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if ctx.Err() != nil {
+			return false
+		}
+		info, err := b.Client.GetTransportInfo(ctx)
+		if err == nil && (info.State == "PLAYING" || info.State == "TRANSITIONING") {
+			if track, err := b.currentTrack(ctx); err == nil && track.Song != nil && songKey(*track.Song) == songKey(song) {
+				return true
+			}
+		}
+		time.Sleep(300 * time.Millisecond)
+	}
+	return false
+}
+
+func (b *NativeBackend) currentTrack(ctx context.Context) (sonos.TrackInfo, error) {
+	pos, err := b.Client.GetPositionInfo(ctx)
+	if err != nil {
+		return sonos.TrackInfo{}, err
+	}
+	item, ok := nativesonos.ParseNowPlaying(pos.TrackMeta)
+	if !ok || strings.TrimSpace(item.Title) == "" || strings.TrimSpace(item.Artist) == "" {
+		return sonos.TrackInfo{}, nil
+	}
+	return sonos.TrackInfo{
+		Song:            &ai.Song{Title: item.Title, Artist: item.Artist},
+		PositionSeconds: sonos.ParseTimeToSeconds(pos.RelTime),
+		TrackID:         storage.ExtractTrackIDFromURI(pos.TrackURI),
+		Album:           item.Album,
+	}, nil
+}
+
+func (b *NativeBackend) StartMonitor(ctx context.Context, options sonos.MonitorOptions) sonos.MonitorHandle {
+	return startSpeakerMonitor(ctx, b.Client, b.currentTrack, options)
+}
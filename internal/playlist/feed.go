@@ -0,0 +1,71 @@
+package playlist
+
+import (
+	"context"
+	"sync"
+
+	"sonos-playlist/internal/ai"
+)
+
+// songFeed is an unbounded queue between the streaming generator and the
+// queueing loop. push never blocks, so slow Sonos calls can't stall the
+// provider streams.
+type songFeed struct {
+	mu     sync.Mutex
+	songs  []ai.Song
+	closed bool
+	notify chan struct{}
+	done   chan struct{}
+}
+
+func newSongFeed() *songFeed {
+	return &songFeed{notify: make(chan struct{}, 1), done: make(chan struct{})}
+}
+
+func (f *songFeed) push(song ai.Song) {
+	f.mu.Lock()
+	f.songs = append(f.songs, song)
+	f.mu.Unlock()
+	f.wake()
+}
+
+// close marks the feed complete; next drains what's left and then reports
+// false.
+func (f *songFeed) close() {
+	f.mu.Lock()
+	f.closed = true
+	f.mu.Unlock()
+	close(f.done)
+	f.wake()
+}
+
+func (f *songFeed) wake() {
+	select {
+	case f.notify <- struct{}{}:
+	default:
+	}
+}
+
+// next blocks until a song is available, the feed is closed and drained, or
+// ctx is done.
+func (f *songFeed) next(ctx context.Context) (ai.Song, bool) {
+	for {
+		f.mu.Lock()
+		if len(f.songs) > 0 {
+			song := f.songs[0]
+			f.songs = f.songs[1:]
+			f.mu.Unlock()
+			return song, true
+		}
+		closed := f.closed
+		f.mu.Unlock()
+		if closed {
+			return ai.Song{}, false
+		}
+		select {
+		case <-f.notify:
+		case <-ctx.Done():
+			return ai.Song{}, false
+		}
+	}
+}
@@ -5,57 +5,138 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"sonos-playlist/internal/ai"
+	nativesonos "sonos-playlist/internal/native/sonos"
 	"sonos-playlist/internal/output"
 	"sonos-playlist/internal/sonos"
 	"sonos-playlist/internal/storage"
+	"sonos-playlist/internal/taste"
 )
 
+// QueueMode controls where generated songs are queued.
+type QueueMode string
+
+const (
+	// QueueReplace clears the queue and starts playing the new playlist.
+	QueueReplace QueueMode = "replace"
+	// QueueAppend adds songs after the existing queue.
+	QueueAppend QueueMode = "append"
+	// QueuePlayNext inserts songs, in order, right after the current track.
+	QueuePlayNext QueueMode = "next"
+)
+
+// QueueEditor reads and edits a speaker's queue directly over UPnP. The
+// native Sonos client implements it.
+type QueueEditor interface {
+	ListQueue(ctx context.Context, start, count int) (nativesonos.QueuePage, error)
+	GetPositionInfo(ctx context.Context) (nativesonos.PositionInfo, error)
+	RemoveTrackFromQueue(ctx context.Context, oneBasedTrackNumber int) error
+	ReorderTracksInQueue(ctx context.Context, start, count, insertBefore int) error
+}
+
 type GeneratorOptions struct {
-	Keys             ai.APIKeys
-	Models           ai.Models
+	Keys    ai.APIKeys
+	Models  ai.Models
+	Weights ai.Weights
+	// Templates and Taste shape the prompt sent to every provider.
+	Templates        ai.PromptTemplates
+	Taste            taste.Profile
 	Prompt           string
 	Room             string
 	DryRun           bool
-	Client           *sonos.Client
+	Backend          Backend
 	Monitor          bool
 	CountPerProvider int
-	Output           *output.Output
+	// ExcludeRecentDays skips songs queued in the last N days (0 disables).
+	ExcludeRecentDays int
+	// Seed is the song playing now. When set the playlist follows on from it
+	// and is never queued with QueueReplace: it defaults to QueuePlayNext, or
+	// QueueAppend when Queue is unavailable.
+	Seed *ai.Song
+	// Mode defaults to QueueReplace. QueuePlayNext requires Queue.
+	Mode  QueueMode
+	Queue QueueEditor
+	// ResolveWorkers bounds concurrent song searches (default 4). Searches
+	// finishing out of order never reorder the queue. The first few streamed
+	// songs are queued as they arrive, which is not ranked; the rest of the
+	// initial playlist follows in ranked order.
+	ResolveWorkers int
+	// Handoff, when set, passes the queued songs to a monitor running
+	// elsewhere (the daemon). It is only used with Monitor, which then only
+	// runs here if the handoff fails.
+	Handoff func(ctx context.Context, songs []WatchedSong) error
+	Output  *output.Output
 }
 
 type Result struct {
-	Room            string `json:"room"`
-	DryRun          bool   `json:"dryRun"`
-	QueuedSongs     int    `json:"queuedSongs"`
-	FailedSongs     int    `json:"failedSongs"`
-	PlaybackStarted bool   `json:"playbackStarted"`
-	Monitored       bool   `json:"monitored"`
+	Room            string    `json:"room"`
+	DryRun          bool      `json:"dryRun"`
+	QueuedSongs     int       `json:"queuedSongs"`
+	FailedSongs     int       `json:"failedSongs"`
+	PlaybackStarted bool      `json:"playbackStarted"`
+	Monitored       bool      `json:"monitored"`
+	Mode            QueueMode `json:"mode"`
+	// Seed is the track the playlist was generated from, if any.
+	Seed *ai.Song `json:"seed,omitempty"`
+	// Songs holds the ranked playlist for dry runs.
+	Songs []ai.RankedSong `json:"songs,omitempty"`
+	// Providers reports how each AI provider fared during the initial generation.
+	Providers []ai.ProviderReport `json:"providers,omitempty"`
+	// HandedOff reports that monitoring was passed to GeneratorOptions.Handoff.
+	HandedOff bool `json:"handedOff,omitempty"`
 }
 
 const (
 	minSongs         = 30
 	maxRetries       = 5
 	altRetryCooldown = 30 * time.Second
-	fastStartTimeout = 10 * time.Second
 )
 
 func songKey(song ai.Song) string {
-	return strings.ToLower(song.Artist) + ":::" + strings.ToLower(song.Title)
+	return ai.SongKey(song)
 }
 
-func generateFastStartSongs(ctx context.Context, keys ai.APIKeys, models ai.Models, prompt string) []ai.Song {
-	if keys.Google == "" {
-		return []ai.Song{}
+// songDetails formats optional album/year metadata as " (Album, 1959)".
+func songDetails(song ai.Song) string {
+	parts := []string{}
+	if song.Album != "" {
+		parts = append(parts, song.Album)
 	}
-	fctx, cancel := context.WithTimeout(ctx, fastStartTimeout)
-	defer cancel()
-	songs, err := ai.GeneratePlaylistGemini(fctx, keys.Google, models.WithDefaults().Gemini, prompt, 3)
-	if err != nil {
-		return []ai.Song{}
+	if song.Year != 0 {
+		parts = append(parts, strconv.Itoa(song.Year))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// printProviderReports warns about failed providers and, in verbose mode,
+// prints per-provider status, latency, and contribution counts.
+func printProviderReports(out *output.Output, reports []ai.ProviderReport) {
+	for _, r := range reports {
+		if r.Status == ai.ProviderError {
+			out.Warn(fmt.Sprintf("Provider %s failed: %s", r.Provider, truncate(r.Error, 200)))
+		}
+	}
+	if !out.Verbose {
+		return
+	}
+	out.Debug("Provider summary:")
+	for _, r := range reports {
+		out.Debug(fmt.Sprintf("  %-8s %-6s %6dms  returned=%d kept=%d exclusive=%d  (%s)",
+			r.Provider, r.Status, r.LatencyMS, r.Returned, r.Kept, r.Exclusive, r.Model))
 	}
-	return songs
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
 }
 
 func GenerateAndPlay(ctx context.Context, options GeneratorOptions) (Result, error) {
@@ -64,200 +145,252 @@ func GenerateAndPlay(ctx context.Context, options GeneratorOptions) (Result, err
 	prompt := options.Prompt
 	room := options.Room
 	dryRun := options.DryRun
-	client := options.Client
+	backend := options.Backend
 	monitor := options.Monitor
 	countPerProvider := options.CountPerProvider
 	out := options.Output
+	seed := options.Seed
+	mode, fellBack := queueMode(options)
+	if fellBack {
+		out.Warn("Cannot reach the speaker directly to insert songs; appending to the queue instead")
+	}
+	prompts := ai.PromptBuilder{Templates: options.Templates, Taste: options.Taste, Room: room, Seed: seed}
+
+	workers := options.ResolveWorkers
+	if workers <= 0 {
+		workers = defaultResolveWorkers
+	}
 
 	playbackStarted := false
-	var fastStartSong *ai.Song
+	// existingKeys is also read by the search pipeline to skip songs that
+	// are already queued.
+	var keysMu sync.Mutex
 	existingKeys := map[string]struct{}{}
-	triedTrackIDsBySong := map[string]map[int]struct{}{}
-	lastAltAttemptAt := map[string]time.Time{}
 	var monitorHandle *sonos.MonitorHandle
+	watcher := NewWatcher(backend, out)
+	// With a handoff the songs are monitored elsewhere once queued.
+	handoff := monitorHandoff(options)
 
-	handleUnplayable := func(song ai.Song, trackID string) error {
-		key := songKey(song)
-		if trackID != "" {
-			out.Warn(fmt.Sprintf("Detected unplayable track: %s - %s (ID: %s, now blocked)", song.Artist, song.Title, trackID))
-		} else {
-			out.Warn(fmt.Sprintf("Detected unplayable track: %s - %s", song.Artist, song.Title))
+	ensureMonitor := func() {
+		if !monitor || handoff != nil || monitorHandle != nil {
+			return
+		}
+		h := watcher.Start(ctx)
+		monitorHandle = &h
+		out.Info(out.Gray("Monitoring playback for unavailable tracks (Ctrl+C to stop)..."))
+	}
+
+	recent := []ai.Song{}
+	if options.ExcludeRecentDays > 0 {
+		since := time.Now().AddDate(0, 0, -options.ExcludeRecentDays)
+		for _, e := range storage.RecentHistory(since) {
+			recent = append(recent, ai.Song{Artist: e.Artist, Title: e.Title})
 		}
-		now := time.Now()
-		if last, ok := lastAltAttemptAt[key]; ok && now.Sub(last) < altRetryCooldown {
-			return nil
+		if len(recent) > 0 {
+			out.Info(out.Gray(fmt.Sprintf("Excluding %d songs played in the last %d days", len(recent), options.ExcludeRecentDays)))
 		}
-		lastAltAttemptAt[key] = now
+	}
+	if seed != nil {
+		out.Info(fmt.Sprintf("Seeding from now playing: %s - %s", seed.Artist, seed.Title))
+		recent = append([]ai.Song{*seed}, recent...)
+		existingKeys[songKey(*seed)] = struct{}{}
+	}
 
-		tried, ok := triedTrackIDsBySong[key]
-		if !ok {
-			tried = map[int]struct{}{}
-			triedTrackIDsBySong[key] = tried
+	if dryRun {
+		out.Info(fmt.Sprintf("Generating playlist from all providers for: %q", prompt))
+		req, err := prompts.Build(prompt, countPerProvider, recent)
+		if err != nil {
+			return Result{}, err
 		}
-		if trackID != "" {
-			if id, err := strconv.Atoi(trackID); err == nil {
-				tried[id] = struct{}{}
+		generated, err := ai.GeneratePlaylistAllProviders(ctx, keys, models, options.Weights, req)
+		printProviderReports(out, generated.Providers)
+		if err != nil {
+			return Result{}, err
+		}
+		out.Success(fmt.Sprintf("Generated %d unique songs from all providers", len(generated.Songs)))
+		out.Print(out.Bold("Initial playlist:"))
+		for i, song := range generated.Songs {
+			voteIndicator := ""
+			if song.Votes > 1 {
+				voteIndicator = out.Yellow(fmt.Sprintf(" [%d]", song.Votes))
+			}
+			out.Print(fmt.Sprintf("  %d. %s - %s%s%s", i+1, song.Artist, song.Title, songDetails(song.Song), voteIndicator))
+			if song.Reason != "" {
+				out.Print(out.Gray("       " + song.Reason))
 			}
 		}
+		out.Print("")
+		out.Warn("Dry run - not queueing or playing")
+		return Result{Room: room, DryRun: true, Songs: generated.Songs, Providers: generated.Providers, Seed: seed, Mode: mode}, nil
+	}
 
-		alt := sonos.AddAlternateSongToQueue(ctx, client, room, song, tried)
-		if alt.Success && alt.TrackID != 0 {
-			tried[alt.TrackID] = struct{}{}
-			if trackID != "" {
-				storage.SetTrackReplacement(trackID, strconv.Itoa(alt.TrackID))
+	// insertAt is the queue position the next song moves to in QueuePlayNext
+	// mode: right after the current track, then after each inserted song.
+	insertAt := 0
+	if mode != QueueReplace {
+		// Whatever is playing keeps playing; new songs go into the queue.
+		playbackStarted = true
+		ensureMonitor()
+		if mode == QueuePlayNext {
+			pos, err := options.Queue.GetPositionInfo(ctx)
+			if err != nil {
+				return Result{}, err
 			}
-			out.Warn(fmt.Sprintf("Unavailable track replaced with alternate: %s - %s", song.Artist, song.Title))
-		} else {
-			out.Warn(fmt.Sprintf("No alternate found for: %s - %s", song.Artist, song.Title))
+			current, _ := strconv.Atoi(strings.TrimSpace(pos.Track))
+			insertAt = current + 1
+		}
+	} else {
+		if err := backend.Pause(ctx); err != nil {
+			// ignore pause failures
+		}
+		if err := backend.ClearQueue(ctx); err != nil {
+			return Result{}, err
 		}
-		return nil
 	}
 
-	ensureMonitor := func() {
-		if !monitor || monitorHandle != nil {
-			return
+	queuedSongs := []ai.Song{}
+	failedSongs := []ai.Song{}
+
+	isQueued := func(song ai.Song) bool {
+		keysMu.Lock()
+		defer keysMu.Unlock()
+		_, ok := existingKeys[songKey(song)]
+		return ok
+	}
+	// searched holds songs that already failed this run, so the ranked pass
+	// doesn't look up the streamed head's misses again.
+	searched := map[string]struct{}{}
+	isTried := func(song ai.Song) bool {
+		if isQueued(song) {
+			return true
 		}
-		h := sonos.StartPlaybackMonitor(ctx, client, room, sonos.MonitorOptions{
-			OnUnplayable: handleUnplayable,
-			UseTimer:     false,
-		})
-		monitorHandle = &h
-		out.Info(out.Gray("Monitoring playback for unavailable tracks (Ctrl+C to stop)..."))
+		keysMu.Lock()
+		defer keysMu.Unlock()
+		_, ok := searched[songKey(song)]
+		return ok
 	}
 
-	var songs []ai.RankedSong
-	if !dryRun {
-		out.Info(fmt.Sprintf("Generating fast-start songs (%s)...", models.Gemini))
-		fastStartCandidates := generateFastStartSongs(ctx, keys, models, prompt)
-
-		if err := sonos.Pause(ctx, client, room); err != nil {
-			// ignore pause failures
+	// queueResolved queues one searched song. It returns false once enough
+	// songs are queued.
+	queueResolved := func(r Resolved) bool {
+		if len(queuedSongs) >= minSongs {
+			return false
 		}
-		if err := sonos.ClearQueue(ctx, client, room); err != nil {
-			return Result{}, err
+		song := r.Song
+		key := songKey(song)
+		if isQueued(song) {
+			return true
 		}
-
-		if len(fastStartCandidates) == 0 {
-			out.Warn("Fast-start unavailable; continuing")
-		} else {
-			for _, candidate := range fastStartCandidates {
-				result := sonos.AddSongToQueue(ctx, client, room, candidate, true)
-				if !result.Success {
-					continue
-				}
-				key := songKey(candidate)
-				if result.TrackID != 0 {
-					tried := triedTrackIDsBySong[key]
-					if tried == nil {
-						tried = map[int]struct{}{}
-						triedTrackIDsBySong[key] = tried
-					}
-					tried[result.TrackID] = struct{}{}
-				}
-				existingKeys[key] = struct{}{}
-				playbackStarted = true
-				c := candidate
-				fastStartSong = &c
-				out.Success(fmt.Sprintf("  -> Now playing: %s - %s", candidate.Artist, candidate.Title))
-				ensureMonitor()
-				break
-			}
-			if !playbackStarted {
-				out.Warn("Fast-start songs queued but none were playable")
-			}
+		out.Write(out.Gray(fmt.Sprintf("  Searching: %s - %s... ", song.Artist, song.Title)))
+		shouldPlayNow := !playbackStarted
+		start := time.Now()
+		result := sonos.QueueResult{Song: song, Success: false, Error: r.Error}
+		if r.Found() {
+			result = backend.Queue(ctx, r, QueueOptions{PlayNow: shouldPlayNow, Position: insertAt})
+		}
+		if !result.Success {
+			out.Print(out.Red("not found"))
+			out.Debug(fmt.Sprintf("    searched in %dms: %s", r.Elapsed.Milliseconds(), result.Error))
+			failedSongs = append(failedSongs, song)
+			keysMu.Lock()
+			searched[key] = struct{}{}
+			keysMu.Unlock()
+			return true
+		}
+		out.Print(out.Green("found"))
+		out.Debug(fmt.Sprintf("    searched in %dms, queued in %dms", r.Elapsed.Milliseconds(), time.Since(start).Milliseconds()))
+		if result.Warning != "" {
+			out.Warn(fmt.Sprintf("%s - %s: %s", song.Artist, song.Title, result.Warning))
+		} else if insertAt > 0 {
+			insertAt++
+		}
+		queuedSongs = append(queuedSongs, song)
+		keysMu.Lock()
+		existingKeys[key] = struct{}{}
+		keysMu.Unlock()
+		trackID := ""
+		if result.TrackID != 0 {
+			trackID = strconv.Itoa(result.TrackID)
 		}
+		id := storage.RecordHistory(storage.HistoryEntry{
+			Artist: song.Artist, Title: song.Title, Album: song.Album, TrackID: trackID,
+			Room: room, Prompt: prompt,
+		})
+		watcher.Add(WatchedSong{Song: song, HistoryID: id, TrackIDs: []int{result.TrackID}})
+		if shouldPlayNow {
+			playbackStarted = true
+			out.Success("  -> Playback started!")
+			ensureMonitor()
+		}
+		return len(queuedSongs) < minSongs
 	}
 
+	// The first streamHeadSongs songs are queued in the order providers
+	// produce them, so playback starts while the models are still writing.
+	// That order is not ranked; the rest of the playlist is queued from the
+	// ranked result once every provider has answered.
 	out.Info(fmt.Sprintf("Generating playlist from all providers for: %q", prompt))
-	ranked, err := ai.GeneratePlaylistAllProviders(ctx, keys, models, prompt, countPerProvider)
+	out.Info("Adding the first songs to queue as they arrive...")
+	req, err := prompts.Build(prompt, countPerProvider, recent)
 	if err != nil {
 		return Result{}, err
 	}
-	songs = ranked
-	out.Success(fmt.Sprintf("Generated %d unique songs from all providers", len(songs)))
+	feed := newSongFeed()
+	var generated ai.PlaylistResult
+	var genErr error
+	go func() {
+		defer feed.close()
+		generated, genErr = ai.StreamPlaylistAllProviders(ctx, keys, models, options.Weights, req, feed.push)
+	}()
+	resolveInOrder(ctx, workers, feed.next, isTried, backend.Resolve, func(r Resolved) bool {
+		return queueResolved(r) && len(queuedSongs) < streamHeadSongs
+	})
+	// Once the head is queued, or on cancellation, the pipeline stops reading
+	// the feed; wait for the providers to finish.
+	<-feed.done
 
-	out.Print(out.Bold("Initial playlist:"))
-	for i, song := range songs {
-		voteIndicator := ""
-		if song.Votes > 1 {
-			voteIndicator = out.Yellow(fmt.Sprintf(" [%d]", song.Votes))
+	printProviderReports(out, generated.Providers)
+	reports := generated.Providers
+	if genErr != nil {
+		if len(queuedSongs) == 0 {
+			return Result{}, genErr
 		}
-		out.Print(fmt.Sprintf("  %d. %s - %s%s", i+1, song.Artist, song.Title, voteIndicator))
-	}
-	out.Print("")
-
-	if dryRun {
-		out.Warn("Dry run - not queueing or playing")
-		return Result{Room: room, DryRun: true, QueuedSongs: 0, FailedSongs: 0, PlaybackStarted: false, Monitored: false}, nil
+		out.Warn(genErr.Error())
 	}
-
-	if playbackStarted {
-		out.Info("Fast-start playing; appending generated songs to the queue...")
+	out.Success(fmt.Sprintf("Generated %d unique songs from all providers", len(generated.Songs)))
+	if len(queuedSongs) < minSongs && ctx.Err() == nil {
+		ranked := make([]ai.Song, 0, len(generated.Songs))
+		for _, song := range generated.Songs {
+			ranked = append(ranked, song.Song)
+		}
+		out.Info("Adding the rest of the playlist to queue in ranked order...")
+		resolveInOrder(ctx, workers, sliceSongs(ranked), isTried, backend.Resolve, queueResolved)
 	}
 
-	queuedSongs := []ai.Song{}
-	failedSongs := []ai.Song{}
-	retryCount := 0
-
-	for len(queuedSongs) < minSongs && retryCount < maxRetries {
-		if retryCount > 0 {
-			out.Info(fmt.Sprintf("Generating more songs (attempt %d)...", retryCount+1))
-			existing := make([]ai.Song, 0, len(queuedSongs)+len(failedSongs)+1)
-			existing = append(existing, queuedSongs...)
-			existing = append(existing, failedSongs...)
-			if fastStartSong != nil {
-				existing = append(existing, *fastStartSong)
-			}
-			moreSongs, err := ai.GenerateMoreSongs(ctx, keys, models, prompt, existing, countPerProvider)
-			if err != nil {
-				return Result{}, err
-			}
-			if len(moreSongs) == 0 {
-				out.Warn("Could not generate more unique songs")
-				break
-			}
-			songs = make([]ai.RankedSong, 0, len(moreSongs))
-			for _, s := range moreSongs {
-				songs = append(songs, ai.RankedSong{Song: s, Votes: 1})
-			}
-			out.Success(fmt.Sprintf("Generated %d more songs", len(songs)))
+	for retryCount := 1; len(queuedSongs) < minSongs && retryCount < maxRetries; retryCount++ {
+		out.Info(fmt.Sprintf("Generating more songs (attempt %d)...", retryCount+1))
+		// This session's songs come first so they're the ones spelled out in
+		// the prompt; older history is still filtered from the answers.
+		existing := make([]ai.Song, 0, len(queuedSongs)+len(failedSongs)+len(recent))
+		existing = append(existing, queuedSongs...)
+		existing = append(existing, failedSongs...)
+		existing = append(existing, recent...)
+		moreReq, err := prompts.Build(prompt, countPerProvider, existing)
+		if err != nil {
+			return Result{}, err
+		}
+		moreSongs, err := ai.GenerateMoreSongs(ctx, keys, models, moreReq)
+		if err != nil {
+			return Result{}, err
 		}
+		if len(moreSongs) == 0 {
+			out.Warn("Could not generate more unique songs")
+			break
+		}
+		out.Success(fmt.Sprintf("Generated %d more songs", len(moreSongs)))
 
 		out.Info("Adding songs to queue...")
-		for _, song := range songs {
-			if len(queuedSongs) >= minSongs {
-				break
-			}
-			key := songKey(song.Song)
-			if _, ok := existingKeys[key]; ok {
-				continue
-			}
-			out.Write(out.Gray(fmt.Sprintf("  Searching: %s - %s... ", song.Artist, song.Title)))
-			shouldPlayNow := !playbackStarted
-			result := sonos.AddSongToQueue(ctx, client, room, song.Song, shouldPlayNow)
-			if result.Success {
-				out.Print(out.Green("found"))
-				queuedSongs = append(queuedSongs, song.Song)
-				existingKeys[key] = struct{}{}
-				if result.TrackID != 0 {
-					tried := triedTrackIDsBySong[key]
-					if tried == nil {
-						tried = map[int]struct{}{}
-						triedTrackIDsBySong[key] = tried
-					}
-					tried[result.TrackID] = struct{}{}
-				}
-				if shouldPlayNow {
-					playbackStarted = true
-					out.Success("  -> Playback started!")
-					ensureMonitor()
-				}
-			} else {
-				out.Print(out.Red("not found"))
-				failedSongs = append(failedSongs, song.Song)
-			}
-		}
-		retryCount++
+		resolveInOrder(ctx, workers, sliceSongs(moreSongs), isTried, backend.Resolve, queueResolved)
 	}
 
 	out.Print("")
@@ -265,23 +398,77 @@ func GenerateAndPlay(ctx context.Context, options GeneratorOptions) (Result, err
 	if len(failedSongs) > 0 {
 		out.Warn(fmt.Sprintf("%d songs not found on Apple Music", len(failedSongs)))
 	}
-	if !playbackStarted {
+	if len(queuedSongs) == 0 {
 		out.Warn("No songs were queued, nothing to play")
 	}
 
+	result := Result{
+		Room: room, DryRun: false, QueuedSongs: len(queuedSongs), FailedSongs: len(failedSongs),
+		PlaybackStarted: playbackStarted, Providers: reports, Seed: seed, Mode: mode,
+	}
+	if handoff != nil && len(queuedSongs) > 0 {
+		err := handoff(ctx, watcher.Songs())
+		if err == nil {
+			out.Info(out.Gray("Handed the queue to the sonos daemon for monitoring."))
+			result.Monitored = true
+			result.HandedOff = true
+			return result, nil
+		}
+		out.Warn("Could not hand the queue to the sonos daemon: " + err.Error())
+	}
+	handoff = nil
 	if !monitor {
 		out.Info(out.Gray("Queueing complete. Exiting now (use --monitor to keep running)."))
-		return Result{
-			Room: room, DryRun: false, QueuedSongs: len(queuedSongs), FailedSongs: len(failedSongs),
-			PlaybackStarted: playbackStarted, Monitored: false,
-		}, nil
+		return result, nil
 	}
 
+	if playbackStarted {
+		ensureMonitor()
+	}
+	result.Monitored = true
 	if monitorHandle != nil {
 		<-monitorHandle.Done
 	}
-	return Result{
-		Room: room, DryRun: false, QueuedSongs: len(queuedSongs), FailedSongs: len(failedSongs),
-		PlaybackStarted: playbackStarted, Monitored: true,
-	}, nil
+	return result, nil
+}
+
+// monitorHandoff returns options.Handoff when the run asked for monitoring.
+// Without Monitor nothing is watched, here or in the daemon.
+func monitorHandoff(options GeneratorOptions) func(context.Context, []WatchedSong) error {
+	if !options.Monitor {
+		return nil
+	}
+	return options.Handoff
+}
+
+// queueMode returns the effective queue mode for options. Seeded playlists
+// go right after the current track. fellBack reports that QueuePlayNext was
+// wanted but Queue is unavailable, so songs are appended instead.
+func queueMode(options GeneratorOptions) (mode QueueMode, fellBack bool) {
+	mode = options.Mode
+	if mode == "" {
+		mode = QueueReplace
+	}
+	if options.Seed != nil && mode == QueueReplace {
+		mode = QueuePlayNext
+	}
+	if mode == QueuePlayNext && options.Queue == nil && !options.DryRun {
+		return QueueAppend, true
+	}
+	return mode, false
+}
+
+// moveLastQueuedTo moves the song just appended to the end of the queue to
+// position insertAt. Only the HTTP backend needs it; the native backend adds
+// songs at their position directly.
+func moveLastQueuedTo(ctx context.Context, queue QueueEditor, insertAt int) error {
+	page, err := queue.ListQueue(ctx, 0, 1)
+	if err != nil {
+		return err
+	}
+	last := page.TotalMatches
+	if last <= insertAt {
+		return nil
+	}
+	return queue.ReorderTracksInQueue(ctx, last, 1, insertAt)
 }
@@ -0,0 +1,89 @@
+package playlist
+
+import (
+	"context"
+	"testing"
+
+	"sonos-playlist/internal/ai"
+	nativesonos "sonos-playlist/internal/native/sonos"
+)
+
+type fakeQueue struct {
+	total int
+	moves [][3]int
+}
+
+func (q *fakeQueue) ListQueue(ctx context.Context, start, count int) (nativesonos.QueuePage, error) {
+	return nativesonos.QueuePage{TotalMatches: q.total}, nil
+}
+
+func (q *fakeQueue) GetPositionInfo(ctx context.Context) (nativesonos.PositionInfo, error) {
+	return nativesonos.PositionInfo{Track: "3"}, nil
+}
+
+func (q *fakeQueue) RemoveTrackFromQueue(ctx context.Context, oneBasedTrackNumber int) error {
+	q.total--
+	return nil
+}
+
+func (q *fakeQueue) ReorderTracksInQueue(ctx context.Context, start, count, insertBefore int) error {
+	q.moves = append(q.moves, [3]int{start, count, insertBefore})
+	return nil
+}
+
+func TestMoveLastQueuedTo(t *testing.T) {
+	t.Parallel()
+
+	q := &fakeQueue{total: 10}
+	if err := moveLastQueuedTo(context.Background(), q, 4); err != nil {
+		t.Fatalf("moveLastQueuedTo: %v", err)
+	}
+	if len(q.moves) != 1 || q.moves[0] != [3]int{10, 1, 4} {
+		t.Fatalf("moves = %v, want [[10 1 4]]", q.moves)
+	}
+
+	// A song appended to a queue that ends at the insert point stays put.
+	q = &fakeQueue{total: 4}
+	if err := moveLastQueuedTo(context.Background(), q, 4); err != nil {
+		t.Fatalf("moveLastQueuedTo: %v", err)
+	}
+	if len(q.moves) != 0 {
+		t.Fatalf("moves = %v, want none", q.moves)
+	}
+}
+
+func TestQueueModeSeedsPlayNext(t *testing.T) {
+	t.Parallel()
+
+	seed := &ai.Song{Artist: "Miles Davis", Title: "So What"}
+	tests := []struct {
+		name     string
+		options  GeneratorOptions
+		want     QueueMode
+		fellBack bool
+	}{
+		{"default", GeneratorOptions{}, QueueReplace, false},
+		{"seeded", GeneratorOptions{Seed: seed, Queue: &fakeQueue{}}, QueuePlayNext, false},
+		{"seeded without queue", GeneratorOptions{Seed: seed}, QueueAppend, true},
+		{"seeded append", GeneratorOptions{Seed: seed, Mode: QueueAppend}, QueueAppend, false},
+		{"dry run", GeneratorOptions{Seed: seed, DryRun: true}, QueuePlayNext, false},
+	}
+	for _, tt := range tests {
+		mode, fellBack := queueMode(tt.options)
+		if mode != tt.want || fellBack != tt.fellBack {
+			t.Fatalf("%s: got %s/%v, want %s/%v", tt.name, mode, fellBack, tt.want, tt.fellBack)
+		}
+	}
+}
+
+func TestMonitorHandoffRequiresMonitor(t *testing.T) {
+	t.Parallel()
+
+	handoff := func(context.Context, []WatchedSong) error { return nil }
+	if monitorHandoff(GeneratorOptions{Handoff: handoff}) != nil {
+		t.Fatal("handed off without --monitor")
+	}
+	if monitorHandoff(GeneratorOptions{Monitor: true, Handoff: handoff}) == nil {
+		t.Fatal("expected the handoff with --monitor")
+	}
+}
@@ -0,0 +1,155 @@
+package playlist
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	nativesonos "sonos-playlist/internal/native/sonos"
+	"sonos-playlist/internal/sonos"
+)
+
+// eventSubscriptionTimeout is requested for AVTransport subscriptions; they
+// are renewed at half of whatever the speaker grants.
+const eventSubscriptionTimeout = 30 * time.Minute
+
+// initialEventTimeout bounds the wait for the NOTIFY a speaker sends right
+// after SUBSCRIBE. Without it events can't reach this machine (a firewall,
+// usually), so the monitor polls instead.
+const initialEventTimeout = 2 * time.Second
+
+// startSpeakerMonitor watches playback through AVTransport events from
+// speaker, so bad tracks are caught as soon as the speaker gives up on them.
+// It polls source instead when the speaker can't deliver events to this
+// machine, or once the subscription is lost.
+func startSpeakerMonitor(ctx context.Context, speaker *nativesonos.Client, source sonos.TrackSource, options sonos.MonitorOptions) sonos.MonitorHandle {
+	ctx, cancel := context.WithCancel(ctx)
+	events, err := subscribeTrackEvents(ctx, speaker, source)
+	if err != nil {
+		cancel()
+		return sonos.StartTrackMonitor(ctx, source, options)
+	}
+	h := sonos.StartEventMonitor(ctx, events, source, options)
+	go func() {
+		<-h.Done
+		cancel()
+	}()
+	return h
+}
+
+// subscribeTrackEvents subscribes to speaker's AVTransport events and turns
+// them into track events, looking the track up with source when it changes.
+// The channel closes when ctx is done or the subscription can't be renewed.
+// It fails when the speaker's initial event doesn't arrive within
+// initialEventTimeout.
+func subscribeTrackEvents(ctx context.Context, speaker *nativesonos.Client, source sonos.TrackSource) (<-chan sonos.TrackEvent, error) {
+	listener, err := nativesonos.NewEventListener(speaker.IP)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := speaker.SubscribeAVTransport(ctx, listener.CallbackURL(), eventSubscriptionTimeout)
+	if err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	first, err := waitForInitialEvent(ctx, listener.Events(), sub.SID)
+	if err != nil {
+		_ = speaker.Unsubscribe(context.Background(), sub)
+		_ = listener.Close()
+		return nil, err
+	}
+
+	out := make(chan sonos.TrackEvent, 16)
+	go func() {
+		defer close(out)
+		defer func() { _ = listener.Close() }()
+		defer func() { _ = speaker.Unsubscribe(context.Background(), sub) }()
+
+		lastURI := ""
+		forward := func(e nativesonos.Event) bool {
+			ev := trackEventFromVars(e.Vars)
+			if uri, ok := e.Vars["current_track_uri"]; ok && uri != lastURI {
+				lastURI = uri
+				if track, err := source(ctx); err == nil && track.Song != nil {
+					ev.Track = &track
+				}
+			}
+			if ev == (sonos.TrackEvent{}) {
+				return true
+			}
+			select {
+			case out <- ev:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		if !forward(first) {
+			return
+		}
+
+		renew := time.NewTimer(renewAfter(sub.Timeout))
+		defer renew.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-renew.C:
+				renewed, err := speaker.Renew(ctx, sub, eventSubscriptionTimeout)
+				if err != nil {
+					return
+				}
+				sub = renewed
+				renew.Reset(renewAfter(sub.Timeout))
+			case e := <-listener.Events():
+				if e.SID != "" && e.SID != sub.SID {
+					continue
+				}
+				if !forward(e) {
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// waitForInitialEvent returns the first event for subscription sid, or an
+// error when none arrives within initialEventTimeout.
+func waitForInitialEvent(ctx context.Context, events <-chan nativesonos.Event, sid string) (nativesonos.Event, error) {
+	timeout := time.NewTimer(initialEventTimeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nativesonos.Event{}, ctx.Err()
+		case <-timeout.C:
+			return nativesonos.Event{}, errors.New("no event from the speaker; it may not be able to reach this machine")
+		case e := <-events:
+			if e.SID == "" || e.SID == sid {
+				return e, nil
+			}
+		}
+	}
+}
+
+// trackEventFromVars reads the transport state and any playback error from
+// a parsed AVTransport event.
+func trackEventFromVars(vars map[string]string) sonos.TrackEvent {
+	ev := sonos.TrackEvent{
+		State: strings.ToUpper(strings.TrimSpace(vars["transport_state"])),
+		Error: strings.TrimSpace(vars["transport_error_description"]),
+	}
+	if status := strings.TrimSpace(vars["transport_status"]); ev.Error == "" && strings.HasPrefix(strings.ToUpper(status), "ERROR") {
+		ev.Error = status
+	}
+	return ev
+}
+
+func renewAfter(granted time.Duration) time.Duration {
+	if granted <= 0 {
+		return eventSubscriptionTimeout / 2
+	}
+	return granted / 2
+}
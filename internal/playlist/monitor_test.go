@@ -0,0 +1,40 @@
+package playlist
+
+import (
+	"context"
+	"testing"
+
+	nativesonos "sonos-playlist/internal/native/sonos"
+	"sonos-playlist/internal/sonos"
+)
+
+func TestTrackEventFromVars(t *testing.T) {
+	t.Parallel()
+
+	if got := trackEventFromVars(map[string]string{"transport_state": "playing"}); got != (sonos.TrackEvent{State: "PLAYING"}) {
+		t.Fatalf("state event = %+v", got)
+	}
+	got := trackEventFromVars(map[string]string{"transport_state": "STOPPED", "transport_status": "ERROR_PLAYING_TRACK"})
+	if got.State != "STOPPED" || got.Error != "ERROR_PLAYING_TRACK" {
+		t.Fatalf("error event = %+v", got)
+	}
+	if got := trackEventFromVars(map[string]string{"volume_master": "12"}); got != (sonos.TrackEvent{}) {
+		t.Fatalf("unrelated event = %+v", got)
+	}
+}
+
+func TestWaitForInitialEvent(t *testing.T) {
+	t.Parallel()
+
+	events := make(chan nativesonos.Event, 2)
+	events <- nativesonos.Event{SID: "uuid:other"}
+	events <- nativesonos.Event{SID: "uuid:sub", Vars: map[string]string{"transport_state": "PLAYING"}}
+	e, err := waitForInitialEvent(context.Background(), events, "uuid:sub")
+	if err != nil || e.Vars["transport_state"] != "PLAYING" {
+		t.Fatalf("event = %+v, err = %v", e, err)
+	}
+
+	if _, err := waitForInitialEvent(context.Background(), make(chan nativesonos.Event), "uuid:sub"); err == nil {
+		t.Fatalf("expected an error when no event arrives")
+	}
+}
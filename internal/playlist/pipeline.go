@@ -0,0 +1,99 @@
+package playlist
+
+import (
+	"context"
+	"time"
+
+	"sonos-playlist/internal/ai"
+)
+
+// defaultResolveWorkers bounds concurrent song searches when
+// GeneratorOptions.ResolveWorkers is unset.
+const defaultResolveWorkers = 4
+
+// streamHeadSongs is how many songs are queued in provider arrival order so
+// playback starts while the models are still writing; the rest of the
+// initial playlist is queued in ranked order once every provider is done.
+const streamHeadSongs = 3
+
+// resolveInOrder resolves songs from next on up to workers goroutines and
+// passes each result to handle in the order next produced the songs, however
+// the searches finish. It does not rank anything: the queue follows whatever
+// order next yields, arrival order for a streaming feed. Songs for which skip
+// reports true are not searched. handle runs on the caller's goroutine and
+// returns false to stop; searches still in flight are cancelled and waited
+// for before resolveInOrder returns.
+func resolveInOrder(
+	ctx context.Context,
+	workers int,
+	next func(context.Context) (ai.Song, bool),
+	skip func(ai.Song) bool,
+	resolve func(context.Context, ai.Song) Resolved,
+	handle func(Resolved) bool,
+) {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Each job's result is buffered so workers never wait on the consumer;
+	// the jobs channel bounds how far searches run ahead of queueing.
+	jobs := make(chan chan Resolved, workers)
+	sem := make(chan struct{}, workers)
+	go func() {
+		defer close(jobs)
+		for {
+			song, ok := next(ctx)
+			if !ok {
+				return
+			}
+			if skip(song) {
+				continue
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			done := make(chan Resolved, 1)
+			go func() {
+				defer func() { <-sem }()
+				start := time.Now()
+				r := resolve(ctx, song)
+				r.Elapsed = time.Since(start)
+				done <- r
+			}()
+			select {
+			case jobs <- done:
+			case <-ctx.Done():
+				<-done
+				return
+			}
+		}
+	}()
+
+	stopped := false
+	for done := range jobs {
+		r := <-done
+		if stopped {
+			continue
+		}
+		if !handle(r) {
+			stopped = true
+			cancel()
+		}
+	}
+}
+
+// sliceSongs returns a next function for resolveInOrder over songs.
+func sliceSongs(songs []ai.Song) func(context.Context) (ai.Song, bool) {
+	i := 0
+	return func(ctx context.Context) (ai.Song, bool) {
+		if i >= len(songs) || ctx.Err() != nil {
+			return ai.Song{}, false
+		}
+		i++
+		return songs[i-1], true
+	}
+}
@@ -0,0 +1,53 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sonos-playlist/internal/ai"
+)
+
+func TestResolveInOrderKeepsInputOrder(t *testing.T) {
+	songs := make([]ai.Song, 0, 12)
+	for i := 0; i < 12; i++ {
+		songs = append(songs, ai.Song{Artist: "Artist", Title: fmt.Sprintf("Song %d", i)})
+	}
+
+	var running, peak int32
+	resolve := func(ctx context.Context, song ai.Song) Resolved {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		// Later songs finish first.
+		var i int
+		fmt.Sscanf(song.Title, "Song %d", &i)
+		time.Sleep(time.Duration(12-i) * time.Millisecond)
+		return Resolved{Song: song, TrackID: i + 1}
+	}
+	skip := func(song ai.Song) bool { return song.Title == "Song 3" }
+
+	got := []int{}
+	resolveInOrder(context.Background(), 3, sliceSongs(songs), skip, resolve, func(r Resolved) bool {
+		got = append(got, r.TrackID)
+		return len(got) < 8
+	})
+
+	want := []int{1, 2, 3, 5, 6, 7, 8, 9}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("handled %v, want %v", got, want)
+	}
+	if peak > 3 {
+		t.Fatalf("ran %d searches at once, want at most 3", peak)
+	}
+	if atomic.LoadInt32(&running) != 0 {
+		t.Fatalf("searches still running after resolveInOrder returned")
+	}
+}
@@ -0,0 +1,270 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sonos-playlist/internal/ai"
+	"sonos-playlist/internal/output"
+	"sonos-playlist/internal/storage"
+	"sonos-playlist/internal/taste"
+)
+
+type RefineOptions struct {
+	Keys      ai.APIKeys
+	Models    ai.Models
+	Weights   ai.Weights
+	Templates ai.PromptTemplates
+	Taste     taste.Profile
+	Room      string
+	// Instruction describes how the upcoming songs should change.
+	Instruction string
+	// Count is the number of songs requested when nothing is queued after
+	// the current track.
+	Count  int
+	DryRun bool
+	// Backend appends new songs. Queue, when set, is used to read the queue
+	// and remove songs; without it the upcoming songs come from history and
+	// nothing is removed.
+	Backend Backend
+	Queue   QueueEditor
+	Output  *output.Output
+}
+
+type RefineResult struct {
+	Room        string `json:"room"`
+	Instruction string `json:"instruction"`
+	DryRun      bool   `json:"dryRun"`
+	Kept        int    `json:"kept"`
+	Removed     int    `json:"removed"`
+	Added       int    `json:"added"`
+	Failed      int    `json:"failed"`
+	// Songs is the revised upcoming playlist as ranked by the providers.
+	Songs     []ai.RankedSong     `json:"songs,omitempty"`
+	Providers []ai.ProviderReport `json:"providers,omitempty"`
+}
+
+// maxRefineSongs bounds how much of the upcoming queue is sent to the
+// providers; songs further out are left alone.
+const maxRefineSongs = 50
+
+// queuedSong is an upcoming queue entry. Position is 1-based, or 0 when the
+// song came from history and its queue position is unknown.
+type queuedSong struct {
+	Position int
+	Song     ai.Song
+}
+
+// refinePlan lists the queue positions to remove, highest first so earlier
+// positions stay valid, and the songs to append.
+type refinePlan struct {
+	Remove []int
+	Add    []ai.Song
+	Kept   int
+}
+
+// planRefine compares the upcoming songs with the revised list. Songs missing
+// from the revision are removed; revised songs not already queued are added
+// until the upcoming queue is back to target songs.
+func planRefine(upcoming []queuedSong, revised []ai.Song, target int) refinePlan {
+	wanted := map[string]struct{}{}
+	for _, s := range revised {
+		wanted[songKey(s)] = struct{}{}
+	}
+	plan := refinePlan{}
+	queued := map[string]struct{}{}
+	for _, q := range upcoming {
+		key := songKey(q.Song)
+		if _, ok := wanted[key]; ok {
+			queued[key] = struct{}{}
+			plan.Kept++
+			continue
+		}
+		if q.Position > 0 {
+			plan.Remove = append(plan.Remove, q.Position)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(plan.Remove)))
+
+	for _, s := range revised {
+		if plan.Kept+len(plan.Add) >= target {
+			break
+		}
+		key := songKey(s)
+		if _, ok := queued[key]; ok {
+			continue
+		}
+		queued[key] = struct{}{}
+		plan.Add = append(plan.Add, s)
+	}
+	return plan
+}
+
+// Refine revises the songs queued after the current track according to an
+// instruction. The current track keeps playing: songs that no longer fit are
+// removed from the queue and replacements are appended.
+func Refine(ctx context.Context, options RefineOptions) (RefineResult, error) {
+	out := options.Output
+	room := options.Room
+	result := RefineResult{Room: room, Instruction: options.Instruction, DryRun: options.DryRun}
+
+	// The most recent generation for this room supplies the original prompt.
+	prompt := ""
+	if last := storage.SearchHistory(storage.HistoryQuery{Room: room, Limit: 1}); len(last) > 0 {
+		prompt = last[0].Prompt
+	}
+
+	upcoming, err := upcomingSongs(ctx, options.Queue, room, prompt)
+	if err != nil {
+		if options.Queue == nil {
+			return result, err
+		}
+		out.Warn("Could not read the queue (" + err.Error() + "); using the last generated playlist instead")
+		options.Queue = nil
+		if upcoming, err = upcomingSongs(ctx, nil, room, prompt); err != nil {
+			return result, err
+		}
+	}
+	if len(upcoming) > maxRefineSongs {
+		upcoming = upcoming[:maxRefineSongs]
+	}
+	target := len(upcoming)
+	if target == 0 {
+		target = options.Count
+	}
+
+	current := make([]ai.Song, 0, len(upcoming))
+	for _, q := range upcoming {
+		current = append(current, q.Song)
+	}
+	prompts := ai.PromptBuilder{Templates: options.Templates, Taste: options.Taste, Room: room}
+	req, err := prompts.BuildRefine(prompt, options.Instruction, current, target)
+	if err != nil {
+		return result, err
+	}
+
+	out.Info(fmt.Sprintf("Refining %d upcoming songs on %s: %q", len(upcoming), room, options.Instruction))
+	generated, err := ai.GeneratePlaylistAllProviders(ctx, options.Keys, options.Models.WithDefaults(), options.Weights, req)
+	printProviderReports(out, generated.Providers)
+	result.Providers = generated.Providers
+	if err != nil {
+		return result, err
+	}
+	result.Songs = generated.Songs
+
+	revised := make([]ai.Song, 0, len(generated.Songs))
+	for _, s := range generated.Songs {
+		revised = append(revised, s.Song)
+	}
+	plan := planRefine(upcoming, revised, target)
+	result.Kept = plan.Kept
+	if options.Queue == nil && len(upcoming) > plan.Kept {
+		out.Warn("Queue positions are unknown; songs that no longer fit were left in place")
+	}
+
+	if options.DryRun {
+		result.Removed = len(plan.Remove)
+		result.Added = len(plan.Add)
+		for _, q := range upcoming {
+			for _, pos := range plan.Remove {
+				if q.Position == pos {
+					out.Print(out.Red(fmt.Sprintf("  - %s - %s", q.Song.Artist, q.Song.Title)))
+				}
+			}
+		}
+		for _, s := range plan.Add {
+			out.Print(out.Green(fmt.Sprintf("  + %s - %s%s", s.Artist, s.Title, songDetails(s))))
+		}
+		out.Warn("Dry run - not changing the queue")
+		return result, nil
+	}
+
+	for _, pos := range plan.Remove {
+		if err := options.Queue.RemoveTrackFromQueue(ctx, pos); err != nil {
+			return result, fmt.Errorf("remove queue position %d: %w", pos, err)
+		}
+		result.Removed++
+	}
+
+	for _, song := range plan.Add {
+		out.Write(out.Gray(fmt.Sprintf("  Searching: %s - %s... ", song.Artist, song.Title)))
+		added := addSong(ctx, options.Backend, song, false)
+		if !added.Success {
+			out.Print(out.Red("not found"))
+			result.Failed++
+			continue
+		}
+		out.Print(out.Green("found"))
+		result.Added++
+		trackID := ""
+		if added.TrackID != 0 {
+			trackID = strconv.Itoa(added.TrackID)
+		}
+		storage.RecordHistory(storage.HistoryEntry{
+			Artist: song.Artist, Title: song.Title, Album: song.Album, TrackID: trackID,
+			// The original prompt keeps the songs with their playlist, so a
+			// later refine without queue access still finds them.
+			Room: room, Prompt: prompt, Refine: options.Instruction,
+		})
+	}
+
+	out.Success(fmt.Sprintf("Kept %d, removed %d, added %d songs on %s", result.Kept, result.Removed, result.Added, room))
+	return result, nil
+}
+
+// upcomingSongs returns the songs queued after the current track. Without a
+// queue editor it falls back to the unplayed songs of the room's most recent
+// generation.
+func upcomingSongs(ctx context.Context, queue QueueEditor, room, prompt string) ([]queuedSong, error) {
+	if queue == nil {
+		return upcomingFromHistory(room, prompt), nil
+	}
+	pos, err := queue.GetPositionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current, _ := strconv.Atoi(strings.TrimSpace(pos.Track))
+
+	out := []queuedSong{}
+	for start := 0; ; {
+		page, err := queue.ListQueue(ctx, start, 100)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range page.Items {
+			if it.Position <= current {
+				continue
+			}
+			out = append(out, queuedSong{
+				Position: it.Position,
+				Song:     ai.Song{Artist: it.Item.Artist, Title: it.Item.Title, Album: it.Item.Album},
+			})
+		}
+		start += page.NumberReturned
+		if page.NumberReturned == 0 || start >= page.TotalMatches {
+			break
+		}
+	}
+	return out, nil
+}
+
+func upcomingFromHistory(room, prompt string) []queuedSong {
+	out := []queuedSong{}
+	for _, e := range storage.SearchHistory(storage.HistoryQuery{Room: room}) {
+		if e.Prompt != prompt {
+			break
+		}
+		if e.Played || e.Unplayable {
+			continue
+		}
+		out = append(out, queuedSong{Song: ai.Song{Artist: e.Artist, Title: e.Title, Album: e.Album}})
+	}
+	// History is newest first; the queue plays oldest first.
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
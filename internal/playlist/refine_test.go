@@ -0,0 +1,48 @@
+package playlist
+
+import (
+	"reflect"
+	"testing"
+
+	"sonos-playlist/internal/ai"
+)
+
+func TestPlanRefine(t *testing.T) {
+	t.Parallel()
+
+	upcoming := []queuedSong{
+		{Position: 4, Song: ai.Song{Artist: "Miles Davis", Title: "So What"}},
+		{Position: 5, Song: ai.Song{Artist: "Bill Evans", Title: "Peace Piece"}},
+		{Position: 6, Song: ai.Song{Artist: "Chet Baker", Title: "Almost Blue"}},
+	}
+	revised := []ai.Song{
+		{Artist: "Miles Davis", Title: "So What - Remastered"},
+		{Artist: "Art Blakey", Title: "Moanin'"},
+		{Artist: "Lee Morgan", Title: "The Sidewinder"},
+		{Artist: "Horace Silver", Title: "Song for My Father"},
+	}
+
+	plan := planRefine(upcoming, revised, 3)
+	if plan.Kept != 1 {
+		t.Fatalf("Kept = %d, want 1", plan.Kept)
+	}
+	if want := []int{6, 5}; !reflect.DeepEqual(plan.Remove, want) {
+		t.Fatalf("Remove = %v, want %v", plan.Remove, want)
+	}
+	if len(plan.Add) != 2 || plan.Add[0].Artist != "Art Blakey" || plan.Add[1].Artist != "Lee Morgan" {
+		t.Fatalf("Add = %+v", plan.Add)
+	}
+}
+
+func TestPlanRefineWithoutPositions(t *testing.T) {
+	t.Parallel()
+
+	upcoming := []queuedSong{{Song: ai.Song{Artist: "Sade", Title: "Cherish the Day"}}}
+	plan := planRefine(upcoming, []ai.Song{{Artist: "Sade", Title: "Kiss of Life"}}, 1)
+	if len(plan.Remove) != 0 {
+		t.Fatalf("Remove = %v, want none without queue positions", plan.Remove)
+	}
+	if len(plan.Add) != 1 {
+		t.Fatalf("Add = %+v", plan.Add)
+	}
+}
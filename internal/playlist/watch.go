@@ -0,0 +1,158 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"sonos-playlist/internal/ai"
+	"sonos-playlist/internal/output"
+	"sonos-playlist/internal/sonos"
+	"sonos-playlist/internal/storage"
+)
+
+// WatchedSong is a queued song whose playback is monitored.
+type WatchedSong struct {
+	Song ai.Song `json:"song"`
+	// HistoryID is the song's history entry, marked played or unplayable.
+	HistoryID string `json:"historyId,omitempty"`
+	// TrackIDs are the iTunes IDs already queued for the song, so alternates
+	// don't repeat them.
+	TrackIDs []int `json:"trackIds,omitempty"`
+}
+
+// Watcher records what happens to monitored songs and queues an alternate
+// when one turns out to be unplayable. It is safe for concurrent use, so
+// songs can be added while the monitor runs.
+type Watcher struct {
+	backend Backend
+	out     *output.Output
+
+	mu               sync.Mutex
+	historyIDs       map[string]string
+	tried            map[string]map[int]struct{}
+	order            []ai.Song
+	lastAltAttemptAt map[string]time.Time
+}
+
+// NewWatcher returns a watcher that queues alternates through backend.
+func NewWatcher(backend Backend, out *output.Output) *Watcher {
+	return &Watcher{
+		backend:          backend,
+		out:              out,
+		historyIDs:       map[string]string{},
+		tried:            map[string]map[int]struct{}{},
+		lastAltAttemptAt: map[string]time.Time{},
+	}
+}
+
+// Add starts watching songs. A song already watched keeps its history entry
+// unless a new one is given, and collects the new track IDs.
+func (w *Watcher) Add(songs ...WatchedSong) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, s := range songs {
+		key := songKey(s.Song)
+		tried, ok := w.tried[key]
+		if !ok {
+			tried = map[int]struct{}{}
+			w.tried[key] = tried
+			w.order = append(w.order, s.Song)
+		}
+		if s.HistoryID != "" {
+			w.historyIDs[key] = s.HistoryID
+		}
+		for _, id := range s.TrackIDs {
+			if id != 0 {
+				tried[id] = struct{}{}
+			}
+		}
+	}
+}
+
+// Songs returns the watched songs in the order they were added.
+func (w *Watcher) Songs() []WatchedSong {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	out := make([]WatchedSong, 0, len(w.order))
+	for _, song := range w.order {
+		key := songKey(song)
+		ids := make([]int, 0, len(w.tried[key]))
+		for id := range w.tried[key] {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		out = append(out, WatchedSong{Song: song, HistoryID: w.historyIDs[key], TrackIDs: ids})
+	}
+	return out
+}
+
+// Start monitors playback through the backend until ctx is done.
+func (w *Watcher) Start(ctx context.Context) sonos.MonitorHandle {
+	return w.backend.StartMonitor(ctx, sonos.MonitorOptions{
+		OnUnplayable: func(song ai.Song, trackID string) error {
+			w.unplayable(ctx, song, trackID)
+			return nil
+		},
+		OnPlayed: func(song ai.Song, _ string) {
+			storage.MarkHistoryPlayed(w.historyID(song))
+		},
+	})
+}
+
+func (w *Watcher) historyID(song ai.Song) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.historyIDs[songKey(song)]
+}
+
+// unplayable marks song unplayable and queues an alternate, at most once
+// per altRetryCooldown.
+func (w *Watcher) unplayable(ctx context.Context, song ai.Song, trackID string) {
+	out := w.out
+	storage.MarkHistoryUnplayable(w.historyID(song))
+	if trackID != "" {
+		out.Warn(fmt.Sprintf("Detected unplayable track: %s - %s (ID: %s, now blocked)", song.Artist, song.Title, trackID))
+	} else {
+		out.Warn(fmt.Sprintf("Detected unplayable track: %s - %s", song.Artist, song.Title))
+	}
+
+	key := songKey(song)
+	w.mu.Lock()
+	now := time.Now()
+	if last, ok := w.lastAltAttemptAt[key]; ok && now.Sub(last) < altRetryCooldown {
+		w.mu.Unlock()
+		return
+	}
+	w.lastAltAttemptAt[key] = now
+	tried, ok := w.tried[key]
+	if !ok {
+		tried = map[int]struct{}{}
+		w.tried[key] = tried
+		w.order = append(w.order, song)
+	}
+	if id, err := strconv.Atoi(trackID); err == nil {
+		tried[id] = struct{}{}
+	}
+	snapshot := make(map[int]struct{}, len(tried))
+	for id := range tried {
+		snapshot[id] = struct{}{}
+	}
+	w.mu.Unlock()
+
+	alt := w.backend.AddAlternate(ctx, song, snapshot)
+	if alt.Success && alt.TrackID != 0 {
+		w.mu.Lock()
+		tried[alt.TrackID] = struct{}{}
+		w.mu.Unlock()
+		if trackID != "" {
+			storage.SetTrackReplacement(trackID, strconv.Itoa(alt.TrackID))
+		}
+		out.Warn(fmt.Sprintf("Unavailable track replaced with alternate: %s - %s", song.Artist, song.Title))
+	} else {
+		out.Warn(fmt.Sprintf("No alternate found for: %s - %s", song.Artist, song.Title))
+	}
+}
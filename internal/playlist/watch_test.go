@@ -0,0 +1,61 @@
+package playlist
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"sonos-playlist/internal/ai"
+	"sonos-playlist/internal/output"
+	"sonos-playlist/internal/sonos"
+)
+
+// alternateBackend records AddAlternate calls and answers with next.
+type alternateBackend struct {
+	Backend
+	next  int
+	tried []map[int]struct{}
+}
+
+func (b *alternateBackend) AddAlternate(ctx context.Context, song ai.Song, tried map[int]struct{}) sonos.QueueResult {
+	b.tried = append(b.tried, tried)
+	return sonos.QueueResult{Song: song, Success: b.next != 0, TrackID: b.next}
+}
+
+func TestWatcherMergesSongs(t *testing.T) {
+	w := NewWatcher(&alternateBackend{}, output.New(output.Options{Quiet: true}))
+	blue := ai.Song{Artist: "Miles Davis", Title: "Blue in Green"}
+	w.Add(
+		WatchedSong{Song: blue, HistoryID: "h1", TrackIDs: []int{12}},
+		WatchedSong{Song: ai.Song{Artist: "Bill Evans", Title: "Peace Piece"}, HistoryID: "h2"},
+	)
+	w.Add(WatchedSong{Song: ai.Song{Artist: "miles davis", Title: "Blue In Green"}, TrackIDs: []int{7, 0}})
+
+	got := w.Songs()
+	want := []WatchedSong{
+		{Song: blue, HistoryID: "h1", TrackIDs: []int{7, 12}},
+		{Song: ai.Song{Artist: "Bill Evans", Title: "Peace Piece"}, HistoryID: "h2", TrackIDs: []int{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Songs() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWatcherQueuesAlternateOncePerCooldown(t *testing.T) {
+	b := &alternateBackend{next: 99}
+	w := NewWatcher(b, output.New(output.Options{Quiet: true}))
+	song := ai.Song{Artist: "Miles Davis", Title: "So What"}
+	w.Add(WatchedSong{Song: song, TrackIDs: []int{12}})
+
+	w.unplayable(context.Background(), song, "")
+	w.unplayable(context.Background(), song, "")
+	if len(b.tried) != 1 {
+		t.Fatalf("AddAlternate called %d times, want 1", len(b.tried))
+	}
+	if _, ok := b.tried[0][12]; !ok {
+		t.Fatalf("tried = %v, want the queued track excluded", b.tried[0])
+	}
+	if got := w.Songs()[0].TrackIDs; !reflect.DeepEqual(got, []int{12, 99}) {
+		t.Fatalf("TrackIDs = %v, want the alternate recorded", got)
+	}
+}
@@ -0,0 +1,176 @@
+// Package resolve finds playable tracks for AI-suggested songs on the
+// household's music services.
+package resolve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sonos-playlist/internal/ai"
+	nativesonos "sonos-playlist/internal/native/sonos"
+)
+
+// Service names accepted in the preferred-service order. Any other name is
+// looked up among the speaker's SMAPI music services (e.g. "spotify").
+const (
+	AppleMusic = "applemusic"
+	Library    = "library"
+)
+
+// DefaultServices is the preferred-service order when none is configured.
+var DefaultServices = []string{AppleMusic}
+
+// Match is a playable track. URI and Metadata are ready for AddURIToQueue.
+type Match struct {
+	Service  string `json:"service"`
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Artist   string `json:"artist"`
+	Album    string `json:"album,omitempty"`
+	URI      string `json:"uri"`
+	Metadata string `json:"-"`
+}
+
+// Resolver finds matches for a song on one service, best first.
+type Resolver interface {
+	Name() string
+	Resolve(ctx context.Context, song ai.Song) ([]Match, error)
+}
+
+// Chain tries resolvers in preferred order and returns the first non-empty
+// result.
+type Chain []Resolver
+
+func (c Chain) Name() string {
+	names := make([]string, 0, len(c))
+	for _, r := range c {
+		names = append(names, r.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+// Resolve returns the first resolver's matches. Errors are only returned
+// when no resolver found anything.
+func (c Chain) Resolve(ctx context.Context, song ai.Song) ([]Match, error) {
+	var errs []error
+	for _, r := range c {
+		matches, err := r.Resolve(ctx, song)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(matches) > 0 {
+			return matches, nil
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// ParseServices splits a comma-separated preferred-service list, lowercasing
+// names and dropping blanks and duplicates.
+func ParseServices(s string) []string {
+	out := []string{}
+	seen := map[string]struct{}{}
+	for _, name := range strings.Split(s, ",") {
+		name = serviceKey(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		out = append(out, name)
+	}
+	return out
+}
+
+// sameTrack reports whether a service result is the requested song, comparing
+// normalized titles and artists loosely enough to allow "feat." credits and
+// remaster suffixes.
+func sameTrack(song ai.Song, title, artist string) bool {
+	wantTitle, gotTitle := ai.NormalizeTitle(song.Title), ai.NormalizeTitle(title)
+	if wantTitle == "" || gotTitle == "" {
+		return false
+	}
+	if wantTitle != gotTitle && !strings.HasPrefix(gotTitle, wantTitle) {
+		return false
+	}
+	if strings.TrimSpace(artist) == "" {
+		return false
+	}
+	wantArtist, gotArtist := ai.NormalizeArtist(song.Artist), ai.NormalizeArtist(artist)
+	return wantArtist == gotArtist || strings.Contains(gotArtist, wantArtist) || strings.Contains(wantArtist, gotArtist)
+}
+
+// NewChain builds resolvers for services in preferred order, talking to the
+// household through speaker. Services that can't be set up (unknown, not
+// linked, or not signed in) are skipped and reported in the returned errors.
+func NewChain(ctx context.Context, speaker *nativesonos.Client, services []string, tokens nativesonos.SMAPITokenStore) (Chain, []error) {
+	if len(services) == 0 {
+		services = DefaultServices
+	}
+	chain := Chain{}
+	var errs []error
+	var available []nativesonos.MusicServiceDescriptor
+	for _, name := range services {
+		switch name {
+		case AppleMusic:
+			chain = append(chain, &AppleMusicResolver{Speaker: speaker})
+			continue
+		case Library:
+			chain = append(chain, &LibraryResolver{Client: speaker})
+			continue
+		}
+		if available == nil {
+			list, err := speaker.ListAvailableServices(ctx)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: list music services: %w", name, err))
+				continue
+			}
+			available = list
+		}
+		r, err := newSMAPIResolver(ctx, speaker, available, name, tokens)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		chain = append(chain, r)
+	}
+	return chain, errs
+}
+
+func newSMAPIResolver(ctx context.Context, speaker *nativesonos.Client, available []nativesonos.MusicServiceDescriptor, name string, tokens nativesonos.SMAPITokenStore) (*SMAPIResolver, error) {
+	var svc *nativesonos.MusicServiceDescriptor
+	for i := range available {
+		if serviceKey(available[i].Name) == serviceKey(name) {
+			svc = &available[i]
+			break
+		}
+	}
+	if svc == nil {
+		return nil, errors.New("not a music service available on this system")
+	}
+	id, err := strconv.Atoi(svc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service id %q", svc.ID)
+	}
+	serial, err := speaker.ServiceAccountSerial(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	client, err := nativesonos.NewSMAPIClient(ctx, speaker, *svc, tokens)
+	if err != nil {
+		return nil, err
+	}
+	return &SMAPIResolver{Service: name, ServiceID: id, Serial: serial, Client: client}, nil
+}
+
+// serviceKey compares service names ignoring case and spacing, so "apple
+// music" and "AppleMusic" are the same service.
+func serviceKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), ""))
+}
@@ -0,0 +1,169 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sonos-playlist/internal/ai"
+	nativesonos "sonos-playlist/internal/native/sonos"
+)
+
+type stubResolver struct {
+	name    string
+	matches []Match
+	err     error
+	calls   int
+}
+
+func (s *stubResolver) Name() string { return s.name }
+
+func (s *stubResolver) Resolve(context.Context, ai.Song) ([]Match, error) {
+	s.calls++
+	return s.matches, s.err
+}
+
+func TestChainPrefersFirstServiceWithMatches(t *testing.T) {
+	t.Parallel()
+
+	broken := &stubResolver{name: "spotify", err: errors.New("offline")}
+	empty := &stubResolver{name: "library"}
+	apple := &stubResolver{name: AppleMusic, matches: []Match{{Service: AppleMusic, ID: "1"}}}
+	last := &stubResolver{name: "deezer", matches: []Match{{Service: "deezer", ID: "2"}}}
+	chain := Chain{broken, empty, apple, last}
+
+	matches, err := chain.Resolve(context.Background(), ai.Song{Title: "T", Artist: "A"})
+	if err != nil || len(matches) != 1 || matches[0].Service != AppleMusic {
+		t.Fatalf("Resolve = %+v, %v; want the apple music match", matches, err)
+	}
+	if last.calls != 0 {
+		t.Fatalf("resolvers after the first match should not be asked")
+	}
+	if got := chain.Name(); got != "spotify,library,applemusic,deezer" {
+		t.Fatalf("Name = %q", got)
+	}
+
+	_, err = Chain{broken, empty}.Resolve(context.Background(), ai.Song{})
+	if err == nil || !strings.Contains(err.Error(), "offline") {
+		t.Fatalf("expected the service error when nothing matched, got %v", err)
+	}
+}
+
+func TestParseServices(t *testing.T) {
+	t.Parallel()
+
+	got := ParseServices(" Spotify, apple music ,,library,spotify")
+	want := []string{"spotify", AppleMusic, Library}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseServices = %v, want %v", got, want)
+	}
+}
+
+type fakeSearcher struct {
+	term   string
+	result nativesonos.SMAPISearchResult
+}
+
+func (f *fakeSearcher) Search(_ context.Context, category, term string, _, _ int) (nativesonos.SMAPISearchResult, error) {
+	if category != "tracks" {
+		return nativesonos.SMAPISearchResult{}, errors.New("unexpected category " + category)
+	}
+	f.term = term
+	return f.result, nil
+}
+
+func TestSMAPIResolverFiltersToTheSong(t *testing.T) {
+	t.Parallel()
+
+	searcher := &fakeSearcher{result: nativesonos.SMAPISearchResult{MediaMetadata: []nativesonos.SMAPIItem{
+		{ID: "spotify:track:cover", ItemType: "track", Title: "Heroes", Artist: "Tribute Band"},
+		{ID: "spotify:track:orig", ItemType: "track", Title: "\"Heroes\" - 2017 Remaster", Artist: "David Bowie", Album: "Heroes"},
+	}}}
+	r := &SMAPIResolver{Service: "spotify", ServiceID: 12, Serial: 3, Client: searcher}
+
+	matches, err := r.Resolve(context.Background(), ai.Song{Title: "Heroes", Artist: "David Bowie"})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if searcher.term != "David Bowie Heroes" {
+		t.Fatalf("search term = %q", searcher.term)
+	}
+	if len(matches) != 1 || matches[0].ID != "spotify:track:orig" {
+		t.Fatalf("matches = %+v, want only the original recording", matches)
+	}
+	if want := "x-sonos-spotify:spotify%3atrack%3aorig?sid=12&flags=8224&sn=3"; matches[0].URI != want {
+		t.Fatalf("uri = %q, want %q", matches[0].URI, want)
+	}
+	if !strings.Contains(matches[0].Metadata, "SA_RINCON3079_X_#Svc3079-0-Token") {
+		t.Fatalf("metadata: %s", matches[0].Metadata)
+	}
+}
+
+type fakeBrowser struct {
+	objectID string
+	result   string
+}
+
+func (f *fakeBrowser) Browse(_ context.Context, objectID string, _, _ int) (nativesonos.BrowseResponse, error) {
+	f.objectID = objectID
+	return nativesonos.BrowseResponse{Result: f.result}, nil
+}
+
+func TestLibraryResolver(t *testing.T) {
+	t.Parallel()
+
+	browser := &fakeBrowser{result: `<DIDL-Lite xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/" xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/">` +
+		`<item id="S://nas/music/a.flac" parentID="A:TRACKS" restricted="true"><dc:title>Blue in Green</dc:title><dc:creator>Miles Davis</dc:creator><upnp:album>Kind of Blue</upnp:album><upnp:class>object.item.audioItem.musicTrack</upnp:class><res>x-file-cifs://nas/music/a.flac</res></item>` +
+		`<item id="S://nas/music/b.flac" parentID="A:TRACKS" restricted="true"><dc:title>Blue in Green</dc:title><dc:creator>Someone Else</dc:creator><upnp:class>object.item.audioItem.musicTrack</upnp:class><res>x-file-cifs://nas/music/b.flac</res></item>` +
+		`</DIDL-Lite>`}
+	r := &LibraryResolver{Client: browser}
+
+	matches, err := r.Resolve(context.Background(), ai.Song{Title: "Blue in Green", Artist: "Miles Davis"})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if browser.objectID != "A:TRACKS:Blue%20in%20Green" {
+		t.Fatalf("browsed %q", browser.objectID)
+	}
+	if len(matches) != 1 || matches[0].URI != "x-file-cifs://nas/music/a.flac" {
+		t.Fatalf("matches = %+v", matches)
+	}
+	if !strings.Contains(matches[0].Metadata, "<dc:title>Blue in Green</dc:title>") {
+		t.Fatalf("metadata: %s", matches[0].Metadata)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestAppleMusicSerialRetriesAfterAnError(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	speaker := &nativesonos.Client{IP: "192.0.2.1", HTTP: &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("connection reset")
+		}
+		body := `<ZPSupportInfo><Accounts><Account Type="52231" SerialNum="3" Deleted="0"/></Accounts></ZPSupportInfo>`
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}}, nil
+	})}}
+	r := &AppleMusicResolver{Speaker: speaker}
+
+	if _, err := r.appleMusicSerial(context.Background()); err == nil {
+		t.Fatalf("expected the first lookup to fail")
+	}
+	for i := 0; i < 2; i++ {
+		if serial, err := r.appleMusicSerial(context.Background()); err != nil || serial != 3 {
+			t.Fatalf("serial = %d, %v; want 3", serial, err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("%d lookups, want the successful serial cached after 2", calls)
+	}
+}
@@ -0,0 +1,156 @@
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"sonos-playlist/internal/ai"
+	nativesonos "sonos-playlist/internal/native/sonos"
+	"sonos-playlist/internal/sonos"
+)
+
+// AppleMusicResolver matches songs through the iTunes Search API. Speaker,
+// when set, supplies the account serial needed to build queue URIs; without
+// it matches carry only the catalog ID.
+type AppleMusicResolver struct {
+	Speaker *nativesonos.Client
+
+	// serial is cached once found; failed lookups are retried.
+	serialMu    sync.Mutex
+	serial      int
+	serialFound bool
+}
+
+func (r *AppleMusicResolver) Name() string { return AppleMusic }
+
+func (r *AppleMusicResolver) Resolve(ctx context.Context, song ai.Song) ([]Match, error) {
+	tracks := sonos.SearchTracks(ctx, song)
+	if len(tracks) == 0 {
+		return nil, nil
+	}
+	serial := 0
+	if r.Speaker != nil {
+		var err error
+		if serial, err = r.appleMusicSerial(ctx); err != nil {
+			return nil, fmt.Errorf("apple music: %w", err)
+		}
+	}
+	out := make([]Match, 0, len(tracks))
+	for _, t := range tracks {
+		// The DIDL carries iTunes' names for the track, so what the speaker
+		// reports as playing can be checked against the match.
+		m := Match{Service: AppleMusic, ID: strconv.Itoa(t.ID), Title: t.Title, Artist: t.Artist, Album: t.Album}
+		if m.Title == "" || m.Artist == "" {
+			m.Title, m.Artist, m.Album = song.Title, song.Artist, song.Album
+		}
+		if r.Speaker != nil {
+			track := nativesonos.AppleMusicTrack{ID: t.ID, Title: m.Title, Artist: m.Artist, Album: m.Album}
+			m.URI = nativesonos.AppleMusicTrackURI(t.ID, serial)
+			m.Metadata = nativesonos.AppleMusicTrackDIDL(track)
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+func (r *AppleMusicResolver) appleMusicSerial(ctx context.Context) (int, error) {
+	r.serialMu.Lock()
+	defer r.serialMu.Unlock()
+	if r.serialFound {
+		return r.serial, nil
+	}
+	serial, err := r.Speaker.AppleMusicSerial(ctx)
+	if err != nil {
+		return 0, err
+	}
+	r.serial, r.serialFound = serial, true
+	return serial, nil
+}
+
+// SMAPISearcher is the part of nativesonos.SMAPIClient the resolver uses.
+type SMAPISearcher interface {
+	Search(ctx context.Context, category, term string, index, count int) (nativesonos.SMAPISearchResult, error)
+}
+
+// SMAPIResolver matches songs through a linked music service's SMAPI search
+// (Spotify, Deezer, Tidal, ...).
+type SMAPIResolver struct {
+	Service   string
+	ServiceID int
+	// Serial is the household's account serial for the service.
+	Serial int
+	Client SMAPISearcher
+}
+
+func (r *SMAPIResolver) Name() string { return r.Service }
+
+func (r *SMAPIResolver) Resolve(ctx context.Context, song ai.Song) ([]Match, error) {
+	res, err := r.Client.Search(ctx, "tracks", song.Artist+" "+song.Title, 0, 10)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", r.Service, err)
+	}
+	out := []Match{}
+	for _, it := range res.MediaMetadata {
+		if it.ItemType != "" && it.ItemType != "track" {
+			continue
+		}
+		if !sameTrack(song, it.Title, it.Artist) {
+			continue
+		}
+		out = append(out, Match{
+			Service:  r.Service,
+			ID:       it.ID,
+			Title:    it.Title,
+			Artist:   it.Artist,
+			Album:    it.Album,
+			URI:      nativesonos.ServiceTrackURI(r.ServiceID, r.Serial, it.ID, it.MimeType),
+			Metadata: nativesonos.ServiceTrackDIDL(r.ServiceID, it.ID, it.Title, it.Artist, it.Album),
+		})
+	}
+	return out, nil
+}
+
+// LibraryBrowser is the part of nativesonos.Client the library resolver uses.
+type LibraryBrowser interface {
+	Browse(ctx context.Context, objectID string, start, count int) (nativesonos.BrowseResponse, error)
+}
+
+// LibraryResolver matches songs in the household's local music library
+// (shares indexed by the speakers).
+type LibraryResolver struct {
+	Client LibraryBrowser
+}
+
+func (r *LibraryResolver) Name() string { return Library }
+
+func (r *LibraryResolver) Resolve(ctx context.Context, song ai.Song) ([]Match, error) {
+	// A:TRACKS:<term> searches track titles in the music library index.
+	resp, err := r.Client.Browse(ctx, "A:TRACKS:"+url.PathEscape(strings.TrimSpace(song.Title)), 0, 50)
+	if err != nil {
+		return nil, fmt.Errorf("library: %w", err)
+	}
+	items, err := nativesonos.ParseDIDLItems(resp.Result)
+	if err != nil {
+		return nil, fmt.Errorf("library: %w", err)
+	}
+	out := []Match{}
+	for _, it := range items {
+		if it.URI == "" || !sameTrack(song, it.Title, it.Artist) {
+			continue
+		}
+		out = append(out, Match{
+			Service:  Library,
+			ID:       it.ID,
+			Title:    it.Title,
+			Artist:   it.Artist,
+			Album:    it.Album,
+			URI:      it.URI,
+			Metadata: nativesonos.BuildItemDIDL(it),
+		})
+	}
+	return out, nil
+}
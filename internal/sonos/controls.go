@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+
+	"sonos-playlist/internal/ai"
 )
 
 type playerState struct {
@@ -21,6 +23,24 @@ func getState(ctx context.Context, client *Client, room string) (playerState, er
 	return state, err
 }
 
+var maxVolume = func(room string) (int, bool) { return 0, false }
+
+// ConfigureMaxVolume installs the per-room volume caps that every volume
+// change in this package enforces. A nil limit removes them.
+func ConfigureMaxVolume(limit func(room string) (int, bool)) {
+	if limit == nil {
+		limit = func(string) (int, bool) { return 0, false }
+	}
+	maxVolume = limit
+}
+
+func capVolume(room string, level int) int {
+	if limit, ok := maxVolume(room); ok {
+		return min(level, limit)
+	}
+	return level
+}
+
 func VolumeUp(ctx context.Context, client *Client, room string) (int, error) {
 	state, err := getState(ctx, client, room)
 	if err != nil {
@@ -33,6 +53,7 @@ func VolumeUp(ctx context.Context, client *Client, room string) (int, error) {
 	} else {
 		newVolume = min(100, ((current/5)+1)*5)
 	}
+	newVolume = capVolume(room, newVolume)
 	err = client.RequestNoResponse(ctx, fmt.Sprintf("/%s/volume/%d", url.PathEscape(room), newVolume))
 	return newVolume, err
 }
@@ -49,12 +70,13 @@ func VolumeDown(ctx context.Context, client *Client, room string) (int, error) {
 	} else {
 		newVolume = max(0, (current/5)*5)
 	}
+	newVolume = capVolume(room, newVolume)
 	err = client.RequestNoResponse(ctx, fmt.Sprintf("/%s/volume/%d", url.PathEscape(room), newVolume))
 	return newVolume, err
 }
 
 func VolumeSet(ctx context.Context, client *Client, room string, level int) (int, error) {
-	newVolume := max(0, min(100, level))
+	newVolume := capVolume(room, max(0, min(100, level)))
 	err := client.RequestNoResponse(ctx, fmt.Sprintf("/%s/volume/%d", url.PathEscape(room), newVolume))
 	return newVolume, err
 }
@@ -75,6 +97,22 @@ func GetVolume(ctx context.Context, client *Client, room string) (int, error) {
 	return state.Volume, nil
 }
 
+// NowPlaying returns the room's current track. ok is false when nothing with
+// an artist and title is loaded.
+func NowPlaying(ctx context.Context, client *Client, room string) (song ai.Song, ok bool, err error) {
+	var raw map[string]any
+	if err := client.RequestJSON(ctx, "/"+url.PathEscape(room)+"/state", &raw); err != nil {
+		return ai.Song{}, false, err
+	}
+	track := extractTrack(raw)
+	if track.Song == nil {
+		return ai.Song{}, false, nil
+	}
+	song = *track.Song
+	song.Album = track.Album
+	return song, true, nil
+}
+
 func Play(ctx context.Context, client *Client, room string) error {
 	return client.RequestNoResponse(ctx, fmt.Sprintf("/%s/play", url.PathEscape(room)))
 }
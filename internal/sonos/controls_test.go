@@ -0,0 +1,61 @@
+package sonos
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestVolumeCommandsRespectMaxVolume(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.EscapedPath())
+		mu.Unlock()
+		if r.URL.Path == "/Kids Room/state" {
+			_, _ = w.Write([]byte(`{"volume":35}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer srv.Close()
+
+	ConfigureMaxVolume(func(room string) (int, bool) {
+		if room == "Kids Room" {
+			return 38, true
+		}
+		return 0, false
+	})
+	t.Cleanup(func() { ConfigureMaxVolume(nil) })
+
+	client := NewClient(srv.URL)
+	ctx := context.Background()
+
+	if v, err := VolumeSet(ctx, client, "Kids Room", 80); err != nil || v != 38 {
+		t.Fatalf("VolumeSet = %d, %v", v, err)
+	}
+	if v, err := VolumeUp(ctx, client, "Kids Room"); err != nil || v != 38 {
+		t.Fatalf("VolumeUp = %d, %v", v, err)
+	}
+	if v, err := VolumeHigh(ctx, client, "Kids Room"); err != nil || v != 38 {
+		t.Fatalf("VolumeHigh = %d, %v", v, err)
+	}
+	if v, err := VolumeSet(ctx, client, "Office", 80); err != nil || v != 80 {
+		t.Fatalf("VolumeSet without a cap = %d, %v", v, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"/Kids%20Room/volume/38", "/Kids%20Room/state", "/Kids%20Room/volume/38", "/Kids%20Room/volume/38", "/Office/volume/80"}
+	if len(paths) != len(want) {
+		t.Fatalf("requests = %v, want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Fatalf("requests = %v, want %v", paths, want)
+		}
+	}
+}
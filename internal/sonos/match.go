@@ -0,0 +1,254 @@
+package sonos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"sonos-playlist/internal/ai"
+)
+
+// MatchRulesFile is the rules file name inside the config directory.
+const MatchRulesFile = "match-rules.json"
+
+// MatchRules tune how iTunes candidates are filtered and scored.
+type MatchRules struct {
+	// Adjust adds to a candidate's score when its title contains the keyword,
+	// e.g. {"remix": -20}.
+	Adjust map[string]int `json:"adjust,omitempty"`
+	// Exclude drops candidates whose title, artist, or album contains a
+	// keyword.
+	Exclude []string `json:"exclude,omitempty"`
+	// Allow takes keywords back out of Exclude.
+	Allow []string `json:"allow,omitempty"`
+}
+
+// DefaultMatchRules are the built-in rules: penalties for alternate versions
+// and a list of versions that are never wanted.
+func DefaultMatchRules() MatchRules {
+	return MatchRules{
+		Adjust: map[string]int{
+			"remix":    -20,
+			"live":     -10,
+			"remaster": -5,
+			"edit":     -15,
+			"version":  -10,
+		},
+		Exclude: []string{
+			"lullaby", "lullabies", "karaoke", "tribute", "cover", "instrumental",
+			"kids", "baby", "babies", "nursery", "toddler", "children",
+			"made famous", "in the style of", "originally performed",
+			"8-bit", "8 bit", "piano version", "acoustic cover",
+			"ringtone", "workout", "fitness",
+		},
+	}
+}
+
+// Merge layers other on top of r: its adjustments replace r's for the same
+// keyword, and its exclusions and allowances are added.
+func (r MatchRules) Merge(other MatchRules) MatchRules {
+	out := MatchRules{Adjust: map[string]int{}}
+	for k, v := range r.Adjust {
+		out.Adjust[normalizeKeyword(k)] = v
+	}
+	for k, v := range other.Adjust {
+		out.Adjust[normalizeKeyword(k)] = v
+	}
+	out.Allow = appendKeywords(append([]string(nil), r.Allow...), other.Allow...)
+	out.Exclude = appendKeywords(append([]string(nil), r.Exclude...), other.Exclude...)
+	return out
+}
+
+// excluded returns the keywords to reject, after allowances.
+func (r MatchRules) excluded() []string {
+	allowed := map[string]struct{}{}
+	for _, kw := range r.Allow {
+		allowed[normalizeKeyword(kw)] = struct{}{}
+	}
+	out := []string{}
+	for _, kw := range r.Exclude {
+		kw = normalizeKeyword(kw)
+		if _, ok := allowed[kw]; !ok && kw != "" {
+			out = append(out, kw)
+		}
+	}
+	return out
+}
+
+// promptBonus is the score given to a keyword the prompt asks for.
+const promptBonus = 20
+
+// ForPrompt relaxes the rules for versions the prompt asks for: a prompt for
+// "instrumental focus music" allows instrumentals and prefers them, and
+// "live jazz" turns the live penalty into a bonus.
+func (r MatchRules) ForPrompt(prompt string) MatchRules {
+	prompt = strings.ToLower(prompt)
+	if strings.TrimSpace(prompt) == "" {
+		return r
+	}
+	keywords := append([]string(nil), r.Exclude...)
+	for kw := range r.Adjust {
+		keywords = append(keywords, kw)
+	}
+	asked := MatchRules{Adjust: map[string]int{}}
+	for _, kw := range keywords {
+		kw = normalizeKeyword(kw)
+		if kw == "" || !promptMentions(prompt, kw) {
+			continue
+		}
+		asked.Allow = appendKeywords(asked.Allow, kw)
+		if r.Adjust[kw] < promptBonus {
+			asked.Adjust[kw] = promptBonus
+		}
+	}
+	if len(asked.Allow) == 0 {
+		return r
+	}
+	return r.Merge(asked)
+}
+
+func promptMentions(prompt, keyword string) bool {
+	re := regexp.MustCompile(`\b` + regexp.QuoteMeta(keyword) + `(s|es|ed)?\b`)
+	return re.MatchString(prompt)
+}
+
+// fingerprint identifies the effective rules, so cached search results
+// scored under different rules aren't reused.
+func (r MatchRules) fingerprint() string {
+	norm := MatchRules{Adjust: r.Merge(MatchRules{}).Adjust, Exclude: r.excluded()}
+	sort.Strings(norm.Exclude)
+	b, _ := json.Marshal(norm)
+	h := fnv.New64a()
+	_, _ = h.Write(b)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// LoadMatchRules layers the rules file at path over the defaults. A missing
+// file yields the defaults.
+func LoadMatchRules(path string) (MatchRules, error) {
+	rules := DefaultMatchRules()
+	if path == "" {
+		return rules, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return rules, nil
+		}
+		return MatchRules{}, err
+	}
+	var file MatchRules
+	if err := json.Unmarshal(b, &file); err != nil {
+		return MatchRules{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return rules.Merge(file), nil
+}
+
+// ParseMatchAdjust parses a "keyword=score" override such as "remix=0".
+func ParseMatchAdjust(s string) (string, int, error) {
+	kw, val, ok := strings.Cut(s, "=")
+	kw = normalizeKeyword(kw)
+	if !ok || kw == "" {
+		return "", 0, fmt.Errorf("invalid adjustment %q (want keyword=score)", s)
+	}
+	var n int
+	if _, err := fmt.Sscanf(strings.TrimSpace(val), "%d", &n); err != nil {
+		return "", 0, fmt.Errorf("invalid adjustment %q (want keyword=score)", s)
+	}
+	return kw, n, nil
+}
+
+func normalizeKeyword(kw string) string {
+	return strings.ToLower(strings.TrimSpace(kw))
+}
+
+func appendKeywords(dst []string, kws ...string) []string {
+	seen := map[string]struct{}{}
+	for _, kw := range dst {
+		seen[normalizeKeyword(kw)] = struct{}{}
+	}
+	for _, kw := range kws {
+		kw = normalizeKeyword(kw)
+		if _, ok := seen[kw]; ok || kw == "" {
+			continue
+		}
+		seen[kw] = struct{}{}
+		dst = append(dst, kw)
+	}
+	return dst
+}
+
+// CandidateReport explains how one iTunes result was judged.
+type CandidateReport struct {
+	TrackID int      `json:"trackId"`
+	Title   string   `json:"title"`
+	Artist  string   `json:"artist"`
+	Album   string   `json:"album,omitempty"`
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons,omitempty"`
+	// Rejected says why the candidate was dropped before ranking.
+	Rejected string `json:"rejected,omitempty"`
+	Chosen   bool   `json:"chosen,omitempty"`
+}
+
+// MatchReport explains how a song was matched.
+type MatchReport struct {
+	Song       ai.Song           `json:"song"`
+	Candidates []CandidateReport `json:"candidates"`
+}
+
+var (
+	matchMu        sync.RWMutex
+	matchRules     = DefaultMatchRules()
+	matchExplainer func(MatchReport)
+)
+
+// SetMatchRules replaces the rules used for every later search.
+func SetMatchRules(r MatchRules) {
+	matchMu.Lock()
+	defer matchMu.Unlock()
+	matchRules = r
+}
+
+// SetMatchExplainer registers fn to receive a report for every song search;
+// nil turns reporting off. Searches bypass the cache while it is set so the
+// reasons are available.
+func SetMatchExplainer(fn func(MatchReport)) {
+	matchMu.Lock()
+	defer matchMu.Unlock()
+	matchExplainer = fn
+}
+
+type matchRulesKey struct{}
+
+// WithMatchRules returns a context whose searches use r instead of the rules
+// set with SetMatchRules, so one process can match for several rooms, each
+// with the rules of the run that queued its songs.
+func WithMatchRules(ctx context.Context, r MatchRules) context.Context {
+	return context.WithValue(ctx, matchRulesKey{}, r)
+}
+
+func currentMatchRules(ctx context.Context) (MatchRules, func(MatchReport)) {
+	matchMu.RLock()
+	defer matchMu.RUnlock()
+	if r, ok := ctx.Value(matchRulesKey{}).(MatchRules); ok {
+		return r, matchExplainer
+	}
+	return matchRules, matchExplainer
+}
+
+// scoreNotes collects the reasons behind a score when explaining.
+type scoreNotes []string
+
+func (n *scoreNotes) add(delta int, why string) int {
+	if n != nil {
+		*n = append(*n, fmt.Sprintf("%+d %s", delta, why))
+	}
+	return delta
+}
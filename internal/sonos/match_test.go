@@ -0,0 +1,114 @@
+package sonos
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sonos-playlist/internal/ai"
+)
+
+func TestMatchRulesForPromptAllowsAskedForVersions(t *testing.T) {
+	t.Parallel()
+
+	rules := DefaultMatchRules().ForPrompt("Instrumental focus music, some live takes")
+	for _, kw := range rules.excluded() {
+		if kw == "instrumental" {
+			t.Fatalf("instrumental should no longer be excluded")
+		}
+	}
+	if rules.Adjust["live"] != promptBonus || rules.Adjust["instrumental"] != promptBonus {
+		t.Fatalf("asked-for versions should score a bonus: %v", rules.Adjust)
+	}
+	if rules.Adjust["remix"] != -20 {
+		t.Fatalf("other penalties should stay: %v", rules.Adjust)
+	}
+	if got := DefaultMatchRules().ForPrompt("deliver us from evil"); got.fingerprint() != DefaultMatchRules().fingerprint() {
+		t.Fatalf("keywords inside other words should not relax the rules: %+v", got)
+	}
+}
+
+func TestWithMatchRulesOverridesProcessRules(t *testing.T) {
+	t.Parallel()
+
+	room := DefaultMatchRules().ForPrompt("instrumental focus music")
+	got, _ := currentMatchRules(WithMatchRules(context.Background(), room))
+	if got.fingerprint() != room.fingerprint() {
+		t.Fatalf("context rules were not used: %+v", got)
+	}
+	if got, _ := currentMatchRules(context.Background()); got.fingerprint() == room.fingerprint() {
+		t.Fatalf("context rules leaked into the process rules")
+	}
+}
+
+func TestScoreMatchExplainsAdjustments(t *testing.T) {
+	t.Parallel()
+
+	rules := DefaultMatchRules().Merge(MatchRules{Adjust: map[string]int{"remix": 0}})
+	track := itunesTrack{TrackName: "Midnight City (Live Remix)", ArtistName: "M83"}
+	notes := &scoreNotes{}
+	score := scoreMatch(track, ai.Song{Title: "Midnight City", Artist: "M83"}, rules, notes)
+	if score != 140 {
+		t.Fatalf("score = %d (%v), want 140", score, *notes)
+	}
+	want := []string{"+100 artist matches", "+50 title contains Midnight City", `-10 title has "live"`}
+	if len(*notes) != len(want) {
+		t.Fatalf("notes = %v, want %v", *notes, want)
+	}
+	for i := range want {
+		if (*notes)[i] != want[i] {
+			t.Fatalf("notes = %v, want %v", *notes, want)
+		}
+	}
+
+	if got := unwantedReason(itunesTrack{TrackName: "Midnight City", ArtistName: "M83", Collection: "Workout Hits"}, "M83", rules.excluded()); got != `excluded keyword "workout" in album` {
+		t.Fatalf("unwantedReason = %q", got)
+	}
+}
+
+func TestLoadMatchRules(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	rules, err := LoadMatchRules(filepath.Join(dir, MatchRulesFile))
+	if err != nil || rules.fingerprint() != DefaultMatchRules().fingerprint() {
+		t.Fatalf("missing file should give defaults: %+v, %v", rules, err)
+	}
+
+	path := filepath.Join(dir, MatchRulesFile)
+	if err := os.WriteFile(path, []byte(`{"adjust":{"Live":0},"exclude":["sped up"],"allow":["workout"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err = LoadMatchRules(path)
+	if err != nil {
+		t.Fatalf("LoadMatchRules: %v", err)
+	}
+	excluded := map[string]bool{}
+	for _, kw := range rules.excluded() {
+		excluded[kw] = true
+	}
+	if rules.Adjust["live"] != 0 || !excluded["sped up"] || excluded["workout"] || !excluded["karaoke"] {
+		t.Fatalf("rules = %+v", rules)
+	}
+
+	if _, _, err := ParseMatchAdjust("remix"); err == nil {
+		t.Fatalf("expected an error without =score")
+	}
+	if kw, n, err := ParseMatchAdjust(" Remix = -5"); err != nil || kw != "remix" || n != -5 {
+		t.Fatalf("ParseMatchAdjust = %q, %d, %v", kw, n, err)
+	}
+}
+
+func TestDedupeTracksKeepsSearchMetadata(t *testing.T) {
+	t.Parallel()
+
+	got := dedupeTracks([]CatalogTrack{
+		{ID: 7},
+		{ID: 3, Title: "So What", Artist: "Miles Davis"},
+		{ID: 7, Title: "Blue in Green", Artist: "Miles Davis", Album: "Kind of Blue"},
+	})
+	if len(got) != 2 || got[0].ID != 7 || got[0].Title != "Blue in Green" || got[1].ID != 3 {
+		t.Fatalf("dedupeTracks = %+v", got)
+	}
+}
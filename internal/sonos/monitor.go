@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -17,8 +18,10 @@ type MonitorOptions struct {
 	MinPlaySecs  int
 	StallSecs    int
 	OnUnplayable func(song ai.Song, trackID string) error
-	OnStateLog   func(state any)
-	UseTimer     bool
+	// OnPlayed is called once per track when it has played for a few seconds.
+	OnPlayed   func(song ai.Song, trackID string)
+	OnStateLog func(state any)
+	UseTimer   bool
 }
 
 type TrackInfo struct {
@@ -34,7 +37,9 @@ type monitorState struct {
 	Position     any            `json:"position"`
 }
 
-func parseTimeToSeconds(v any) *int {
+// ParseTimeToSeconds converts a position given as seconds or "H:MM:SS" to
+// seconds, returning nil when it can't be parsed.
+func ParseTimeToSeconds(v any) *int {
 	switch t := v.(type) {
 	case float64:
 		i := int(t)
@@ -72,7 +77,7 @@ func extractTrack(raw map[string]any) TrackInfo {
 	if title == "" || artist == "" {
 		return TrackInfo{}
 	}
-	position := parseTimeToSeconds(firstAny(current["position"], current["positionSeconds"], raw["position"]))
+	position := ParseTimeToSeconds(firstAny(current["position"], current["positionSeconds"], raw["position"]))
 	uri := firstString(current, "uri", "trackUri", "albumArtUri")
 	trackID := storage.ExtractTrackIDFromURI(uri)
 	song := ai.Song{Title: title, Artist: artist}
@@ -106,7 +111,34 @@ type MonitorHandle struct {
 	Done <-chan struct{}
 }
 
+// TrackSource reports a room's current track to the playback monitor. A nil
+// TrackInfo.Song means nothing identifiable is playing.
+type TrackSource func(ctx context.Context) (TrackInfo, error)
+
+// StartPlaybackMonitor watches a room through the HTTP API.
 func StartPlaybackMonitor(ctx context.Context, client *Client, room string, options MonitorOptions) MonitorHandle {
+	return StartTrackMonitor(ctx, RoomTrackSource(client, room, options.OnStateLog), options)
+}
+
+// RoomTrackSource reads a room's current track from the HTTP API's /state,
+// passing the raw state to onState when set.
+func RoomTrackSource(client *Client, room string, onState func(state any)) TrackSource {
+	encodedRoom := url.PathEscape(room)
+	return func(ctx context.Context) (TrackInfo, error) {
+		var raw map[string]any
+		if err := client.RequestJSON(ctx, "/"+encodedRoom+"/state", &raw); err != nil {
+			return TrackInfo{}, err
+		}
+		if onState != nil {
+			onState(raw)
+		}
+		return extractTrack(raw), nil
+	}
+}
+
+// StartTrackMonitor polls source, reporting tracks that play and blocking
+// tracks that are skipped before playing or stall.
+func StartTrackMonitor(ctx context.Context, source TrackSource, options MonitorOptions) MonitorHandle {
 	pollMS := options.PollMS
 	if pollMS == 0 {
 		pollMS = 4000
@@ -115,7 +147,6 @@ func StartPlaybackMonitor(ctx context.Context, client *Client, room string, opti
 	if stallMS == 0 {
 		stallMS = 10000
 	}
-	encodedRoom := url.PathEscape(room)
 
 	var stopped atomic.Bool
 	done := make(chan struct{})
@@ -129,23 +160,21 @@ func StartPlaybackMonitor(ctx context.Context, client *Client, room string, opti
 		var lastPosition *int
 		lastPositionAt := time.Now()
 		startedPlaying := false
+		reportedPlayed := false
 		sawStall := false
+		reportPlayed := func() {
+			if startedPlaying && !reportedPlayed && options.OnPlayed != nil {
+				reportedPlayed = true
+				options.OnPlayed(*lastSong, lastTrackID)
+			}
+		}
 
 		for !stopped.Load() {
 			if options.UseTimer && !storage.ShouldMonitorContinue() {
 				break
 			}
-			var raw map[string]any
-			err := client.RequestJSON(ctx, "/"+encodedRoom+"/state", &raw)
-			if err != nil {
-				time.Sleep(time.Duration(pollMS) * time.Millisecond)
-				continue
-			}
-			if options.OnStateLog != nil {
-				options.OnStateLog(raw)
-			}
-			track := extractTrack(raw)
-			if track.Song == nil {
+			track, err := source(ctx)
+			if err != nil || track.Song == nil {
 				time.Sleep(time.Duration(pollMS) * time.Millisecond)
 				continue
 			}
@@ -160,7 +189,9 @@ func StartPlaybackMonitor(ctx context.Context, client *Client, room string, opti
 				lastPosition = track.PositionSeconds
 				lastPositionAt = now
 				startedPlaying = track.PositionSeconds != nil && *track.PositionSeconds >= 3
+				reportedPlayed = false
 				sawStall = false
+				reportPlayed()
 				time.Sleep(time.Duration(pollMS) * time.Millisecond)
 				continue
 			}
@@ -181,7 +212,9 @@ func StartPlaybackMonitor(ctx context.Context, client *Client, room string, opti
 				lastPosition = track.PositionSeconds
 				lastPositionAt = now
 				startedPlaying = track.PositionSeconds != nil && *track.PositionSeconds >= 3
+				reportedPlayed = false
 				sawStall = false
+				reportPlayed()
 				time.Sleep(time.Duration(pollMS) * time.Millisecond)
 				continue
 			}
@@ -194,6 +227,7 @@ func StartPlaybackMonitor(ctx context.Context, client *Client, room string, opti
 				} else {
 					if *track.PositionSeconds >= 3 {
 						startedPlaying = true
+						reportPlayed()
 					}
 					lastPosition = track.PositionSeconds
 					lastPositionAt = now
@@ -209,3 +243,136 @@ func StartPlaybackMonitor(ctx context.Context, client *Client, room string, opti
 		Done: done,
 	}
 }
+
+// TrackEvent is a change in a room's playback pushed by the speaker. Zero
+// fields are unchanged.
+type TrackEvent struct {
+	// State is the transport state: PLAYING, TRANSITIONING, STOPPED, ...
+	State string
+	// Track is the new current track.
+	Track *TrackInfo
+	// Error is the speaker's description of a failure to play the current
+	// track.
+	Error string
+}
+
+// StartEventMonitor is StartTrackMonitor driven by events instead of
+// polling: a track is unplayable when the speaker reports a transport error,
+// when it changes before playing MinPlaySecs, or after it stays
+// TRANSITIONING for StallSecs. When events closes (the subscription was
+// lost), monitoring continues by polling fallback, if set.
+func StartEventMonitor(ctx context.Context, events <-chan TrackEvent, fallback TrackSource, options MonitorOptions) MonitorHandle {
+	minPlay := time.Duration(options.MinPlaySecs) * time.Second
+	if minPlay == 0 {
+		minPlay = 3 * time.Second
+	}
+	stall := time.Duration(options.StallSecs) * time.Second
+	if stall == 0 {
+		stall = 10 * time.Second
+	}
+
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		var current *TrackInfo
+		currentKey := ""
+		state := ""
+		var playingSince, transitioningSince time.Time
+		var playedFor time.Duration
+		played, failed, sawStall := false, false, false
+
+		unplayable := func() {
+			if current == nil || failed {
+				return
+			}
+			failed = true
+			if current.TrackID != "" {
+				storage.BlockTrack(current.TrackID, current.Song.Artist, current.Song.Title, current.Album)
+			}
+			if options.OnUnplayable != nil {
+				_ = options.OnUnplayable(*current.Song, current.TrackID)
+			}
+		}
+		checkPlayed := func(now time.Time) {
+			if current == nil || played || state != "PLAYING" {
+				return
+			}
+			if playedFor+now.Sub(playingSince) >= minPlay {
+				played = true
+				if options.OnPlayed != nil {
+					options.OnPlayed(*current.Song, current.TrackID)
+				}
+			}
+		}
+
+		tick := time.NewTicker(500 * time.Millisecond)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case now := <-tick.C:
+				if options.UseTimer && !storage.ShouldMonitorContinue() {
+					return
+				}
+				checkPlayed(now)
+				if state == "TRANSITIONING" && current != nil && now.Sub(transitioningSince) > stall {
+					sawStall = true
+				}
+			case ev, ok := <-events:
+				if !ok {
+					if fallback != nil {
+						h := StartTrackMonitor(ctx, fallback, options)
+						select {
+						case <-h.Done:
+						case <-stop:
+							h.Stop()
+							<-h.Done
+						}
+					}
+					return
+				}
+				now := time.Now()
+				checkPlayed(now)
+				if ev.Track != nil && ev.Track.Song != nil && songKey(*ev.Track.Song) != currentKey {
+					if !played || sawStall {
+						unplayable()
+					}
+					current = ev.Track
+					currentKey = songKey(*ev.Track.Song)
+					playedFor = 0
+					played, failed, sawStall = false, false, false
+					playingSince, transitioningSince = now, now
+					if current.PositionSeconds != nil && time.Duration(*current.PositionSeconds)*time.Second >= minPlay {
+						played = true
+					}
+				}
+				if ev.State != "" && ev.State != state {
+					if state == "PLAYING" {
+						playedFor += now.Sub(playingSince)
+					}
+					state = ev.State
+					switch state {
+					case "PLAYING":
+						playingSince = now
+					case "TRANSITIONING":
+						transitioningSince = now
+					}
+				}
+				if ev.Error != "" {
+					unplayable()
+				}
+			}
+		}
+	}()
+
+	return MonitorHandle{
+		Stop: func() { stopOnce.Do(func() { close(stop) }) },
+		Done: done,
+	}
+}
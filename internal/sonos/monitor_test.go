@@ -0,0 +1,57 @@
+package sonos
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"sonos-playlist/internal/ai"
+)
+
+func TestEventMonitorReportsErrorsAndPlayedTracks(t *testing.T) {
+	var mu sync.Mutex
+	unplayable, played := []string{}, []string{}
+	options := MonitorOptions{
+		MinPlaySecs: 1,
+		OnUnplayable: func(song ai.Song, _ string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			unplayable = append(unplayable, song.Title)
+			return nil
+		},
+		OnPlayed: func(song ai.Song, _ string) {
+			mu.Lock()
+			defer mu.Unlock()
+			played = append(played, song.Title)
+		},
+	}
+	track := func(title string) *TrackInfo {
+		return &TrackInfo{Song: &ai.Song{Artist: "Artist", Title: title}}
+	}
+
+	events := make(chan TrackEvent)
+	h := StartEventMonitor(context.Background(), events, nil, options)
+
+	// A fails outright; B is skipped before it plays; C plays.
+	events <- TrackEvent{State: "TRANSITIONING", Track: track("A")}
+	events <- TrackEvent{Error: "Unable to play"}
+	events <- TrackEvent{State: "PLAYING", Track: track("B")}
+	events <- TrackEvent{Track: track("C")}
+	time.Sleep(1500 * time.Millisecond)
+	close(events)
+
+	select {
+	case <-h.Done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("monitor did not stop when events closed")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(unplayable) != 2 || unplayable[0] != "A" || unplayable[1] != "B" {
+		t.Fatalf("unplayable = %v, want [A B]", unplayable)
+	}
+	if len(played) != 1 || played[0] != "C" {
+		t.Fatalf("played = %v, want [C]", played)
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"sonos-playlist/internal/ai"
@@ -23,6 +22,8 @@ type QueueResult struct {
 	Success bool
 	Error   string
 	TrackID int
+	// Warning notes a problem that didn't stop the song being queued.
+	Warning string
 }
 
 type itunesSearchResult struct {
@@ -31,11 +32,24 @@ type itunesSearchResult struct {
 }
 
 type itunesTrack struct {
-	TrackID      int    `json:"trackId"`
-	TrackName    string `json:"trackName"`
-	ArtistName   string `json:"artistName"`
-	Collection   string `json:"collectionName"`
-	IsStreamable *bool  `json:"isStreamable"`
+	TrackID         int    `json:"trackId"`
+	TrackName       string `json:"trackName"`
+	ArtistName      string `json:"artistName"`
+	Collection      string `json:"collectionName"`
+	ReleaseDate     string `json:"releaseDate"`
+	TrackTimeMillis int    `json:"trackTimeMillis"`
+	IsStreamable    *bool  `json:"isStreamable"`
+}
+
+func (t itunesTrack) releaseYear() int {
+	if len(t.ReleaseDate) < 4 {
+		return 0
+	}
+	y, err := strconv.Atoi(t.ReleaseDate[:4])
+	if err != nil {
+		return 0
+	}
+	return y
 }
 
 type sonosState struct {
@@ -52,28 +66,9 @@ type trackSummary struct {
 	Creator    string `json:"creator"`
 }
 
-var unwantedKeywords = []string{
-	"lullaby", "lullabies", "karaoke", "tribute", "cover", "instrumental",
-	"kids", "baby", "babies", "nursery", "toddler", "children",
-	"made famous", "in the style of", "originally performed",
-	"8-bit", "8 bit", "piano version", "acoustic cover",
-	"ringtone", "workout", "fitness",
-}
-
 var nonAlphaNum = regexp.MustCompile(`[^a-z0-9]+`)
 
-type searchCacheEntry struct {
-	candidates []int
-	expiresAt  time.Time
-}
-
-var (
-	itunesHTTPClient = &http.Client{Timeout: 8 * time.Second}
-	searchCacheMu    sync.RWMutex
-	searchCache      = map[string]searchCacheEntry{}
-)
-
-const searchCacheTTL = 10 * time.Minute
+var itunesHTTPClient = &http.Client{Timeout: 8 * time.Second}
 
 func debugf(format string, args ...any) {
 	if os.Getenv("DEBUG") == "1" {
@@ -81,63 +76,107 @@ func debugf(format string, args ...any) {
 	}
 }
 
-func isUnwantedVersion(trackName, artistName, collectionName, wantedArtist string) bool {
-	trackLower := strings.ToLower(trackName)
-	artistLower := strings.ToLower(artistName)
-	albumLower := strings.ToLower(collectionName)
-	wantedLower := strings.ToLower(wantedArtist)
-
-	for _, kw := range unwantedKeywords {
-		if strings.Contains(trackLower, kw) || strings.Contains(artistLower, kw) || strings.Contains(albumLower, kw) {
-			return true
+// unwantedReason says why a candidate should be dropped: an excluded keyword
+// (karaoke, covers, ...) or a different artist. It is "" for usable tracks.
+func unwantedReason(track itunesTrack, wantedArtist string, exclude []string) string {
+	fields := []struct{ name, value string }{
+		{"title", strings.ToLower(track.TrackName)},
+		{"artist", strings.ToLower(track.ArtistName)},
+		{"album", strings.ToLower(track.Collection)},
+	}
+	for _, kw := range exclude {
+		for _, f := range fields {
+			if strings.Contains(f.value, kw) {
+				return fmt.Sprintf("excluded keyword %q in %s", kw, f.name)
+			}
 		}
 	}
 
+	artistLower := strings.ToLower(track.ArtistName)
+	wantedLower := strings.ToLower(wantedArtist)
 	if !strings.Contains(artistLower, wantedLower) && !strings.Contains(wantedLower, artistLower) {
 		if !strings.Contains(artistLower, "feat") && !strings.Contains(artistLower, "&") && !strings.Contains(artistLower, "with ") {
-			return true
+			return "different artist"
 		}
 	}
-	return false
+	return ""
 }
 
-func scoreMatch(trackName, artistName string, song ai.Song) int {
+// scoreMatch rates how well track matches song. notes, when non-nil,
+// receives the reason for each score change.
+func scoreMatch(track itunesTrack, song ai.Song, rules MatchRules, notes *scoreNotes) int {
 	score := 0
-	trackTitle := strings.ToLower(trackName)
-	trackArtist := strings.ToLower(artistName)
+	trackTitle := strings.ToLower(track.TrackName)
+	trackArtist := strings.ToLower(track.ArtistName)
 	wantedTitle := strings.ToLower(song.Title)
 	wantedArtist := strings.ToLower(song.Artist)
 
 	if trackArtist == wantedArtist {
-		score += 100
+		score += notes.add(100, "artist matches")
 	} else if strings.Contains(trackArtist, wantedArtist) {
-		score += 50
+		score += notes.add(50, "artist contains "+song.Artist)
 	} else if strings.Contains(wantedArtist, trackArtist) {
-		score += 40
+		score += notes.add(40, "artist is part of "+song.Artist)
 	}
 
 	if trackTitle == wantedTitle {
-		score += 100
+		score += notes.add(100, "title matches")
 	} else if strings.Contains(trackTitle, wantedTitle) {
-		score += 50
+		score += notes.add(50, "title contains "+song.Title)
 	} else if strings.Contains(wantedTitle, trackTitle) {
-		score += 40
+		score += notes.add(40, "title is part of "+song.Title)
 	}
 
-	if strings.Contains(trackTitle, "remix") {
-		score -= 20
+	keywords := make([]string, 0, len(rules.Adjust))
+	for kw := range rules.Adjust {
+		keywords = append(keywords, kw)
 	}
-	if strings.Contains(trackTitle, "live") {
-		score -= 10
+	sort.Strings(keywords)
+	for _, kw := range keywords {
+		if delta := rules.Adjust[kw]; delta != 0 && strings.Contains(trackTitle, kw) {
+			score += notes.add(delta, fmt.Sprintf("title has %q", kw))
+		}
 	}
-	if strings.Contains(trackTitle, "remaster") {
-		score -= 5
+	return score + scoreMetadata(track, song, notes)
+}
+
+// scoreMetadata rewards candidates that agree with the optional album, year,
+// and duration hints returned by structured-output providers. It is zero when
+// the song carries no hints, so plain title/artist matching is unchanged.
+func scoreMetadata(track itunesTrack, song ai.Song, notes *scoreNotes) int {
+	score := 0
+	if song.Album != "" && track.Collection != "" {
+		wantAlbum := normalizeText(song.Album)
+		gotAlbum := normalizeText(track.Collection)
+		switch {
+		case wantAlbum == gotAlbum:
+			score += notes.add(30, "album matches")
+		case strings.Contains(gotAlbum, wantAlbum) || strings.Contains(wantAlbum, gotAlbum):
+			score += notes.add(15, "album is similar")
+		}
 	}
-	if strings.Contains(trackTitle, "edit") {
-		score -= 15
+	if song.Year != 0 {
+		if year := track.releaseYear(); year != 0 {
+			diff := year - song.Year
+			if diff < 0 {
+				diff = -diff
+			}
+			switch {
+			case diff == 0:
+				score += notes.add(15, "release year matches")
+			case diff <= 1:
+				score += notes.add(5, "release year is close")
+			}
+		}
 	}
-	if strings.Contains(trackTitle, "version") {
-		score -= 10
+	if song.DurationSeconds > 0 && track.TrackTimeMillis > 0 {
+		diff := track.TrackTimeMillis/1000 - song.DurationSeconds
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff <= 5 {
+			score += notes.add(10, "duration matches")
+		}
 	}
 	return score
 }
@@ -232,129 +271,200 @@ func getCoordinatorUUID(ctx context.Context, client *Client, room string) string
 	return ""
 }
 
-func searchITunesCandidates(ctx context.Context, song ai.Song) []int {
-	cacheKey := normalizeText(song.Artist) + ":::" + normalizeText(song.Title)
-	now := time.Now()
-
-	searchCacheMu.RLock()
-	entry, ok := searchCache[cacheKey]
-	searchCacheMu.RUnlock()
-
-	var baseCandidates []int
-	if ok && now.Before(entry.expiresAt) {
-		baseCandidates = append([]int(nil), entry.candidates...)
-	} else {
-		query := url.QueryEscape(song.Artist + " " + song.Title)
-		u := "https://itunes.apple.com/search?media=music&limit=25&entity=song&term=" + query
-
-		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
-		resp, err := itunesHTTPClient.Do(req)
-		if err != nil {
-			debugf("    [DEBUG] iTunes search error: %v", err)
-			return []int{}
-		}
-		defer resp.Body.Close()
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			debugf("    [DEBUG] iTunes fetch failed: %d", resp.StatusCode)
-			return []int{}
-		}
-		var data itunesSearchResult
-		if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-			return []int{}
-		}
-		debugf("    [DEBUG] iTunes returned %d results", data.ResultCount)
-		if data.ResultCount == 0 {
-			return []int{}
-		}
+// CatalogTrack is an iTunes catalog match with the metadata iTunes reported
+// for it. Title, Artist, and Album are empty for stored replacements and for
+// results cached before the metadata was kept.
+type CatalogTrack struct {
+	ID     int
+	Title  string
+	Artist string
+	Album  string
+}
 
-		type candidate struct {
-			trackID int
-			score   int
-		}
+// SearchTrackIDs returns iTunes catalog IDs for song, best match first.
+// Blocked tracks and unwanted versions (covers, karaoke, ...) are excluded.
+func SearchTrackIDs(ctx context.Context, song ai.Song) []int {
+	return searchITunesCandidates(ctx, song)
+}
 
-		afterStreamable := make([]itunesTrack, 0, len(data.Results))
-		for _, t := range data.Results {
-			if t.IsStreamable != nil && !*t.IsStreamable {
-				continue
-			}
-			afterStreamable = append(afterStreamable, t)
-		}
-		afterUnwanted := make([]itunesTrack, 0, len(afterStreamable))
-		for _, t := range afterStreamable {
-			if !isUnwantedVersion(t.TrackName, t.ArtistName, t.Collection, song.Artist) {
-				afterUnwanted = append(afterUnwanted, t)
-			}
-		}
-		debugf("    [DEBUG] After streamable filter: %d", len(afterStreamable))
-		debugf("    [DEBUG] After unwanted filter: %d", len(afterUnwanted))
+// SearchTracks is SearchTrackIDs with each match's iTunes metadata.
+func SearchTracks(ctx context.Context, song ai.Song) []CatalogTrack {
+	return searchITunesTracks(ctx, song)
+}
 
-		scored := make([]candidate, 0, len(afterUnwanted))
-		for _, t := range afterUnwanted {
-			sc := scoreMatch(t.TrackName, t.ArtistName, song)
-			scored = append(scored, candidate{trackID: t.TrackID, score: sc})
-		}
-		sort.Slice(scored, func(i, j int) bool { return scored[i].score > scored[j].score })
+func searchITunesCandidates(ctx context.Context, song ai.Song) []int {
+	tracks := searchITunesTracks(ctx, song)
+	ids := make([]int, 0, len(tracks))
+	for _, t := range tracks {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
 
-		positive := make([]int, 0, len(scored))
-		for _, c := range scored {
-			if c.score > 0 {
-				positive = append(positive, c.trackID)
-			}
-		}
-		if len(positive) > 0 {
-			baseCandidates = positive
-			debugf("    [DEBUG] Found %d positive matches, best ID: %d", len(positive), positive[0])
-		} else {
-			for _, t := range afterUnwanted {
-				baseCandidates = append(baseCandidates, t.TrackID)
-			}
-			if len(baseCandidates) > 0 {
-				debugf("    [DEBUG] Using fallback: %d", baseCandidates[0])
-			} else {
-				debugf("    [DEBUG] Using fallback: none")
-			}
-		}
+func searchITunesTracks(ctx context.Context, song ai.Song) []CatalogTrack {
+	cacheKey := normalizeText(song.Artist) + ":::" + normalizeText(song.Title)
+	if song.Album != "" || song.Year != 0 || song.DurationSeconds != 0 {
+		// Metadata hints change candidate ranking, so they are part of the key.
+		cacheKey += ":::" + normalizeText(song.Album) + ":::" + strconv.Itoa(song.Year) + ":::" + strconv.Itoa(song.DurationSeconds)
+	}
+	rules, explain := currentMatchRules(ctx)
+	if fp := rules.fingerprint(); fp != DefaultMatchRules().fingerprint() {
+		cacheKey += ":::rules=" + fp
+	}
 
-		searchCacheMu.Lock()
-		searchCache[cacheKey] = searchCacheEntry{
-			candidates: append([]int(nil), baseCandidates...),
-			expiresAt:  time.Now().Add(searchCacheTTL),
+	var report *MatchReport
+	if explain != nil {
+		report = &MatchReport{Song: song, Candidates: []CandidateReport{}}
+		defer func() { explain(*report) }()
+	}
+	scored, ok := []storage.SearchCandidate(nil), false
+	if report == nil {
+		scored, ok = storage.GetSearchCache(cacheKey)
+	}
+	if ok {
+		debugf("    [DEBUG] Using %d cached candidates", len(scored))
+	} else {
+		scored, ok = searchITunes(ctx, song, rules, report)
+		if !ok {
+			return []CatalogTrack{}
 		}
-		searchCacheMu.Unlock()
+		storage.PutSearchCache(cacheKey, scored)
+	}
+	baseCandidates := rankCandidates(scored)
+	if len(baseCandidates) > 0 {
+		debugf("    [DEBUG] Best ID: %d", baseCandidates[0].TrackID)
 	}
 
 	blocked := storage.GetBlockedTrackIDs()
-	replacements := []int{}
+	replacements := []CatalogTrack{}
 	for _, id := range storage.GetSongReplacementTrackIDs(song.Artist, song.Title) {
 		if _, ok := blocked[id]; !ok {
-			replacements = append(replacements, id)
+			replacements = append(replacements, CatalogTrack{ID: id})
 		}
 	}
-	filtered := make([]int, 0, len(baseCandidates))
-	for _, id := range baseCandidates {
-		if _, ok := blocked[id]; ok {
+	filtered := make([]CatalogTrack, 0, len(baseCandidates))
+	for _, c := range baseCandidates {
+		if _, ok := blocked[c.TrackID]; ok {
 			continue
 		}
-		filtered = append(filtered, id)
+		filtered = append(filtered, CatalogTrack{ID: c.TrackID, Title: c.Title, Artist: c.Artist, Album: c.Album})
 	}
 	debugf("    [DEBUG] After blocklist filter: %d", len(filtered))
 
-	preferred := dedupeInts(append(replacements, filtered...))
+	preferred := dedupeTracks(append(replacements, filtered...))
 	if len(replacements) > 0 {
 		debugf("    [DEBUG] Found %d stored replacements", len(replacements))
 	}
+	if report != nil {
+		for i := range report.Candidates {
+			c := &report.Candidates[i]
+			if _, ok := blocked[c.TrackID]; ok && c.Rejected == "" {
+				c.Rejected = "blocked as unplayable"
+			}
+			c.Chosen = len(preferred) > 0 && c.TrackID == preferred[0].ID && c.Rejected == ""
+		}
+	}
 	return preferred
 }
 
-func dedupeInts(in []int) []int {
-	seen := map[int]struct{}{}
-	out := make([]int, 0, len(in))
-	for _, v := range in {
-		if _, ok := seen[v]; ok {
+// searchITunes queries the iTunes Search API and scores every usable result
+// against song, keeping iTunes' order. ok is false when the search failed or
+// found nothing, so the result isn't worth caching. report, when non-nil,
+// receives every result with the reasons it was scored or rejected.
+func searchITunes(ctx context.Context, song ai.Song, rules MatchRules, report *MatchReport) ([]storage.SearchCandidate, bool) {
+	query := url.QueryEscape(song.Artist + " " + song.Title)
+	u := "https://itunes.apple.com/search?media=music&limit=25&entity=song&term=" + query
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	resp, err := itunesHTTPClient.Do(req)
+	if err != nil {
+		debugf("    [DEBUG] iTunes search error: %v", err)
+		return nil, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		debugf("    [DEBUG] iTunes fetch failed: %d", resp.StatusCode)
+		return nil, false
+	}
+	var data itunesSearchResult
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, false
+	}
+	debugf("    [DEBUG] iTunes returned %d results", data.ResultCount)
+	if data.ResultCount == 0 {
+		return nil, false
+	}
+
+	exclude := rules.excluded()
+	scored := make([]storage.SearchCandidate, 0, len(data.Results))
+	for _, t := range data.Results {
+		rejected := ""
+		if t.IsStreamable != nil && !*t.IsStreamable {
+			rejected = "not streamable"
+		} else {
+			rejected = unwantedReason(t, song.Artist, exclude)
+		}
+		var notes *scoreNotes
+		if report != nil {
+			notes = &scoreNotes{}
+		}
+		score := 0
+		if rejected == "" {
+			score = scoreMatch(t, song, rules, notes)
+			scored = append(scored, storage.SearchCandidate{
+				TrackID: t.TrackID, Score: score,
+				Title: t.TrackName, Artist: t.ArtistName, Album: t.Collection,
+			})
+		}
+		if report != nil {
+			report.Candidates = append(report.Candidates, CandidateReport{
+				TrackID:  t.TrackID,
+				Title:    t.TrackName,
+				Artist:   t.ArtistName,
+				Album:    t.Collection,
+				Score:    score,
+				Reasons:  *notes,
+				Rejected: rejected,
+			})
+		}
+	}
+	debugf("    [DEBUG] After streamable and unwanted filters: %d", len(scored))
+	return scored, true
+}
+
+// rankCandidates orders positively scored candidates best first. When none
+// score above zero, every candidate is kept in iTunes' order as a fallback.
+func rankCandidates(scored []storage.SearchCandidate) []storage.SearchCandidate {
+	positive := make([]storage.SearchCandidate, 0, len(scored))
+	for _, c := range scored {
+		if c.Score > 0 {
+			positive = append(positive, c)
+		}
+	}
+	if len(positive) == 0 {
+		positive = scored
+		debugf("    [DEBUG] Using fallback: %d candidates", len(scored))
+	} else {
+		sort.SliceStable(positive, func(i, j int) bool { return positive[i].Score > positive[j].Score })
+		debugf("    [DEBUG] Found %d positive matches", len(positive))
+	}
+	return positive
+}
+
+// dedupeTracks keeps the first track for each ID, so a stored replacement
+// that iTunes also returned takes the search result's metadata.
+func dedupeTracks(in []CatalogTrack) []CatalogTrack {
+	index := map[int]int{}
+	out := make([]CatalogTrack, 0, len(in))
+	for _, t := range in {
+		if i, ok := index[t.ID]; ok {
+			if out[i].Title == "" {
+				out[i] = t
+			}
 			continue
 		}
-		seen[v] = struct{}{}
-		out = append(out, v)
+		index[t.ID] = len(out)
+		out = append(out, t)
 	}
 	return out
 }
@@ -369,7 +479,12 @@ func AddSongToQueue(ctx context.Context, client *Client, room string, song ai.So
 		debugf("    [DEBUG] No track ID found for %s - %s", song.Artist, song.Title)
 		return QueueResult{Song: song, Success: false, Error: "Not found on iTunes"}
 	}
-	trackID := candidates[0]
+	return QueueTrack(ctx, client, room, song, candidates[0], playNow)
+}
+
+// QueueTrack queues an Apple Music track found for song, starting playback
+// when playNow is set.
+func QueueTrack(ctx context.Context, client *Client, room string, song ai.Song, trackID int, playNow bool) QueueResult {
 	encodedRoom := url.PathEscape(room)
 	endpoint := fmt.Sprintf("/%s/applemusic/queue/song:%d", encodedRoom, trackID)
 	debugf("    [DEBUG] Queueing via: %s", endpoint)
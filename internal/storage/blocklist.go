@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BlockedTrack is a track the speakers failed to play. It is skipped when
+// matching songs until it expires.
+type BlockedTrack struct {
+	TrackID            string `json:"trackId"`
+	Artist             string `json:"artist"`
+	Title              string `json:"title"`
+	Album              string `json:"album,omitempty"`
+	ReplacementTrackID string `json:"replacementTrackId,omitempty"`
+	FailedAt           string `json:"failedAt"`
+}
+
+// DefaultBlocklistTTL is how long a track stays blocked; Apple Music
+// availability changes, so blocks don't last forever.
+const DefaultBlocklistTTL = 30 * 24 * time.Hour
+
+var (
+	// blocklistMu serializes read-modify-write cycles in this process;
+	// lockFile does the same across processes.
+	blocklistMu  sync.Mutex
+	blocklistTTL = DefaultBlocklistTTL
+)
+
+// ConfigureBlocklist sets how long blocks last. A TTL of zero or less keeps
+// them until they are removed.
+func ConfigureBlocklist(ttl time.Duration) {
+	blocklistMu.Lock()
+	defer blocklistMu.Unlock()
+	blocklistTTL = ttl
+}
+
+// ExpiresAt returns when the block lapses under the configured TTL, or the
+// zero time if it doesn't.
+func (b BlockedTrack) ExpiresAt() time.Time {
+	blocklistMu.Lock()
+	ttl := blocklistTTL
+	blocklistMu.Unlock()
+	return b.expiresAt(ttl)
+}
+
+func (b BlockedTrack) expiresAt(ttl time.Duration) time.Time {
+	failed, err := time.Parse(time.RFC3339, b.FailedAt)
+	if ttl <= 0 || err != nil {
+		return time.Time{}
+	}
+	return failed.Add(ttl)
+}
+
+func (b BlockedTrack) expired(ttl time.Duration, now time.Time) bool {
+	exp := b.expiresAt(ttl)
+	return !exp.IsZero() && !now.Before(exp)
+}
+
+func blocklistFile() string { return filepath.Join(storageDir, "blocklist.json") }
+
+// GetBlocklist returns the blocks that haven't expired, keyed by track ID.
+func GetBlocklist() map[string]BlockedTrack {
+	blocklistMu.Lock()
+	ttl := blocklistTTL
+	blocklistMu.Unlock()
+	all := readBlocklist()
+	now := time.Now()
+	for id, b := range all {
+		if b.expired(ttl, now) {
+			delete(all, id)
+		}
+	}
+	return all
+}
+
+// ListBlocklist returns the blocks that haven't expired, most recent first.
+func ListBlocklist() []BlockedTrack {
+	out := []BlockedTrack{}
+	for _, b := range GetBlocklist() {
+		out = append(out, b)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].FailedAt != out[j].FailedAt {
+			return out[i].FailedAt > out[j].FailedAt
+		}
+		return out[i].TrackID < out[j].TrackID
+	})
+	return out
+}
+
+// GetBlockedTrack returns the block for trackID, if it is active.
+func GetBlockedTrack(trackID string) (BlockedTrack, bool) {
+	b, ok := GetBlocklist()[strings.TrimSpace(trackID)]
+	return b, ok
+}
+
+func IsTrackBlocked(trackID string) bool {
+	_, ok := GetBlockedTrack(trackID)
+	return ok
+}
+
+func BlockTrack(trackID, artist, title, album string) {
+	_ = updateBlocklist(func(b map[string]BlockedTrack) bool {
+		b[trackID] = BlockedTrack{
+			TrackID:  trackID,
+			Artist:   artist,
+			Title:    title,
+			Album:    album,
+			FailedAt: time.Now().UTC().Format(time.RFC3339),
+		}
+		return true
+	})
+}
+
+func SetTrackReplacement(trackID, replacementTrackID string) {
+	_ = updateBlocklist(func(b map[string]BlockedTrack) bool {
+		item, ok := b[trackID]
+		if !ok {
+			return false
+		}
+		item.ReplacementTrackID = replacementTrackID
+		b[trackID] = item
+		return true
+	})
+}
+
+// UnblockTrack removes the block for trackID and reports whether there was
+// one.
+func UnblockTrack(trackID string) (bool, error) {
+	trackID = strings.TrimSpace(trackID)
+	found := false
+	err := updateBlocklist(func(b map[string]BlockedTrack) bool {
+		_, found = b[trackID]
+		delete(b, trackID)
+		return found
+	})
+	return found, err
+}
+
+// ClearBlocklist removes every block and returns how many were active.
+func ClearBlocklist() (int, error) {
+	n := 0
+	err := updateBlocklist(func(b map[string]BlockedTrack) bool {
+		n = len(b)
+		for id := range b {
+			delete(b, id)
+		}
+		return true
+	})
+	return n, err
+}
+
+// ImportBlocklist adds entries, keeping the most recent failure when a track
+// is already blocked. With replace, existing blocks are dropped first.
+// Entries without a failure time count as failing now. It returns how many
+// entries were added or updated.
+func ImportBlocklist(entries []BlockedTrack, replace bool) (int, error) {
+	now := time.Now().UTC()
+	n := 0
+	err := updateBlocklist(func(b map[string]BlockedTrack) bool {
+		if replace {
+			for id := range b {
+				delete(b, id)
+			}
+		}
+		for _, e := range entries {
+			e.TrackID = strings.TrimSpace(e.TrackID)
+			if e.TrackID == "" {
+				continue
+			}
+			failed, err := time.Parse(time.RFC3339, e.FailedAt)
+			if err != nil {
+				e.FailedAt, failed = now.Format(time.RFC3339), now
+			}
+			if cur, ok := b[e.TrackID]; ok {
+				if t, err := time.Parse(time.RFC3339, cur.FailedAt); err == nil && !t.Before(failed) {
+					continue
+				}
+			}
+			b[e.TrackID] = e
+			n++
+		}
+		return true
+	})
+	return n, err
+}
+
+// ParseBlocklist reads exported blocks: a JSON array of entries, or the
+// blocklist file itself (an object keyed by track ID).
+func ParseBlocklist(data []byte) ([]BlockedTrack, error) {
+	var list []BlockedTrack
+	if err := json.Unmarshal(data, &list); err == nil {
+		return list, nil
+	}
+	var byID map[string]BlockedTrack
+	if err := json.Unmarshal(data, &byID); err != nil {
+		return nil, fmt.Errorf("not a blocklist: %w", err)
+	}
+	for id, b := range byID {
+		if b.TrackID == "" {
+			b.TrackID = id
+		}
+		list = append(list, b)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].TrackID < list[j].TrackID })
+	return list, nil
+}
+
+// updateBlocklist applies update to the active blocks under both locks and
+// writes the result if update reports a change. Expired blocks are dropped.
+func updateBlocklist(update func(map[string]BlockedTrack) bool) error {
+	blocklistMu.Lock()
+	defer blocklistMu.Unlock()
+	unlock, err := lockFile(blocklistFile())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	b := readBlocklist()
+	now := time.Now()
+	pruned := false
+	for id, item := range b {
+		if item.expired(blocklistTTL, now) {
+			delete(b, id)
+			pruned = true
+		}
+	}
+	if !update(b) && !pruned {
+		return nil
+	}
+	buf, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(blocklistFile(), buf)
+}
+
+func readBlocklist() map[string]BlockedTrack {
+	b, err := os.ReadFile(blocklistFile())
+	if err != nil {
+		return map[string]BlockedTrack{}
+	}
+	var out map[string]BlockedTrack
+	if err := json.Unmarshal(b, &out); err != nil || out == nil {
+		return map[string]BlockedTrack{}
+	}
+	return out
+}
+
+func GetSongReplacementTrackIDs(artist, title string) []int {
+	wantArtist := strings.ToLower(strings.TrimSpace(artist))
+	wantTitle := strings.ToLower(strings.TrimSpace(title))
+	seen := map[int]struct{}{}
+	for _, blocked := range GetBlocklist() {
+		if blocked.ReplacementTrackID == "" {
+			continue
+		}
+		if strings.ToLower(strings.TrimSpace(blocked.Artist)) == wantArtist &&
+			strings.ToLower(strings.TrimSpace(blocked.Title)) == wantTitle {
+			id, err := strconv.Atoi(blocked.ReplacementTrackID)
+			if err == nil {
+				seen[id] = struct{}{}
+			}
+		}
+	}
+	out := make([]int, 0, len(seen))
+	for id := range seen {
+		out = append(out, id)
+	}
+	return out
+}
+
+func GetBlockedTrackIDs() map[int]struct{} {
+	ids := map[int]struct{}{}
+	for key := range GetBlocklist() {
+		id, err := strconv.Atoi(key)
+		if err == nil {
+			ids[id] = struct{}{}
+		}
+	}
+	return ids
+}
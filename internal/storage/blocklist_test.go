@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func useTempBlocklist(t *testing.T, ttl time.Duration) {
+	t.Helper()
+	orig, origTTL := storageDir, blocklistTTL
+	storageDir = t.TempDir()
+	blocklistTTL = ttl
+	t.Cleanup(func() { storageDir, blocklistTTL = orig, origTTL })
+}
+
+func TestBlocklistExpiresAndUnblocks(t *testing.T) {
+	useTempBlocklist(t, 24*time.Hour)
+
+	old := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	if n, err := ImportBlocklist([]BlockedTrack{{TrackID: "1", Artist: "Sade", Title: "Smooth Operator", FailedAt: old}}, false); err != nil || n != 1 {
+		t.Fatalf("import: %d, %v", n, err)
+	}
+	BlockTrack("2", "Miles Davis", "So What", "Kind of Blue")
+	SetTrackReplacement("2", "3")
+
+	if IsTrackBlocked("1") {
+		t.Fatal("expired block still active")
+	}
+	list := ListBlocklist()
+	if len(list) != 1 || list[0].TrackID != "2" || list[0].ReplacementTrackID != "3" {
+		t.Fatalf("list = %+v", list)
+	}
+	if exp := list[0].ExpiresAt(); exp.Before(time.Now().Add(23 * time.Hour)) {
+		t.Fatalf("expires at %v", exp)
+	}
+	if got := GetSongReplacementTrackIDs("miles davis", "so what"); len(got) != 1 || got[0] != 3 {
+		t.Fatalf("replacements = %v", got)
+	}
+	if _, ok := readBlocklist()["1"]; ok {
+		t.Fatal("expired block not pruned on write")
+	}
+
+	if ok, err := UnblockTrack("2"); !ok || err != nil {
+		t.Fatalf("unblock: %v, %v", ok, err)
+	}
+	if ok, _ := UnblockTrack("2"); ok {
+		t.Fatal("unblocked twice")
+	}
+
+	blocklistTTL = 0
+	BlockTrack("4", "Sade", "Cherish the Day", "")
+	if b, ok := GetBlockedTrack("4"); !ok || !b.ExpiresAt().IsZero() {
+		t.Fatalf("block without ttl: %+v, %v", b, ok)
+	}
+	if n, err := ClearBlocklist(); n != 1 || err != nil {
+		t.Fatalf("clear: %d, %v", n, err)
+	}
+}
+
+func TestImportBlocklistKeepsNewestFailure(t *testing.T) {
+	useTempBlocklist(t, 0)
+
+	BlockTrack("1", "Sade", "Smooth Operator", "")
+	older := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	data := []byte(fmt.Sprintf(`{"1": {"artist": "Sade", "title": "Old", "failedAt": %q}, "2": {"artist": "Sade", "title": "Kiss of Life"}}`, older))
+	entries, err := ParseBlocklist(data)
+	if err != nil || len(entries) != 2 || entries[0].TrackID != "1" {
+		t.Fatalf("parse: %+v, %v", entries, err)
+	}
+	n, err := ImportBlocklist(entries, false)
+	if err != nil || n != 1 {
+		t.Fatalf("import: %d, %v", n, err)
+	}
+	if b, _ := GetBlockedTrack("1"); b.Title != "Smooth Operator" {
+		t.Fatalf("older import replaced newer block: %+v", b)
+	}
+	if b, ok := GetBlockedTrack("2"); !ok || b.FailedAt == "" {
+		t.Fatalf("imported block: %+v", b)
+	}
+
+	if n, err := ImportBlocklist(entries[:1], true); err != nil || n != 1 || len(ListBlocklist()) != 1 {
+		t.Fatalf("replace import: %d, %v, %+v", n, err, ListBlocklist())
+	}
+}
+
+func TestBlockTrackConcurrentWritesKeepEveryEntry(t *testing.T) {
+	useTempBlocklist(t, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			BlockTrack(fmt.Sprint(i), "Artist", fmt.Sprint("Song ", i), "")
+		}(i)
+	}
+	wg.Wait()
+	if got := len(GetBlocklist()); got != 20 {
+		t.Fatalf("%d blocks, want 20", got)
+	}
+}
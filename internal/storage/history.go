@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HistoryEntry is one song queued by a generated playlist.
+type HistoryEntry struct {
+	ID      string `json:"id"`
+	Artist  string `json:"artist"`
+	Title   string `json:"title"`
+	Album   string `json:"album,omitempty"`
+	TrackID string `json:"trackId,omitempty"`
+	Room    string `json:"room"`
+	Prompt  string `json:"prompt"`
+	// Refine is the instruction that added the song to a playlist generated
+	// for Prompt.
+	Refine   string `json:"refine,omitempty"`
+	QueuedAt string `json:"queuedAt"`
+	// Played and Unplayable are only known when playback was monitored.
+	Played     bool   `json:"played,omitempty"`
+	PlayedAt   string `json:"playedAt,omitempty"`
+	Unplayable bool   `json:"unplayable,omitempty"`
+}
+
+// QueuedTime parses QueuedAt, returning the zero time if it is malformed.
+func (e HistoryEntry) QueuedTime() time.Time {
+	t, _ := time.Parse(time.RFC3339, e.QueuedAt)
+	return t
+}
+
+// maxHistoryEntries bounds the history file; the oldest entries are dropped.
+const maxHistoryEntries = 5000
+
+// historyMu serializes read-modify-write cycles; the playback monitor records
+// from its own goroutine. The file lock covers other processes, such as the
+// daemon marking songs while a CLI run records new ones.
+var historyMu sync.Mutex
+
+func historyFile() string { return filepath.Join(storageDir, "history.json") }
+
+// GetHistory returns every entry, oldest first.
+func GetHistory() []HistoryEntry {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	return readHistory()
+}
+
+// RecordHistory appends an entry and returns its ID. ID and QueuedAt are
+// filled in when empty.
+func RecordHistory(entry HistoryEntry) string {
+	now := time.Now().UTC()
+	if entry.ID == "" {
+		entry.ID = strconv.FormatInt(now.UnixNano(), 36)
+	}
+	if entry.QueuedAt == "" {
+		entry.QueuedAt = now.Format(time.RFC3339)
+	}
+	_ = modifyHistory(func(entries []HistoryEntry) ([]HistoryEntry, bool) {
+		entries = append(entries, entry)
+		if len(entries) > maxHistoryEntries {
+			entries = entries[len(entries)-maxHistoryEntries:]
+		}
+		return entries, true
+	})
+	return entry.ID
+}
+
+// MarkHistoryPlayed records that the entry started playing.
+func MarkHistoryPlayed(id string) {
+	updateHistory(id, func(e *HistoryEntry) {
+		e.Played = true
+		e.PlayedAt = time.Now().UTC().Format(time.RFC3339)
+	})
+}
+
+// MarkHistoryUnplayable records that the entry failed to play.
+func MarkHistoryUnplayable(id string) {
+	updateHistory(id, func(e *HistoryEntry) { e.Unplayable = true })
+}
+
+func updateHistory(id string, update func(*HistoryEntry)) {
+	if id == "" {
+		return
+	}
+	_ = modifyHistory(func(entries []HistoryEntry) ([]HistoryEntry, bool) {
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].ID == id {
+				update(&entries[i])
+				return entries, true
+			}
+		}
+		return entries, false
+	})
+}
+
+// modifyHistory runs update on the current entries under both locks and
+// writes the result when update reports a change.
+func modifyHistory(update func([]HistoryEntry) ([]HistoryEntry, bool)) error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	unlock, err := lockFile(historyFile())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries, changed := update(readHistory())
+	if !changed {
+		return nil
+	}
+	buf, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(historyFile(), buf)
+}
+
+// HistoryQuery filters SearchHistory results. Zero values match everything.
+type HistoryQuery struct {
+	// Text matches artist, title, album, prompt, or refine instruction
+	// (case-insensitive).
+	Text  string
+	Room  string
+	Since time.Time
+	// Limit keeps only the newest matches.
+	Limit int
+}
+
+// SearchHistory returns matching entries, newest first.
+func SearchHistory(q HistoryQuery) []HistoryEntry {
+	needle := strings.ToLower(strings.TrimSpace(q.Text))
+	all := GetHistory()
+	out := []HistoryEntry{}
+	for i := len(all) - 1; i >= 0; i-- {
+		e := all[i]
+		if q.Room != "" && !strings.EqualFold(e.Room, q.Room) {
+			continue
+		}
+		if !q.Since.IsZero() && e.QueuedTime().Before(q.Since) {
+			continue
+		}
+		if needle != "" && !strings.Contains(strings.ToLower(strings.Join([]string{e.Artist, e.Title, e.Album, e.Prompt, e.Refine}, "\n")), needle) {
+			continue
+		}
+		out = append(out, e)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].QueuedTime().After(out[j].QueuedTime()) })
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out
+}
+
+// RecentHistory returns entries queued since the given time that were not
+// seen failing, newest first. Without monitoring, a queued song counts as
+// played.
+func RecentHistory(since time.Time) []HistoryEntry {
+	out := []HistoryEntry{}
+	for _, e := range SearchHistory(HistoryQuery{Since: since}) {
+		if !e.Unplayable {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func readHistory() []HistoryEntry {
+	b, err := os.ReadFile(historyFile())
+	if err != nil {
+		return []HistoryEntry{}
+	}
+	var out []HistoryEntry
+	if err := json.Unmarshal(b, &out); err != nil {
+		return []HistoryEntry{}
+	}
+	return out
+}
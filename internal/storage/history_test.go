@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryRecordMarkAndSearch(t *testing.T) {
+	orig := storageDir
+	storageDir = t.TempDir()
+	t.Cleanup(func() { storageDir = orig })
+
+	old := time.Now().AddDate(0, 0, -10).UTC().Format(time.RFC3339)
+	RecordHistory(HistoryEntry{Artist: "Miles Davis", Title: "So What", Room: "Kitchen", Prompt: "cool jazz", QueuedAt: old})
+	played := RecordHistory(HistoryEntry{Artist: "Sade", Title: "Cherish the Day", Room: "Kitchen", Prompt: "dinner"})
+	failed := RecordHistory(HistoryEntry{Artist: "Sade", Title: "No Ordinary Love", Room: "Office", Prompt: "dinner"})
+
+	MarkHistoryPlayed(played)
+	MarkHistoryUnplayable(failed)
+
+	all := SearchHistory(HistoryQuery{})
+	if len(all) != 3 || all[len(all)-1].Title != "So What" {
+		t.Fatalf("expected newest first, got %+v", all)
+	}
+	if all[0].ID != failed || !all[0].Unplayable || all[1].ID != played || !all[1].Played {
+		t.Fatalf("marks not persisted: %+v", all)
+	}
+
+	if got := SearchHistory(HistoryQuery{Text: "SADE", Room: "kitchen"}); len(got) != 1 || got[0].ID != played {
+		t.Fatalf("text+room search: %+v", got)
+	}
+	if got := SearchHistory(HistoryQuery{Text: "dinner", Limit: 1}); len(got) != 1 {
+		t.Fatalf("limit: %+v", got)
+	}
+
+	recent := RecentHistory(time.Now().AddDate(0, 0, -7))
+	if len(recent) != 1 || recent[0].ID != played {
+		t.Fatalf("recent should skip old and unplayable entries: %+v", recent)
+	}
+
+	refined := RecordHistory(HistoryEntry{Artist: "Sade", Title: "Paradise", Room: "Kitchen", Prompt: "dinner", Refine: "more upbeat"})
+	if got := SearchHistory(HistoryQuery{Text: "upbeat"}); len(got) != 1 || got[0].ID != refined || got[0].Prompt != "dinner" {
+		t.Fatalf("refine search: %+v", got)
+	}
+}
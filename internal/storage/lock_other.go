@@ -0,0 +1,8 @@
+//go:build !unix
+
+package storage
+
+// lockFile is a no-op where flock isn't available; writes are still atomic.
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
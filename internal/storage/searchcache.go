@@ -0,0 +1,279 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SearchCandidate is one iTunes search result and its match score.
+type SearchCandidate struct {
+	TrackID int `json:"trackId"`
+	Score   int `json:"score"`
+	// Title, Artist, and Album are what iTunes reported for the track.
+	Title  string `json:"title,omitempty"`
+	Artist string `json:"artist,omitempty"`
+	Album  string `json:"album,omitempty"`
+}
+
+// SearchCacheEntry holds the scored candidates for one song, in the order
+// iTunes returned them.
+type SearchCacheEntry struct {
+	Candidates []SearchCandidate `json:"candidates"`
+	CachedAt   string            `json:"cachedAt"`
+	ExpiresAt  string            `json:"expiresAt"`
+}
+
+func (e SearchCacheEntry) expired(now time.Time) bool {
+	t, err := time.Parse(time.RFC3339, e.ExpiresAt)
+	return err != nil || !now.Before(t)
+}
+
+const (
+	// DefaultSearchCacheTTL is how long search results are reused.
+	DefaultSearchCacheTTL = 7 * 24 * time.Hour
+	// DefaultSearchCacheMaxEntries bounds the cache file; the oldest entries
+	// are dropped first.
+	DefaultSearchCacheMaxEntries = 5000
+)
+
+// searchCacheFlushDelay batches the entries stored while a playlist is being
+// searched into one write of the cache file.
+const searchCacheFlushDelay = 2 * time.Second
+
+var (
+	searchCacheMu         sync.Mutex
+	searchCacheTTL        = DefaultSearchCacheTTL
+	searchCacheMaxEntries = DefaultSearchCacheMaxEntries
+	// searchCache is the file's contents, loaded on first use, plus the
+	// entries stored since.
+	searchCache map[string]SearchCacheEntry
+	// searchCachePending holds entries not yet written; searchCacheTimer
+	// writes them after searchCacheFlushDelay.
+	searchCachePending map[string]SearchCacheEntry
+	searchCacheTimer   *time.Timer
+	// searchCacheVersion identifies the file searchCache was read from, so
+	// long-running processes notice when another one rewrites or clears it.
+	searchCacheVersion fileVersion
+)
+
+// fileVersion is what os.Stat reports about a file; it changes whenever the
+// file is replaced or removed.
+type fileVersion struct {
+	modTime int64
+	size    int64
+}
+
+func statFileVersion(path string) fileVersion {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileVersion{}
+	}
+	return fileVersion{modTime: fi.ModTime().UnixNano(), size: fi.Size()}
+}
+
+// loadSearchCache makes searchCache current: it is (re)read from the file on
+// first use and whenever the file changed since, keeping entries not yet
+// written. The caller holds searchCacheMu.
+func loadSearchCache() {
+	version := statFileVersion(searchCacheFile())
+	if searchCache != nil && version == searchCacheVersion {
+		return
+	}
+	if version == (fileVersion{}) && searchCacheVersion != (fileVersion{}) {
+		// Cleared by another process: drop what this one had not written.
+		searchCachePending = nil
+	}
+	searchCache = readSearchCache()
+	searchCacheVersion = version
+	for k, e := range searchCachePending {
+		searchCache[k] = e
+	}
+}
+
+// ConfigureSearchCache sets the TTL and size bound for new entries. A TTL of
+// zero or less disables the cache; maxEntries <= 0 keeps the default.
+func ConfigureSearchCache(ttl time.Duration, maxEntries int) {
+	searchCacheMu.Lock()
+	defer searchCacheMu.Unlock()
+	searchCacheTTL = ttl
+	if maxEntries <= 0 {
+		maxEntries = DefaultSearchCacheMaxEntries
+	}
+	searchCacheMaxEntries = maxEntries
+}
+
+func searchCacheFile() string { return filepath.Join(storageDir, "search-cache.json") }
+
+// GetSearchCache returns the unexpired candidates cached under key.
+func GetSearchCache(key string) ([]SearchCandidate, bool) {
+	searchCacheMu.Lock()
+	defer searchCacheMu.Unlock()
+	if searchCacheTTL <= 0 {
+		return nil, false
+	}
+	loadSearchCache()
+	entry, ok := searchCache[key]
+	if !ok || entry.expired(time.Now()) {
+		return nil, false
+	}
+	return append([]SearchCandidate(nil), entry.Candidates...), true
+}
+
+// PutSearchCache stores candidates under key. The entry is usable at once;
+// it reaches the cache file with the rest of the batch shortly after, or on
+// FlushSearchCache.
+func PutSearchCache(key string, candidates []SearchCandidate) {
+	searchCacheMu.Lock()
+	defer searchCacheMu.Unlock()
+	if searchCacheTTL <= 0 {
+		return
+	}
+	loadSearchCache()
+	if searchCachePending == nil {
+		searchCachePending = map[string]SearchCacheEntry{}
+	}
+	now := time.Now().UTC()
+	entry := SearchCacheEntry{
+		Candidates: append([]SearchCandidate(nil), candidates...),
+		CachedAt:   now.Format(time.RFC3339),
+		ExpiresAt:  now.Add(searchCacheTTL).Format(time.RFC3339),
+	}
+	searchCache[key] = entry
+	searchCachePending[key] = entry
+	if searchCacheTimer == nil {
+		searchCacheTimer = time.AfterFunc(searchCacheFlushDelay, func() { _ = FlushSearchCache() })
+	}
+}
+
+// FlushSearchCache writes the entries stored since the last write, dropping
+// expired entries and the oldest ones beyond the size bound. The file is
+// locked and re-read first so entries written by other processes survive.
+// Call it before exiting.
+func FlushSearchCache() error {
+	searchCacheMu.Lock()
+	defer searchCacheMu.Unlock()
+	if searchCacheTimer != nil {
+		searchCacheTimer.Stop()
+		searchCacheTimer = nil
+	}
+	if len(searchCachePending) == 0 {
+		return nil
+	}
+	unlock, err := lockFile(searchCacheFile())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	loadSearchCache()
+	if len(searchCachePending) == 0 {
+		return nil
+	}
+
+	entries := readSearchCache()
+	for k, e := range searchCachePending {
+		entries[k] = e
+	}
+	searchCachePending = nil
+	pruneSearchCache(entries, time.Now().UTC(), searchCacheMaxEntries)
+	searchCache = entries
+	err = persistSearchCache(entries)
+	searchCacheVersion = statFileVersion(searchCacheFile())
+	return err
+}
+
+func pruneSearchCache(entries map[string]SearchCacheEntry, now time.Time, maxEntries int) {
+	for k, e := range entries {
+		if e.expired(now) {
+			delete(entries, k)
+		}
+	}
+	if len(entries) <= maxEntries {
+		return
+	}
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return entries[keys[i]].CachedAt < entries[keys[j]].CachedAt })
+	for _, k := range keys[:len(keys)-maxEntries] {
+		delete(entries, k)
+	}
+}
+
+// SearchCacheStats describes the on-disk search cache.
+type SearchCacheStats struct {
+	Path    string `json:"path"`
+	Entries int    `json:"entries"`
+	Expired int    `json:"expired"`
+	Bytes   int64  `json:"bytes"`
+	Oldest  string `json:"oldest,omitempty"`
+	Newest  string `json:"newest,omitempty"`
+}
+
+// GetSearchCacheStats reads the cache file and summarizes it.
+func GetSearchCacheStats() SearchCacheStats {
+	searchCacheMu.Lock()
+	defer searchCacheMu.Unlock()
+	stats := SearchCacheStats{Path: searchCacheFile()}
+	if fi, err := os.Stat(stats.Path); err == nil {
+		stats.Bytes = fi.Size()
+	}
+	now := time.Now()
+	for _, e := range readSearchCache() {
+		stats.Entries++
+		if e.expired(now) {
+			stats.Expired++
+		}
+		if stats.Oldest == "" || e.CachedAt < stats.Oldest {
+			stats.Oldest = e.CachedAt
+		}
+		if e.CachedAt > stats.Newest {
+			stats.Newest = e.CachedAt
+		}
+	}
+	return stats
+}
+
+// ClearSearchCache deletes the cache file and returns how many entries it
+// held. It holds the file lock so a concurrent flush cannot write the old
+// entries back; other processes drop their copy once they see the file gone.
+func ClearSearchCache() (int, error) {
+	searchCacheMu.Lock()
+	defer searchCacheMu.Unlock()
+	unlock, err := lockFile(searchCacheFile())
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	n := len(readSearchCache())
+	searchCache = nil
+	searchCachePending = nil
+	if err := os.Remove(searchCacheFile()); err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+	return n, nil
+}
+
+func readSearchCache() map[string]SearchCacheEntry {
+	b, err := os.ReadFile(searchCacheFile())
+	if err != nil {
+		return map[string]SearchCacheEntry{}
+	}
+	var out map[string]SearchCacheEntry
+	if err := json.Unmarshal(b, &out); err != nil || out == nil {
+		return map[string]SearchCacheEntry{}
+	}
+	return out
+}
+
+func persistSearchCache(entries map[string]SearchCacheEntry) error {
+	buf, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(searchCacheFile(), buf)
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSearchCachePersistsAndBoundsEntries(t *testing.T) {
+	orig := storageDir
+	storageDir = t.TempDir()
+	t.Cleanup(func() {
+		storageDir = orig
+		searchCache = nil
+		ConfigureSearchCache(DefaultSearchCacheTTL, DefaultSearchCacheMaxEntries)
+	})
+	ConfigureSearchCache(time.Hour, 2)
+
+	old := time.Now().Add(-time.Minute).UTC()
+	persistSearchCache(map[string]SearchCacheEntry{"a": {
+		Candidates: []SearchCandidate{{TrackID: 1, Score: 90}},
+		CachedAt:   old.Format(time.RFC3339),
+		ExpiresAt:  old.Add(time.Hour).Format(time.RFC3339),
+	}})
+	PutSearchCache("b", []SearchCandidate{{TrackID: 3, Score: 10}})
+	PutSearchCache("c", []SearchCandidate{{TrackID: 4, Score: 10}})
+	if stats := GetSearchCacheStats(); stats.Entries != 1 {
+		t.Fatalf("entries should be written in one batch: %+v", stats)
+	}
+	if err := FlushSearchCache(); err != nil {
+		t.Fatalf("FlushSearchCache: %v", err)
+	}
+
+	// Drop the in-memory copy so lookups come from disk.
+	searchCache = nil
+	if _, ok := GetSearchCache("a"); ok {
+		t.Fatalf("oldest entry should have been evicted")
+	}
+	got, ok := GetSearchCache("c")
+	if !ok || len(got) != 1 || got[0].TrackID != 4 || got[0].Score != 10 {
+		t.Fatalf("GetSearchCache(c) = %+v, %v", got, ok)
+	}
+	if stats := GetSearchCacheStats(); stats.Entries != 2 || stats.Expired != 0 || stats.Bytes == 0 {
+		t.Fatalf("stats = %+v", stats)
+	}
+
+	n, err := ClearSearchCache()
+	if err != nil || n != 2 {
+		t.Fatalf("ClearSearchCache = %d, %v", n, err)
+	}
+	if _, ok := GetSearchCache("b"); ok {
+		t.Fatalf("cache should be empty after clear")
+	}
+}
+
+func TestSearchCacheDisabledWithZeroTTL(t *testing.T) {
+	orig := storageDir
+	storageDir = t.TempDir()
+	t.Cleanup(func() {
+		storageDir = orig
+		searchCache = nil
+		ConfigureSearchCache(DefaultSearchCacheTTL, DefaultSearchCacheMaxEntries)
+	})
+	ConfigureSearchCache(0, 0)
+
+	PutSearchCache("a", []SearchCandidate{{TrackID: 1}})
+	_ = FlushSearchCache()
+	if _, ok := GetSearchCache("a"); ok {
+		t.Fatalf("a zero TTL should disable the cache")
+	}
+	if stats := GetSearchCacheStats(); stats.Entries != 0 {
+		t.Fatalf("nothing should be written: %+v", stats)
+	}
+}
+
+func TestSearchCacheNoticesAClearByAnotherProcess(t *testing.T) {
+	orig := storageDir
+	storageDir = t.TempDir()
+	t.Cleanup(func() {
+		storageDir = orig
+		searchCache = nil
+		searchCachePending = nil
+		searchCacheVersion = fileVersion{}
+	})
+
+	PutSearchCache("a", []SearchCandidate{{TrackID: 1, Score: 90}})
+	if err := FlushSearchCache(); err != nil {
+		t.Fatalf("FlushSearchCache: %v", err)
+	}
+	PutSearchCache("b", []SearchCandidate{{TrackID: 2, Score: 80}})
+
+	// Another process clears the cache while this one has "b" pending.
+	if err := os.Remove(searchCacheFile()); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, ok := GetSearchCache("a"); ok {
+		t.Fatal("entry survived a clear by another process")
+	}
+	if err := FlushSearchCache(); err != nil {
+		t.Fatalf("FlushSearchCache: %v", err)
+	}
+	if _, err := os.Stat(searchCacheFile()); !os.IsNotExist(err) {
+		t.Fatalf("flush wrote the cleared cache back: %v", err)
+	}
+}
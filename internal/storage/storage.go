@@ -1,7 +1,6 @@
 package storage
 
 import (
-	"encoding/json"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -25,100 +24,11 @@ func ensureStorageDir() {
 	_ = os.MkdirAll(storageDir, 0o755)
 }
 
-type BlockedTrack struct {
-	TrackID            string `json:"trackId"`
-	Artist             string `json:"artist"`
-	Title              string `json:"title"`
-	Album              string `json:"album,omitempty"`
-	ReplacementTrackID string `json:"replacementTrackId,omitempty"`
-	FailedAt           string `json:"failedAt"`
-}
-
-func blocklistFile() string { return filepath.Join(storageDir, "blocklist.json") }
-
-func GetBlocklist() map[string]BlockedTrack {
-	file := blocklistFile()
-	b, err := os.ReadFile(file)
-	if err != nil {
-		return map[string]BlockedTrack{}
-	}
-	var out map[string]BlockedTrack
-	if err := json.Unmarshal(b, &out); err != nil {
-		return map[string]BlockedTrack{}
-	}
-	if out == nil {
-		return map[string]BlockedTrack{}
-	}
-	return out
-}
-
-func IsTrackBlocked(trackID string) bool {
-	_, ok := GetBlocklist()[trackID]
-	return ok
-}
-
-func BlockTrack(trackID, artist, title, album string) {
-	ensureStorageDir()
-	b := GetBlocklist()
-	b[trackID] = BlockedTrack{
-		TrackID:  trackID,
-		Artist:   artist,
-		Title:    title,
-		Album:    album,
-		FailedAt: time.Now().UTC().Format(time.RFC3339),
-	}
-	persistBlocklist(b)
-}
-
-func SetTrackReplacement(trackID, replacementTrackID string) {
+// Dir returns the directory holding history, caches, and other state,
+// creating it if needed.
+func Dir() string {
 	ensureStorageDir()
-	b := GetBlocklist()
-	item, ok := b[trackID]
-	if !ok {
-		return
-	}
-	item.ReplacementTrackID = replacementTrackID
-	b[trackID] = item
-	persistBlocklist(b)
-}
-
-func persistBlocklist(data map[string]BlockedTrack) {
-	buf, _ := json.MarshalIndent(data, "", "  ")
-	_ = os.WriteFile(blocklistFile(), buf, 0o644)
-}
-
-func GetSongReplacementTrackIDs(artist, title string) []int {
-	wantArtist := strings.ToLower(strings.TrimSpace(artist))
-	wantTitle := strings.ToLower(strings.TrimSpace(title))
-	seen := map[int]struct{}{}
-	for _, blocked := range GetBlocklist() {
-		if blocked.ReplacementTrackID == "" {
-			continue
-		}
-		if strings.ToLower(strings.TrimSpace(blocked.Artist)) == wantArtist &&
-			strings.ToLower(strings.TrimSpace(blocked.Title)) == wantTitle {
-			id, err := strconv.Atoi(blocked.ReplacementTrackID)
-			if err == nil {
-				seen[id] = struct{}{}
-			}
-		}
-	}
-	out := make([]int, 0, len(seen))
-	for id := range seen {
-		out = append(out, id)
-	}
-	return out
-}
-
-func GetBlockedTrackIDs() map[int]struct{} {
-	ids := map[int]struct{}{}
-	for key := range GetBlocklist() {
-		id, err := strconv.Atoi(key)
-		if err == nil {
-			ids[id] = struct{}{}
-		}
-	}
-	return ids
+	return storageDir
 }
 
 func monitorUntilFile() string { return filepath.Join(storageDir, "monitor-until.txt") }
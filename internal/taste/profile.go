@@ -0,0 +1,163 @@
+package taste
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Kind selects which list a taste entry belongs to.
+type Kind string
+
+const (
+	Artist Kind = "artist"
+	Genre  Kind = "genre"
+)
+
+// Profile is the household's standing musical preferences, added to every
+// playlist request.
+type Profile struct {
+	LikedArtists    []string `json:"likedArtists,omitempty"`
+	DislikedArtists []string `json:"dislikedArtists,omitempty"`
+	LikedGenres     []string `json:"likedGenres,omitempty"`
+	DislikedGenres  []string `json:"dislikedGenres,omitempty"`
+}
+
+// IsEmpty reports whether the profile has no entries.
+func (p Profile) IsEmpty() bool {
+	return len(p.LikedArtists) == 0 && len(p.DislikedArtists) == 0 &&
+		len(p.LikedGenres) == 0 && len(p.DislikedGenres) == 0
+}
+
+// Like adds name to the liked list of kind, removing it from the disliked list.
+func (p Profile) Like(kind Kind, name string) (Profile, error) {
+	return p.set(kind, name, true)
+}
+
+// Dislike adds name to the disliked list of kind, removing it from the liked
+// list.
+func (p Profile) Dislike(kind Kind, name string) (Profile, error) {
+	return p.set(kind, name, false)
+}
+
+// Remove drops name from both lists of kind.
+func (p Profile) Remove(kind Kind, name string) (Profile, error) {
+	liked, disliked, err := p.lists(kind)
+	if err != nil {
+		return Profile{}, err
+	}
+	*liked = without(*liked, name)
+	*disliked = without(*disliked, name)
+	return p, nil
+}
+
+func (p Profile) set(kind Kind, name string, like bool) (Profile, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Profile{}, errors.New("name is required")
+	}
+	liked, disliked, err := p.lists(kind)
+	if err != nil {
+		return Profile{}, err
+	}
+	add, drop := liked, disliked
+	if !like {
+		add, drop = disliked, liked
+	}
+	*drop = without(*drop, name)
+	*add = append(without(*add, name), name)
+	sort.Slice(*add, func(i, j int) bool { return strings.ToLower((*add)[i]) < strings.ToLower((*add)[j]) })
+	return p, nil
+}
+
+// lists copies the liked/disliked slices of kind and returns pointers to them,
+// so edits never alias the caller's profile.
+func (p *Profile) lists(kind Kind) (*[]string, *[]string, error) {
+	switch kind {
+	case Artist:
+		p.LikedArtists = append([]string(nil), p.LikedArtists...)
+		p.DislikedArtists = append([]string(nil), p.DislikedArtists...)
+		return &p.LikedArtists, &p.DislikedArtists, nil
+	case Genre:
+		p.LikedGenres = append([]string(nil), p.LikedGenres...)
+		p.DislikedGenres = append([]string(nil), p.DislikedGenres...)
+		return &p.LikedGenres, &p.DislikedGenres, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown kind %q (expected artist|genre)", kind)
+	}
+}
+
+// without returns list minus any case-insensitive match of name.
+func without(list []string, name string) []string {
+	out := list[:0]
+	for _, v := range list {
+		if !strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(name)) {
+			out = append(out, v)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+type Store interface {
+	Path() string
+	Load() (Profile, error)
+	Save(p Profile) error
+}
+
+type FileStore struct {
+	path string
+}
+
+func NewFileStore(path string) (*FileStore, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, errors.New("path is required")
+	}
+	return &FileStore{path: path}, nil
+}
+
+func NewDefaultStore() (*FileStore, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{path: filepath.Join(dir, "sonos-playlist", "taste.json")}, nil
+}
+
+func (s *FileStore) Path() string { return s.path }
+
+func (s *FileStore) Load() (Profile, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Profile{}, nil
+		}
+		return Profile{}, err
+	}
+	var p Profile
+	if err := json.Unmarshal(b, &p); err != nil {
+		return Profile{}, fmt.Errorf("parse taste profile: %w", err)
+	}
+	return p, nil
+}
+
+func (s *FileStore) Save(p Profile) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
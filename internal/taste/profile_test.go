@@ -0,0 +1,82 @@
+package taste
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProfileLikeDislikeMovesBetweenLists(t *testing.T) {
+	t.Parallel()
+
+	p, err := Profile{}.Like(Artist, "Nickelback")
+	if err != nil {
+		t.Fatalf("Like: %v", err)
+	}
+	p, err = p.Dislike(Artist, " nickelback ")
+	if err != nil {
+		t.Fatalf("Dislike: %v", err)
+	}
+	if len(p.LikedArtists) != 0 || !reflect.DeepEqual(p.DislikedArtists, []string{"nickelback"}) {
+		t.Fatalf("unexpected profile: %+v", p)
+	}
+
+	p, _ = p.Like(Genre, "Jazz")
+	p, _ = p.Like(Genre, "bossa nova")
+	if !reflect.DeepEqual(p.LikedGenres, []string{"bossa nova", "Jazz"}) {
+		t.Fatalf("liked genres: %v", p.LikedGenres)
+	}
+
+	p, _ = p.Remove(Genre, "jazz")
+	if !reflect.DeepEqual(p.LikedGenres, []string{"bossa nova"}) {
+		t.Fatalf("after remove: %v", p.LikedGenres)
+	}
+}
+
+func TestProfileEditsDoNotAliasOriginal(t *testing.T) {
+	t.Parallel()
+
+	orig := Profile{LikedArtists: []string{"A", "B", "C"}}
+	if _, err := orig.Remove(Artist, "A"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if !reflect.DeepEqual(orig.LikedArtists, []string{"A", "B", "C"}) {
+		t.Fatalf("original mutated: %v", orig.LikedArtists)
+	}
+}
+
+func TestProfileRejectsBadInput(t *testing.T) {
+	t.Parallel()
+
+	if _, err := (Profile{}).Like(Artist, "  "); err == nil {
+		t.Fatalf("expected error for empty name")
+	}
+	if _, err := (Profile{}).Like(Kind("mood"), "chill"); err == nil {
+		t.Fatalf("expected error for unknown kind")
+	}
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewFileStore(filepath.Join(t.TempDir(), "nested", "taste.json"))
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	empty, err := s.Load()
+	if err != nil || !empty.IsEmpty() {
+		t.Fatalf("Load missing file: %+v, %v", empty, err)
+	}
+
+	want := Profile{DislikedArtists: []string{"Nickelback"}, LikedGenres: []string{"soul"}}
+	if err := s.Save(want); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}